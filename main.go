@@ -23,21 +23,65 @@ func main() {
 		excludeTags         = flag.String("exclude-tags", "", "Comma-separated list of tags to exclude")
 		includeOnlyPaths    = flag.String("include-only-paths", "", "Comma-separated list of paths to include exclusively")
 		includeOnlyOps      = flag.String("include-only-operations", "", "Comma-separated list of operation IDs to include exclusively")
+		excludeDeprecated   = flag.Bool("exclude-deprecated", false, "Exclude operations marked as deprecated")
 		httpPort            = flag.Int("http-port", 0, "HTTP server port (0 = disabled, use stdio transport)")
 		httpHost            = flag.String("http-host", "localhost", "HTTP server host")
 		httpPath            = flag.String("http-path", "/mcp", "HTTP server path for MCP endpoint")
+		httpWithStdio       = flag.Bool("http-with-stdio", false, "With -http-port, also serve MCP over stdio")
 		skillsDir           = flag.String("skills-dir", "", "Generate Agent Skills to this directory instead of running MCP server")
+		envFile             = flag.String("env-file", "", "Load environment variables from this file, e.g. .env (existing variables are not overridden)")
+		cookieParams        = flag.Bool("cookie-params", false, "Expose cookie parameters as tool arguments and send them as cookies")
+		perCallAuth         = flag.Bool("per-call-auth", false, "Let tool calls supply their own API key in the _apiKey argument (trusted clients only)")
+		authHeader          = flag.String("auth-header", "", "Header(s) the API key is sent in: both (default), x-api-key, bearer or a custom header name")
+		refHosts            = flag.String("allowed-ref-hosts", "", "Comma-separated list of hosts remote $refs may be fetched from")
+		allowedHosts        = flag.String("allowed-hosts", "", "Comma-separated list of hosts API calls may reach (default: any)")
+		allowInsecureHTTP   = flag.Bool("allow-insecure-http", false, "Allow a plaintext http:// API base URL to a non-localhost host")
+		configFile          = flag.String("config", "", "Load server options from a YAML or JSON file (flags override file values)")
+		toolManifest        = flag.String("tool-manifest", "", "Write the generated tool definitions as JSON to this file on startup")
+		richDescriptions    = flag.Bool("rich-descriptions", false, "Add the method, path and required parameters to tool descriptions")
+		responseFormat      = flag.String("response-format", "", "Format of JSON responses: compact (default), pretty or raw")
+		stripPathPrefix     = flag.String("strip-path-prefix", "", "Remove this prefix from operation paths in API calls, e.g. /v2 when -api-base already ends in /v2")
+		addPathPrefix       = flag.String("add-path-prefix", "", "Prepend this prefix to operation paths in API calls, e.g. /api")
 	)
+	cookies := keyValueFlag{}
+	flag.Var(cookies, "cookie", "Cookie sent with every API call as name=value (repeatable)")
+	queryParams := keyValueFlag{}
+	flag.Var(queryParams, "query-param", "Query parameter sent with every API call as name=value (repeatable)")
 
 	flag.Parse()
 
+	// Load the env file before anything reads the environment. Only an
+	// explicitly given file is loaded, never one from the working directory.
+	if *envFile != "" {
+		if err := mcp.LoadEnvFile(*envFile); err != nil {
+			log.Fatalf("Failed to load env file: %v", err)
+		}
+		log.Printf("Loaded environment variables from %s", *envFile)
+	}
+	if err := applyEnvFlags(flag.CommandLine); err != nil {
+		log.Fatalf("Failed to read configuration from the environment: %v", err)
+	}
+
 	// Validate inputs
-	if *swaggerFile == "" && *swaggerURL == "" {
-		fmt.Fprintf(os.Stderr, "Usage: %s -swagger <file> | -swagger-url <url> [-api-base <url>] [-api-key <key>] [transport options] [filtering options] [skills options]\n", os.Args[0])
+	if *swaggerFile == "" && *swaggerURL == "" && *configFile == "" {
+		fmt.Fprintf(os.Stderr, "Usage: %s -swagger <file> | -swagger-url <url> | -config <file> [-api-base <url>] [-api-key <key>] [transport options] [filtering options] [skills options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nConfig file options:\n")
+		fmt.Fprintf(os.Stderr, "  -config: Load options (spec, base URL, filters, transport, headers, timeout...) from a YAML or JSON file; flags and environment variables override it\n")
 		fmt.Fprintf(os.Stderr, "\nTransport options:\n")
 		fmt.Fprintf(os.Stderr, "  -http-port: HTTP server port (default: 0 = use stdio)\n")
 		fmt.Fprintf(os.Stderr, "  -http-host: HTTP server host (default: localhost)\n")
 		fmt.Fprintf(os.Stderr, "  -http-path: HTTP server path (default: /mcp)\n")
+		fmt.Fprintf(os.Stderr, "  -http-with-stdio: Serve MCP over stdio as well as HTTP (e.g. stdio client plus HTTP monitoring)\n")
+		fmt.Fprintf(os.Stderr, "\nSpec options:\n")
+		fmt.Fprintf(os.Stderr, "  -allowed-ref-hosts: Comma-separated hosts remote $refs may be fetched from (the -swagger-url host is always allowed)\n")
+		fmt.Fprintf(os.Stderr, "  -allowed-hosts: Comma-separated hosts API calls may reach; a base URL or redirect to any other host is refused\n")
+		fmt.Fprintf(os.Stderr, "  -allow-insecure-http: Allow an http:// API base URL to a remote host (refused by default; localhost is always allowed)\n")
+		fmt.Fprintf(os.Stderr, "  -tool-manifest: Write the generated tool definitions (names, schemas, method, path) as JSON to this file\n")
+		fmt.Fprintf(os.Stderr, "  -rich-descriptions: Add the HTTP method, path and required parameters to tool descriptions\n")
+		fmt.Fprintf(os.Stderr, "  -response-format: Format of JSON responses: compact (default), pretty or raw (passed through unchanged)\n")
+		fmt.Fprintf(os.Stderr, "  -query-param: Query parameter sent with every API call as name=value, e.g. api-version=2023-01-01 (repeatable; a value the call supplies wins)\n")
+		fmt.Fprintf(os.Stderr, "  -strip-path-prefix: Remove this prefix from operation paths in API calls, e.g. /v2 when the base URL already ends in /v2\n")
+		fmt.Fprintf(os.Stderr, "  -add-path-prefix: Prepend this prefix to operation paths in API calls, for specs whose paths omit it\n")
 		fmt.Fprintf(os.Stderr, "\nFiltering options:\n")
 		fmt.Fprintf(os.Stderr, "  -exclude-paths: Comma-separated paths to exclude (supports wildcards)\n")
 		fmt.Fprintf(os.Stderr, "  -exclude-operations: Comma-separated operation IDs to exclude\n")
@@ -45,16 +89,47 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  -exclude-tags: Comma-separated tags to exclude\n")
 		fmt.Fprintf(os.Stderr, "  -include-only-paths: Include only these paths (exclusive)\n")
 		fmt.Fprintf(os.Stderr, "  -include-only-operations: Include only these operation IDs (exclusive)\n")
+		fmt.Fprintf(os.Stderr, "  -exclude-deprecated: Exclude operations marked as deprecated\n")
+		fmt.Fprintf(os.Stderr, "\nAuthentication options:\n")
+		fmt.Fprintf(os.Stderr, "  -cookie: Cookie sent with every API call as name=value (repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  -cookie-params: Expose the spec's cookie parameters as tool arguments\n")
+		fmt.Fprintf(os.Stderr, "  -per-call-auth: Take the API key for each call from its _apiKey argument; only for trusted clients\n")
+		fmt.Fprintf(os.Stderr, "  -auth-header: Send the API key as both X-API-Key and Bearer (default), x-api-key, bearer, or in a custom header\n")
+		fmt.Fprintf(os.Stderr, "\nEnvironment options:\n")
+		fmt.Fprintf(os.Stderr, "  -env-file: Load variables from this file, e.g. .env (default: none)\n")
+		fmt.Fprintf(os.Stderr, "  Flags take precedence over environment variables, which take precedence over defaults:\n")
+		fmt.Fprintf(os.Stderr, "    MCP_SWAGGER_FILE, MCP_SWAGGER_URL, MCP_API_BASE, MCP_API_KEY (-swagger, -swagger-url, -api-base, -api-key)\n")
+		fmt.Fprintf(os.Stderr, "    MCP_HTTP_PORT, MCP_HTTP_HOST, MCP_HTTP_PATH (-http-port, -http-host, -http-path)\n")
+		fmt.Fprintf(os.Stderr, "    MCP_EXCLUDE_PATHS, MCP_EXCLUDE_OPERATIONS, MCP_EXCLUDE_METHODS, MCP_EXCLUDE_TAGS,\n")
+		fmt.Fprintf(os.Stderr, "    MCP_INCLUDE_ONLY_PATHS, MCP_INCLUDE_ONLY_OPERATIONS, MCP_EXCLUDE_DEPRECATED (filtering flags of the same name)\n")
 		fmt.Fprintf(os.Stderr, "\nSkills options:\n")
 		fmt.Fprintf(os.Stderr, "  -skills-dir: Generate Agent Skills to this directory (SKILL.md files) instead of running MCP server\n")
 		os.Exit(1)
 	}
 
+	// Start from the config file, if any; flags (and the environment
+	// variables standing in for them) override its values
+	config := mcp.DefaultConfig()
+	if *configFile != "" {
+		var err error
+		config, err = mcp.LoadConfigFile(*configFile)
+		if err != nil {
+			log.Fatalf("Failed to load config file: %v", err)
+		}
+	}
+	given := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
 	// Build API filter configuration
-	var filter *mcp.APIFilter
 	if *excludePaths != "" || *excludeOperationIDs != "" || *excludeMethods != "" || *excludeTags != "" || 
-	   *includeOnlyPaths != "" || *includeOnlyOps != "" {
-		filter = &mcp.APIFilter{}
+	   *includeOnlyPaths != "" || *includeOnlyOps != "" || *excludeDeprecated {
+		filter := config.Filter
+		if filter == nil {
+			filter = &mcp.APIFilter{}
+		}
+		if *excludeDeprecated {
+			filter.ExcludeDeprecated = true
+		}
 		
 		if *excludePaths != "" {
 			// Split exclude paths and handle patterns
@@ -63,6 +138,7 @@ func main() {
 				paths[i] = strings.TrimSpace(path)
 			}
 			// Separate exact paths from patterns
+			filter.ExcludePaths, filter.ExcludePathPatterns = nil, nil
 			for _, path := range paths {
 				if strings.Contains(path, "*") {
 					filter.ExcludePathPatterns = append(filter.ExcludePathPatterns, path)
@@ -111,42 +187,92 @@ func main() {
 			}
 			filter.IncludeOnlyOperationIDs = ops
 		}
+		config.WithAPIFilter(filter)
 	}
 
-	// Create MCP server using the new library interface with filtering
-	var server *mcp.Server
+	if *apiBaseURL != "" {
+		config.APIBaseURL = *apiBaseURL
+	}
+	if *apiKey != "" {
+		config.APIKey = *apiKey
+	}
+	config.WithEnv()
+	applyAuthFlags(config, cookies, *cookieParams)
+	if *perCallAuth {
+		config.WithPerCallAuth(true)
+	}
+	if *authHeader != "" {
+		config.WithAuthHeaderMode(mcp.AuthHeaderMode(*authHeader))
+	}
+	if len(queryParams) > 0 {
+		config.WithDefaultQueryParams(queryParams)
+	}
+	if *stripPathPrefix != "" {
+		config.WithStripPathPrefix(*stripPathPrefix)
+	}
+	if *addPathPrefix != "" {
+		config.WithAddPathPrefix(*addPathPrefix)
+	}
+	applyRefHosts(config, *refHosts)
+	applyAllowedHosts(config, *allowedHosts)
+	if *allowInsecureHTTP {
+		config.WithAllowInsecureHTTP(true)
+	}
 
+	// A spec given by flag replaces the config file's
 	if *swaggerFile != "" {
-		// Create with config to support filtering
-		config := mcp.DefaultConfig().
-			WithAPIConfig(*apiBaseURL, *apiKey).
-			WithAPIFilter(filter)
-		
 		data, err := readSwaggerFile(*swaggerFile)
 		if err != nil {
 			log.Fatalf("Failed to read swagger file: %v", err)
 		}
-		config.WithSwaggerData(data)
-		
-		server, err = mcp.New(config)
-		if err != nil {
-			log.Fatalf("Failed to create server from swagger file: %v", err)
-		}
+		config.WithSwaggerData(data).WithSpecLocation(*swaggerFile)
 	} else if *swaggerURL != "" {
-		// Create with config to support filtering
-		config := mcp.DefaultConfig().
-			WithAPIConfig(*apiBaseURL, *apiKey).
-			WithAPIFilter(filter)
-		
 		data, err := mcp.FetchSwaggerFromURL(*swaggerURL)
 		if err != nil {
 			log.Fatalf("Failed to fetch swagger from URL: %v", err)
 		}
-		config.WithSwaggerData(data)
-		
-		server, err = mcp.New(config)
-		if err != nil {
-			log.Fatalf("Failed to create server from swagger URL: %v", err)
+		config.WithSwaggerData(data).WithSpecLocation(*swaggerURL)
+	}
+	if *toolManifest != "" {
+		config.WithToolManifestPath(*toolManifest)
+	}
+	if *richDescriptions {
+		config.WithRichDescriptions(true)
+	}
+	switch format := mcp.ResponseFormat(*responseFormat); format {
+	case "":
+	case mcp.ResponseFormatCompact, mcp.ResponseFormatPretty, mcp.ResponseFormatRaw:
+		config.WithResponseFormatting(format)
+	default:
+		log.Fatalf("Invalid -response-format %q: use compact, pretty or raw", *responseFormat)
+	}
+	if len(config.SwaggerData) == 0 {
+		log.Fatalf("No spec configured: use -swagger, -swagger-url, or swagger/swagger_url in %s", *configFile)
+	}
+
+	// Create MCP server using the library interface with filtering
+	server, err := mcp.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	// HTTP transport settings from the config file apply unless overridden
+	t, _ := config.Transport.(*mcp.HTTPTransport)
+	if both, ok := config.Transport.(*mcp.StdioAndHTTPTransport); ok {
+		t = both.HTTP
+		if !given["http-with-stdio"] {
+			*httpWithStdio = true
+		}
+	}
+	if t != nil {
+		if !given["http-port"] {
+			*httpPort = t.Port
+		}
+		if !given["http-host"] {
+			*httpHost = t.Host
+		}
+		if !given["http-path"] {
+			*httpPath = t.Path
 		}
 	}
 
@@ -172,7 +298,14 @@ func main() {
 	// Run the server with appropriate transport
 	ctx := context.Background()
 	
-	if *httpPort > 0 {
+	if *httpPort > 0 && *httpWithStdio {
+		// Serve stdio and HTTP from the same server
+		server.GetConfig().WithTransportStdioAndHTTP(*httpPort, *httpHost, *httpPath)
+		log.Printf("Starting MCP server with stdio and HTTP transport on %s:%d%s", *httpHost, *httpPort, *httpPath)
+		if err := server.Run(ctx); err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+	} else if *httpPort > 0 {
 		// Use HTTP transport
 		server.GetConfig().WithHTTPTransport(*httpPort, *httpHost, *httpPath)
 		log.Printf("Starting MCP server with HTTP transport on %s:%d%s", *httpHost, *httpPort, *httpPath)
@@ -191,4 +324,101 @@ func main() {
 // readSwaggerFile reads a swagger file from disk
 func readSwaggerFile(filePath string) ([]byte, error) {
 	return os.ReadFile(filePath)
-}
\ No newline at end of file
+}
+
+// envFlags maps flags to the environment variables that set them when the
+// flag is not given on the command line
+var envFlags = map[string]string{
+	"swagger":                 "MCP_SWAGGER_FILE",
+	"swagger-url":             "MCP_SWAGGER_URL",
+	"api-base":                mcp.EnvAPIBase,
+	"api-key":                 mcp.EnvAPIKey,
+	"http-port":               "MCP_HTTP_PORT",
+	"http-host":               "MCP_HTTP_HOST",
+	"http-path":               "MCP_HTTP_PATH",
+	"exclude-paths":           "MCP_EXCLUDE_PATHS",
+	"exclude-operations":      "MCP_EXCLUDE_OPERATIONS",
+	"exclude-methods":         "MCP_EXCLUDE_METHODS",
+	"exclude-tags":            "MCP_EXCLUDE_TAGS",
+	"include-only-paths":      "MCP_INCLUDE_ONLY_PATHS",
+	"include-only-operations": "MCP_INCLUDE_ONLY_OPERATIONS",
+	"exclude-deprecated":      "MCP_EXCLUDE_DEPRECATED",
+}
+
+// applyEnvFlags sets each flag in envFlags that was not given on the command
+// line from its environment variable, so flags take precedence over the
+// environment
+func applyEnvFlags(fs *flag.FlagSet) error {
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	for name, env := range envFlags {
+		value, ok := os.LookupEnv(env)
+		if !ok || value == "" || given[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", env, err)
+		}
+	}
+	return nil
+}
+
+// keyValueFlag collects repeated name=value flags
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for name, value := range f {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	f[strings.TrimSpace(name)] = value
+	return nil
+}
+
+// applyAuthFlags copies the authentication flags onto config
+func applyAuthFlags(config *mcp.Config, cookies keyValueFlag, cookieParams bool) {
+	if len(cookies) > 0 {
+		merged := make(map[string]string, len(config.Cookies)+len(cookies))
+		for name, value := range config.Cookies {
+			merged[name] = value
+		}
+		for name, value := range cookies {
+			merged[name] = value
+		}
+		config.WithCookies(merged)
+	}
+	if cookieParams {
+		config.WithCookieParams()
+	}
+}
+
+// applyAllowedHosts restricts API calls to the comma-separated hosts
+func applyAllowedHosts(config *mcp.Config, hosts string) {
+	var allowed []string
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			allowed = append(allowed, host)
+		}
+	}
+	if len(allowed) > 0 {
+		config.WithAllowedHosts(allowed)
+	}
+}
+
+// applyRefHosts allows remote $refs to the comma-separated hosts
+func applyRefHosts(config *mcp.Config, hosts string) {
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			config.WithAllowedRefHosts(host)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+// newTestFlags declares a subset of main's flags on a fresh flag set.
+func newTestFlags() (*flag.FlagSet, *string, *string, *int, *bool) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	swaggerFile := fs.String("swagger", "", "")
+	apiBase := fs.String("api-base", "", "")
+	httpPort := fs.Int("http-port", 0, "")
+	excludeDeprecated := fs.Bool("exclude-deprecated", false, "")
+	return fs, swaggerFile, apiBase, httpPort, excludeDeprecated
+}
+
+func TestApplyEnvFlags_FillsUnsetFlags(t *testing.T) {
+	t.Setenv("MCP_SWAGGER_FILE", "/specs/petstore.yaml")
+	t.Setenv("MCP_API_BASE", "https://env.example.com")
+	t.Setenv("MCP_HTTP_PORT", "8127")
+	t.Setenv("MCP_EXCLUDE_DEPRECATED", "true")
+
+	fs, swaggerFile, apiBase, httpPort, excludeDeprecated := newTestFlags()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnvFlags(fs); err != nil {
+		t.Fatalf("applyEnvFlags failed: %v", err)
+	}
+
+	if *swaggerFile != "/specs/petstore.yaml" || *apiBase != "https://env.example.com" || *httpPort != 8127 || !*excludeDeprecated {
+		t.Errorf("env not applied: swagger=%q api-base=%q http-port=%d exclude-deprecated=%v",
+			*swaggerFile, *apiBase, *httpPort, *excludeDeprecated)
+	}
+}
+
+func TestApplyEnvFlags_FlagsTakePrecedence(t *testing.T) {
+	t.Setenv("MCP_API_BASE", "https://env.example.com")
+	t.Setenv("MCP_HTTP_PORT", "8127")
+
+	fs, _, apiBase, httpPort, _ := newTestFlags()
+	if err := fs.Parse([]string{"-api-base", "https://flag.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnvFlags(fs); err != nil {
+		t.Fatalf("applyEnvFlags failed: %v", err)
+	}
+
+	if *apiBase != "https://flag.example.com" {
+		t.Errorf("expected the flag to win, got %q", *apiBase)
+	}
+	if *httpPort != 8127 {
+		t.Errorf("expected the env port for the unset flag, got %d", *httpPort)
+	}
+}
+
+func TestApplyEnvFlags_InvalidValue(t *testing.T) {
+	t.Setenv("MCP_HTTP_PORT", "not-a-port")
+
+	fs, _, _, _, _ := newTestFlags()
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyEnvFlags(fs); err == nil {
+		t.Fatal("expected an error for an invalid MCP_HTTP_PORT")
+	}
+}
@@ -2,14 +2,25 @@ package mcp
 
 import (
     "bytes"
+    "compress/flate"
+    "compress/gzip"
+    "compress/zlib"
     "context"
     "encoding/json"
+    "errors"
     "fmt"
     "io"
     "net/http"
+    neturl "net/url"
+    "sort"
     "strings"
+    "sync"
+    "time"
 
     "github.com/go-openapi/spec"
+    "go.opentelemetry.io/otel/trace"
+    "golang.org/x/oauth2"
+    "golang.org/x/sync/semaphore"
 )
 
 // APIExecutor handles API request building and execution.
@@ -18,20 +29,748 @@ import (
 type APIExecutor struct {
     APIBaseURL string
     APIKey     string
+
+    // UserAgent is sent with every upstream call
+    UserAgent string
+
+    // ContentType and Accept replace the default application/json request
+    // headers. An operation's own consumes/produces media types take
+    // precedence when the spec declares them.
+    ContentType string
+    Accept      string
+
+    // AcceptPolicy decides whether Accept is negotiated against the
+    // operation's produces (the default), sent verbatim, or omitted
+    AcceptPolicy AcceptPolicy
+
+    // Headers are sent with every request; header parameters and
+    // credentials override them
+    Headers map[string]string
+
+    // OperationTimeouts overrides the timeout of the operations it names,
+    // by tool name or path template; other calls are bounded by Timeout.
+    // The client's own timeout must be unset for overrides to exceed it.
+    Timeout           time.Duration
+    OperationTimeouts map[string]time.Duration
+
+    // DefaultQueryParams are added to every request's query string unless
+    // the call supplies the same parameter
+    DefaultQueryParams map[string]string
+
+    // HTTPClient is shared by all upstream calls so connection pooling,
+    // proxy and TLS settings apply consistently
+    HTTPClient *http.Client
+
+    // Basic authentication; the password may instead come from Secrets
+    BasicAuthUsername string
+    BasicAuthPassword string
+
+    // Secrets, when set, is consulted for credentials before the values
+    // above
+    Secrets SecretProvider
+
+    // OAuth2, when set, supplies bearer tokens for every request, taking
+    // over the Authorization header from the API key and basic auth
+    OAuth2 oauth2.TokenSource
+
+    // CredentialFunc, when set, picks the credentials per operation;
+    // returning false falls back to the configured ones. op may be nil.
+    CredentialFunc func(method, path string, op *spec.Operation) (Credential, bool)
+
+    // PerCallAuth takes a request's API key from the PerCallAuthArg tool
+    // argument when present, in place of all configured credentials. The
+    // argument is never sent to the API.
+    PerCallAuth bool
+
+    // AuthHeaderMode selects the header the API key is sent in (default
+    // both X-API-Key and Authorization: Bearer)
+    AuthHeaderMode AuthHeaderMode
+
+    // Cookies are sent with every request. With CookieParams, cookie
+    // parameters declared by an operation are taken from the tool arguments
+    // and override configured cookies of the same name.
+    Cookies      map[string]string
+    CookieParams bool
+
+    // RawPathParams names path parameters whose values may contain slashes
+    // that must reach the API unescaped, e.g. "path" in /files/{path}
+    RawPathParams []string
+
+    // IdempotencyHeader, when set, names the header that carries a fresh
+    // idempotency key on each POST and PATCH call
+    IdempotencyHeader string
+
+    // MaxUpstreamAttempts caps the HTTP requests one call may send,
+    // counting followed redirects and the pages of an auto-paginated call
+    // (0 = no cap)
+    MaxUpstreamAttempts int
+
+    // StripPathPrefix is removed from the start of operation paths, and
+    // AddPathPrefix then prepended, before they are joined to the base URL
+    StripPathPrefix string
+    AddPathPrefix   string
+
+    // BooleanQueryFormat selects how boolean query arguments are written
+    // (default true/false); a parameter's x-boolean-format overrides it
+    BooleanQueryFormat BooleanQueryFormat
+
+    // AllowedHosts, when non-empty, restricts requests to these hosts
+    AllowedHosts []string
+
+    // ServerVariables, when set, are substituted into the base URL per
+    // call, from the _server_<name> arguments or their defaults
+    ServerVariables map[string]ServerVariable
+
+    // BaseURLFunc, when set, picks the base URL per operation; returning
+    // "" falls back to APIBaseURL. op may be nil for calls made without
+    // operation metadata.
+    BaseURLFunc func(method, path string, op *spec.Operation) string
+
+    // DryRun makes BuildAndExecuteRequest return a preview of the request
+    // instead of sending it
+    DryRun bool
+
+    // ResponseFormat selects how JSON responses are rendered (default
+    // compact)
+    ResponseFormat ResponseFormat
+
+    // RawResponse returns response bodies verbatim, skipping JSON
+    // re-indentation
+    RawResponse bool
+
+    // IncludeResponseHeaders names response headers ("*" for all) to return
+    // with the body in a ResponseEnvelope
+    IncludeResponseHeaders []string
+
+    // EnvelopeResults wraps every successful (2xx) result in a
+    // ResultEnvelope, carrying any IncludeResponseHeaders too
+    EnvelopeResults bool
+
+    // NDJSONArrays renders JSON array responses as newline-delimited JSON
+    NDJSONArrays bool
+
+    // ResponseValidation checks 2xx JSON bodies against the schema the
+    // operation documents for their status, appending mismatches to the
+    // result as warnings; StrictResponseValidation fails the call instead
+    ResponseValidation       bool
+    StrictResponseValidation bool
+
+    // MaxRequestBytes, when positive, rejects request bodies larger than
+    // this many bytes before they are sent
+    MaxRequestBytes int64
+
+    // StreamMaxDuration and StreamMaxBytes bound how long and how much of a
+    // text/event-stream response is read (0 selects the defaults)
+    StreamMaxDuration time.Duration
+    StreamMaxBytes    int64
+
+    // ApplyDefaults fills body and form fields the caller omitted with the
+    // defaults declared in the spec
+    ApplyDefaults bool
+
+    // ParamCoercion converts string arguments to the integer, number or
+    // boolean types the operation declares
+    ParamCoercion bool
+
+    // RequestTransform, when set, may add, remove or override a tool
+    // call's arguments before its request is built. RequestTransformArgs
+    // are the arguments it supplies, which tools do not require.
+    RequestTransform     RequestTransform
+    RequestTransformArgs []string
+
+    // ResponseTransform, when set, rewrites each response body before it is
+    // formatted; an error fails the call
+    ResponseTransform ResponseTransform
+
+    // Tracer, when set, records a client span for every upstream call and
+    // propagates the trace context in the request headers
+    Tracer trace.Tracer
+
+    // PaginationAliases maps the uniform limit/offset/page arguments onto
+    // each operation's own pagination parameters. PaginationOverrides pins
+    // the mapping for specific tools (keyed by tool name).
+    PaginationAliases   bool
+    PaginationOverrides map[string]PaginationParams
+
+    // PageFollow enables auto-pagination for the tools it names (keyed by
+    // tool name) when they are called with allPages
+    PageFollow map[string]PageFollow
+
+    // Metrics, when set, receives an observation for every upstream call
+    Metrics MetricsCollector
+
+    // cache, when set, serves repeated GET requests without calling the API
+    cache *responseCache
+
+    // inFlight, when set, bounds the number of concurrent upstream calls;
+    // excess calls wait their turn
+    inFlight *semaphore.Weighted
+
+    // recorder, when set, logs each upstream call for later replay;
+    // replayer, when set, answers calls from such a log instead
+    recorder *callRecorder
+    replayer *callReplayer
+
+    // endpointMu guards APIBaseURL and ServerVariables, which a spec
+    // reload replaces while calls are in flight
+    endpointMu sync.RWMutex
 }
 
+// RequestTransform rewrites a tool call's arguments in place before the
+// request is built, e.g. to force a tenant ID regardless of what the client
+// sent. Returning an error fails the call.
+type RequestTransform func(toolName, method, path string, args map[string]interface{}) error
+
+// ResponseTransform rewrites an upstream response body before it is
+// formatted as the tool result, e.g. to redact fields. toolName is the
+// calling tool's name.
+type ResponseTransform func(toolName string, status int, body []byte) ([]byte, error)
+
+// ResponseFormat selects how JSON response bodies are rendered as tool
+// content
+type ResponseFormat string
+
+const (
+    // ResponseFormatCompact minifies JSON, saving tokens (the default)
+    ResponseFormatCompact ResponseFormat = "compact"
+    // ResponseFormatPretty re-indents JSON with two spaces
+    ResponseFormatPretty ResponseFormat = "pretty"
+    // ResponseFormatRaw passes bodies through untouched
+    ResponseFormatRaw ResponseFormat = "raw"
+)
+
+// AcceptPolicy selects how the Accept header of upstream calls is chosen
+type AcceptPolicy string
+
+const (
+    // AcceptNegotiate picks Accept from the operation's produces, falling
+    // back to the configured Accept (the default)
+    AcceptNegotiate AcceptPolicy = "negotiate"
+    // AcceptFixed sends the configured Accept on every call, whatever the
+    // operation produces; an empty one sends no Accept header
+    AcceptFixed AcceptPolicy = "fixed"
+    // AcceptOmit sends no Accept header, for upstreams whose strict content
+    // negotiation refuses the types the spec declares
+    AcceptOmit AcceptPolicy = "omit"
+)
+
+// PerCallAuthArg is the tool argument that carries a per-call API key when
+// PerCallAuth is enabled
+const PerCallAuthArg = "_apiKey"
+
+// ErrRequestTooLarge is returned when a request body exceeds MaxRequestBytes
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// ErrInvalidResponse is returned in strict response validation mode when a
+// response does not match its documented schema
+var ErrInvalidResponse = errors.New("response does not match the spec")
+
+// ErrInsecureHTTP is returned by New for a plaintext http base URL to a
+// remote host unless AllowInsecureHTTP is set
+var ErrInsecureHTTP = errors.New("insecure http base URL")
+
+// ErrHostNotAllowed is returned for requests to a host outside AllowedHosts
+var ErrHostNotAllowed = errors.New("host not allowed")
+
 // NewAPIExecutor creates a new API executor
 func NewAPIExecutor(apiBaseURL, apiKey string) *APIExecutor {
     return &APIExecutor{
         APIBaseURL: apiBaseURL,
         APIKey:     apiKey,
+        UserAgent:  defaultUserAgent(""),
+        HTTPClient: newHTTPClient(nil),
+    }
+}
+
+// userAgentProduct is the product token of the default User-Agent
+const userAgentProduct = "mcp-swagger-server"
+
+// defaultUserAgent returns the User-Agent for a server version, e.g.
+// "mcp-swagger-server/1.0.0"
+func defaultUserAgent(version string) string {
+    version = strings.TrimPrefix(version, "v")
+    if version == "" {
+        return userAgentProduct
     }
+    return userAgentProduct + "/" + version
+}
+
+// newAPIExecutorFromConfig creates an API executor carrying the execution
+// options set on config
+func newAPIExecutorFromConfig(config *Config) *APIExecutor {
+    executor := NewAPIExecutor(config.APIBaseURL, config.APIKey)
+    executor.HTTPClient = newHTTPClient(config)
+    executor.UserAgent = config.UserAgent
+    if executor.UserAgent == "" {
+        executor.UserAgent = defaultUserAgent(config.Version)
+    }
+    executor.Headers = config.Headers
+    executor.DefaultQueryParams = config.DefaultQueryParams
+    if len(config.OperationTimeouts) > 0 {
+        executor.Timeout = config.Timeout
+        executor.OperationTimeouts = config.OperationTimeouts
+    }
+    executor.ContentType = config.ContentType
+    executor.Accept = config.Accept
+    executor.AcceptPolicy = config.AcceptPolicy
+    executor.BaseURLFunc = config.BaseURLFunc
+    if config.ServerVariableArgs {
+        _, executor.ServerVariables = serverTemplate(config.SwaggerSpec)
+    }
+    executor.RawPathParams = config.RawPathParams
+    executor.IdempotencyHeader = config.IdempotencyHeader
+    executor.MaxUpstreamAttempts = config.MaxUpstreamAttempts
+    executor.StripPathPrefix = config.StripPathPrefix
+    executor.AddPathPrefix = config.AddPathPrefix
+    executor.BooleanQueryFormat = config.BooleanQueryFormat
+    executor.AllowedHosts = config.AllowedHosts
+    executor.BasicAuthUsername = config.BasicAuthUsername
+    executor.BasicAuthPassword = config.BasicAuthPassword
+    executor.Secrets = config.SecretProvider
+    if config.OAuth2 != nil {
+        executor.OAuth2 = newOAuth2TokenSource(config.OAuth2, config.SecretProvider, executor.HTTPClient)
+    }
+    executor.Cookies = config.Cookies
+    executor.CookieParams = config.CookieParams
+    executor.PerCallAuth = config.PerCallAuth
+    executor.AuthHeaderMode = config.AuthHeaderMode
+    executor.CredentialFunc = config.CredentialFunc
+    executor.DryRun = config.DryRun
+    executor.RawResponse = config.RawResponsePassthrough
+    executor.ResponseFormat = config.ResponseFormat
+    executor.NDJSONArrays = config.NDJSONArrays
+    executor.IncludeResponseHeaders = config.IncludeResponseHeaders
+    executor.EnvelopeResults = config.ResponseEnvelope
+    executor.ApplyDefaults = config.ApplyDefaults
+    executor.ParamCoercion = config.ParamCoercion
+    executor.MaxRequestBytes = config.MaxRequestBytes
+    executor.StreamMaxDuration = config.StreamMaxDuration
+    executor.StreamMaxBytes = config.StreamMaxBytes
+    executor.ResponseValidation = config.ResponseValidation || config.StrictResponseValidation
+    executor.StrictResponseValidation = config.StrictResponseValidation
+    executor.RequestTransform = config.RequestTransform
+    executor.RequestTransformArgs = config.RequestTransformArgs
+    executor.ResponseTransform = config.ResponseTransform
+    executor.Tracer = config.Tracer
+    executor.PaginationAliases = config.PaginationAliases
+    executor.PaginationOverrides = config.PaginationOverrides
+    executor.PageFollow = config.PageFollow
+    executor.Metrics = config.Metrics
+    if config.ResponseCacheTTL > 0 {
+        executor.cache = newResponseCache(config.ResponseCacheTTL, config.ResponseCacheMaxEntries)
+    }
+    if config.MaxConcurrency > 0 {
+        executor.inFlight = semaphore.NewWeighted(int64(config.MaxConcurrency))
+    }
+    if config.RecordPath != "" {
+        executor.recorder = &callRecorder{path: config.RecordPath}
+    }
+    return executor
+}
+
+// APIResult is the outcome of an upstream API call
+type APIResult struct {
+    Content    string      // Response body formatted for the tool result
+    StatusCode int         // HTTP status code (0 for dry runs)
+    Header     http.Header // Response headers
+    Body       []byte      // Raw response body
 }
 
 // BuildAndExecuteRequest builds and executes an API request
 func (e *APIExecutor) BuildAndExecuteRequest(ctx context.Context, method, path string, args map[string]interface{}) (string, int, error) {
+    result, err := e.ExecuteOperation(ctx, method, path, nil, args)
+    if err != nil {
+        if result != nil {
+            return "", result.StatusCode, err
+        }
+        return "", 0, err
+    }
+    return result.Content, result.StatusCode, nil
+}
+
+// ExecuteOperation builds and executes an API request for a spec operation.
+// The operation metadata (which may be nil) drives spec-aware argument
+// handling such as pagination aliases.
+func (e *APIExecutor) ExecuteOperation(ctx context.Context, method, path string, op *spec.Operation, args map[string]interface{}) (result *APIResult, err error) {
+    statusCode := 0
+    ctx, span := e.tracer().Start(ctx, method+" "+path,
+        trace.WithSpanKind(trace.SpanKindClient),
+        trace.WithAttributes(attrHTTPMethod.String(method), attrHTTPRoute.String(path)))
+    defer func() { endSpan(span, statusCode, err) }()
+    ctx = withAttemptBudget(ctx, e.MaxUpstreamAttempts)
+
+    if timeout := e.operationTimeout(method, path, op); timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, timeout)
+        defer cancel()
+    }
+
+    if e.PaginationAliases && op != nil {
+        applyPaginationAliases(args, e.paginationParams(method, path, op))
+    }
+
+    // Building the request consumes some arguments, so keep a copy of the
+    // call as made for recording and replay
+    var callArgs map[string]interface{}
+    if e.recorder != nil || e.replayer != nil {
+        callArgs = recordedArgs(args)
+    }
+    if e.replayer != nil {
+        tool := toolNameFor(method, path, op)
+        record, ok := e.replayer.lookup(tool, callArgs)
+        if !ok {
+            return nil, fmt.Errorf("%w for %s with these arguments", ErrNoRecording, tool)
+        }
+        statusCode = record.Status
+        return e.replayResult(record), nil
+    }
+
+    httpReq, bodyBytes, err := e.buildRequest(ctx, method, path, op, args)
+    if err != nil {
+        return nil, err
+    }
+
+    // In dry-run mode, describe the request instead of sending it
+    if e.DryRun {
+        preview, err := previewRequest(httpReq, bodyBytes, e.AuthHeaderMode.customHeader())
+        if err != nil {
+            return nil, err
+        }
+        return &APIResult{Content: preview}, nil
+    }
+
+    // Serve repeated GET requests from the cache, revalidating expired
+    // responses that carry an ETag
+    var key string
+    var stale *APIResult
+    if e.cache != nil && method == http.MethodGet && !cacheSkipped(ctx) {
+        key = cacheKey(httpReq, e.AuthHeaderMode.customHeader(), headerParamNames(op))
+        if cached, ok := e.cache.get(key); ok {
+            statusCode = cached.StatusCode
+            return cached, nil
+        }
+        if cached, ok := e.cache.stale(key); ok {
+            stale = cached
+            httpReq.Header.Set("If-None-Match", cached.Header.Get("ETag"))
+        }
+    }
+
+    if err := spendAttempt(ctx); err != nil {
+        return nil, fmt.Errorf("request not sent: %w", err)
+    }
+
+    // Wait for a free slot when concurrent calls are limited
+    if e.inFlight != nil {
+        if err := e.inFlight.Acquire(ctx, 1); err != nil {
+            return nil, fmt.Errorf("request not sent: %w", err)
+        }
+        defer e.inFlight.Release(1)
+    }
+
+    // Execute request
+    if e.Metrics != nil {
+        start := time.Now()
+        defer func() { e.Metrics.RecordToolCall(toolNameFor(method, path, op), statusCode, time.Since(start), err) }()
+    }
+    injectTraceHeaders(ctx, httpReq)
+    client := e.client()
+    if e.MaxUpstreamAttempts > 0 {
+        client = budgetedClient(client)
+    }
+    resp, err := client.Do(httpReq)
+    if err != nil {
+        return nil, fmt.Errorf("request failed: %w", err)
+    }
+    defer func() { _ = resp.Body.Close() }()
+    statusCode = resp.StatusCode
+
+    // The cached copy is still current: renew it without a body download
+    if stale != nil && resp.StatusCode == http.StatusNotModified {
+        e.cache.put(key, stale)
+        statusCode = stale.StatusCode
+        return stale, nil
+    }
+
+    // Read response; an event stream may never end, so it is read within
+    // limits and returned as the events received
+    var responseBody []byte
+    if isEventStream(resp.Header) {
+        responseBody, err = readEventStream(resp.Body, e.StreamMaxDuration, e.StreamMaxBytes)
+    } else {
+        responseBody, err = readResponseBody(resp)
+    }
+    if err != nil {
+        return &APIResult{StatusCode: resp.StatusCode, Header: resp.Header}, fmt.Errorf("failed to read response: %w", err)
+    }
+    if e.recorder != nil {
+        e.recorder.record(CallRecord{
+            Tool:         toolNameFor(method, path, op),
+            Method:       method,
+            URL:          redactURL(httpReq.URL),
+            Args:         callArgs,
+            Status:       resp.StatusCode,
+            ResponseBody: string(responseBody),
+        })
+    }
+    var problems []string
+    if e.ResponseValidation {
+        problems = validateResponse(op, resp.StatusCode, responseBody)
+        if len(problems) > 0 && e.StrictResponseValidation {
+            return &APIResult{StatusCode: resp.StatusCode, Header: resp.Header, Body: responseBody},
+                fmt.Errorf("%w: %s", ErrInvalidResponse, strings.Join(problems, "; "))
+        }
+    }
+    if e.ResponseTransform != nil {
+        responseBody, err = e.ResponseTransform(toolNameFor(method, path, op), resp.StatusCode, responseBody)
+        if err != nil {
+            return &APIResult{StatusCode: resp.StatusCode, Header: resp.Header}, fmt.Errorf("response transform failed: %w", err)
+        }
+    }
+
+    result = &APIResult{
+        Content:    e.formatResponse(responseBody),
+        StatusCode: resp.StatusCode,
+        Header:     resp.Header,
+        Body:       responseBody,
+    }
+    // An empty success body would leave the model unsure whether the call
+    // worked, so say so explicitly
+    success := resp.StatusCode >= 200 && resp.StatusCode < 300
+    if success && len(bytes.TrimSpace(responseBody)) == 0 {
+        result.Content = emptySuccessContent(resp.StatusCode)
+    }
+    if method == http.MethodHead {
+        // A HEAD response has no body: its status and headers are the answer
+        result.Content = headResponseContent(resp.StatusCode, resp.Header)
+    } else if e.EnvelopeResults && success {
+        content := result.Content
+        if len(bytes.TrimSpace(responseBody)) == 0 {
+            content = ""
+        }
+        result.Content = envelopeResult(resp.StatusCode, content, resp.Header, e.IncludeResponseHeaders, e.ResponseFormat == ResponseFormatPretty)
+    } else if len(e.IncludeResponseHeaders) > 0 {
+        result.Content = envelopeResponse(result.Content, resp.Header, e.IncludeResponseHeaders, e.ResponseFormat == ResponseFormatPretty)
+    }
+    if len(problems) > 0 {
+        result.Content += "\n\nResponse validation warnings:\n- " + strings.Join(problems, "\n- ")
+    }
+    // Lead an unfollowed redirect with where it points; its body, if any,
+    // is usually just a stub
+    if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 {
+        note := fmt.Sprintf("%s: redirect to %s", resp.Status, location)
+        if result.Content != "" {
+            note += "\n\n" + result.Content
+        }
+        result.Content = note
+    }
+    if key != "" && cacheable(resp) {
+        e.cache.put(key, result)
+    }
+    return result, nil
+}
+
+// operationTimeout returns the timeout of a call when per-operation
+// timeouts are configured: the override for its tool name, else for its
+// path, else Timeout. It returns 0 when there is none to apply.
+func (e *APIExecutor) operationTimeout(method, path string, op *spec.Operation) time.Duration {
+    if len(e.OperationTimeouts) == 0 {
+        return 0
+    }
+    if timeout, ok := e.OperationTimeouts[toolNameFor(method, path, op)]; ok {
+        return timeout
+    }
+    if timeout, ok := e.OperationTimeouts[path]; ok {
+        return timeout
+    }
+    return e.Timeout
+}
+
+// replayResult turns a recorded call into the result of a live one
+func (e *APIExecutor) replayResult(record CallRecord) *APIResult {
+    body := []byte(record.ResponseBody)
+    result := &APIResult{
+        Content:    e.formatResponse(body),
+        StatusCode: record.Status,
+        Header:     http.Header{},
+        Body:       body,
+    }
+    if record.Status >= 200 && record.Status < 300 && len(bytes.TrimSpace(body)) == 0 {
+        result.Content = emptySuccessContent(record.Status)
+    }
+    return result
+}
+
+// emptySuccessContent describes a 2xx response without a body
+func emptySuccessContent(statusCode int) string {
+    message := http.StatusText(statusCode)
+    if message == "" {
+        message = "Success"
+    }
+    content, _ := json.Marshal(struct {
+        Status  int    `json:"status"`
+        Message string `json:"message"`
+    }{statusCode, message})
+    return string(content)
+}
+
+// readResponseBody reads a response body, decoding gzip and deflate content
+// encodings. Go's transport only decompresses responses to requests whose
+// Accept-Encoding it set itself, so encoded bodies can still arrive here.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+    encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+
+    var reader io.Reader = resp.Body
+    switch encoding {
+    case "gzip", "x-gzip":
+        gz, err := gzip.NewReader(resp.Body)
+        if err != nil {
+            return nil, fmt.Errorf("invalid gzip body: %w", err)
+        }
+        defer func() { _ = gz.Close() }()
+        reader = gz
+    case "deflate":
+        // "deflate" is specified as zlib-wrapped, but some servers send
+        // raw deflate data
+        raw, err := io.ReadAll(resp.Body)
+        if err != nil {
+            return nil, err
+        }
+        if zr, err := zlib.NewReader(bytes.NewReader(raw)); err == nil {
+            defer func() { _ = zr.Close() }()
+            reader = zr
+        } else {
+            reader = flate.NewReader(bytes.NewReader(raw))
+        }
+    default:
+        return io.ReadAll(resp.Body)
+    }
+
+    body, err := io.ReadAll(reader)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode %s body: %w", encoding, err)
+    }
+    // The body handed on is decoded
+    resp.Header.Del("Content-Encoding")
+    resp.Header.Del("Content-Length")
+    return body, nil
+}
+
+// formatResponse renders a response body as tool content. JSON bodies are
+// minified, or re-indented in pretty mode; raw mode and non-JSON bodies
+// are passed through.
+func (e *APIExecutor) formatResponse(responseBody []byte) string {
+    format := e.ResponseFormat
+    if e.RawResponse {
+        format = ResponseFormatRaw
+    }
+    if format == ResponseFormatRaw {
+        return string(responseBody)
+    }
+
+    // Try to format JSON response
+    var jsonResponse interface{}
+    if err := unmarshalJSONNumbers(responseBody, &jsonResponse); err == nil {
+        if items, ok := jsonResponse.([]interface{}); ok && e.NDJSONArrays {
+            return formatNDJSON(items)
+        }
+        if format == ResponseFormatPretty {
+            formattedJSON, _ := json.MarshalIndent(jsonResponse, "", "  ")
+            return string(formattedJSON)
+        }
+        // Compacting the original bytes keeps key order and number precision
+        var compacted bytes.Buffer
+        if err := json.Compact(&compacted, responseBody); err == nil {
+            return compacted.String()
+        }
+    }
+    return string(responseBody)
+}
+
+// client returns the HTTP client used for upstream calls
+func (e *APIExecutor) client() *http.Client {
+    if e.HTTPClient == nil {
+        return http.DefaultClient
+    }
+    return e.HTTPClient
+}
+
+// baseURL returns the base URL for an operation, consulting BaseURLFunc
+// before falling back to the static APIBaseURL, along with the server
+// variables to substitute into it
+func (e *APIExecutor) baseURL(method, path string, op *spec.Operation) (string, map[string]ServerVariable) {
+    apiBaseURL, variables := e.endpoint()
+    if e.BaseURLFunc != nil {
+        if base := e.BaseURLFunc(method, path, op); base != "" {
+            return base, variables
+        }
+    }
+    return apiBaseURL, variables
+}
+
+// endpoint returns APIBaseURL and ServerVariables as one consistent pair
+func (e *APIExecutor) endpoint() (string, map[string]ServerVariable) {
+    e.endpointMu.RLock()
+    defer e.endpointMu.RUnlock()
+    return e.APIBaseURL, e.ServerVariables
+}
+
+// setEndpoint replaces APIBaseURL and ServerVariables, e.g. when a reloaded
+// spec declares different servers
+func (e *APIExecutor) setEndpoint(apiBaseURL string, variables map[string]ServerVariable) {
+    e.endpointMu.Lock()
+    defer e.endpointMu.Unlock()
+    e.APIBaseURL = apiBaseURL
+    e.ServerVariables = variables
+}
+
+// bodySchema returns the schema of an operation's body parameter and a
+// name for its XML root element: the parameter's name, or "request" for the
+// generic "body"
+func bodySchema(op *spec.Operation) (*spec.Schema, string) {
+    if op != nil {
+        for _, param := range op.Parameters {
+            if param.In == "body" {
+                if param.Name != "" && param.Name != "body" {
+                    return param.Schema, param.Name
+                }
+                return param.Schema, "request"
+            }
+        }
+    }
+    return nil, "request"
+}
+
+// toolNameFor names the tool behind a call, falling back to the method and
+// route when no operation metadata is available
+func toolNameFor(method, path string, op *spec.Operation) string {
+    if op == nil {
+        return method + " " + path
+    }
+    return GenerateToolName(method, path, op)
+}
+
+// buildRequest resolves the URL, body and headers for an API call. It
+// returns the request along with the marshaled body (nil if there is none).
+func (e *APIExecutor) buildRequest(ctx context.Context, method, path string, op *spec.Operation, args map[string]interface{}) (*http.Request, []byte, error) {
     // Build URL with path parameters
-    url := e.APIBaseURL + path
+    base, variables := e.baseURL(method, path, op)
+    if len(variables) > 0 {
+        expanded, err := expandServerURL(base, variables, takeServerVariableArgs(args, variables))
+        if err != nil {
+            return nil, nil, err
+        }
+        base = expanded
+    }
+    url := joinURL(base, e.requestPath(path))
+
+    // Take out a per-call API key before anything can forward it
+    var callAPIKey string
+    if e.PerCallAuth {
+        if value, exists := args[PerCallAuthArg]; exists {
+            callAPIKey = fmt.Sprintf("%v", value)
+            delete(args, PerCallAuthArg)
+        }
+    }
 
     // Extract body parameter if present
     var bodyData interface{}
@@ -40,17 +779,77 @@ func (e *APIExecutor) BuildAndExecuteRequest(ctx context.Context, method, path s
         delete(args, "body")
     }
 
+    // Fill in spec defaults for omitted body and form fields
+    if e.ApplyDefaults && op != nil {
+        applyParameterDefaults(op.Parameters, args, bodyData)
+    }
+
+    // Convert string arguments to their declared types
+    if e.ParamCoercion && op != nil {
+        bodyData = coerceParameterArgs(op.Parameters, args, bodyData)
+    }
+
+    // Extract cookie parameters so they are not sent as query or body
+    var cookies []*http.Cookie
+    if e.CookieParams && op != nil {
+        for _, param := range op.Parameters {
+            if param.In != "cookie" {
+                continue
+            }
+            if value, exists := args[param.Name]; exists {
+                cookies = append(cookies, &http.Cookie{Name: param.Name, Value: fmt.Sprintf("%v", value)})
+                delete(args, param.Name)
+            }
+        }
+    }
+
     // Replace path parameters
     for key, value := range args {
         placeholder := "{" + key + "}"
         if strings.Contains(url, placeholder) {
-            url = strings.ReplaceAll(url, placeholder, fmt.Sprintf("%v", value))
+            url = strings.ReplaceAll(url, placeholder, escapePathValue(fmt.Sprintf("%v", value), e.isRawPathParam(key, op)))
             delete(args, key)
         }
     }
 
-    // Prepare request body
-    var body io.Reader
+    // Pull out header parameters so they are sent as request headers
+    headers := make(map[string]string)
+    if op != nil {
+        for _, param := range op.Parameters {
+            if param.In != "header" || isAuthHeader(param.Name) {
+                continue
+            }
+            if value, exists := args[param.Name]; exists {
+                headers[param.Name] = fmt.Sprintf("%v", value)
+                delete(args, param.Name)
+            }
+        }
+    }
+
+    // Pull out the operation's query parameters so mutating requests can
+    // send them alongside a body
+    query := make(map[string]interface{})
+    queryParams := make(map[string]*spec.Parameter)
+    if op != nil {
+        for i, param := range op.Parameters {
+            if param.In != "query" {
+                continue
+            }
+            queryParams[param.Name] = &op.Parameters[i]
+            if value, exists := args[param.Name]; exists {
+                query[param.Name] = value
+                delete(args, param.Name)
+            }
+        }
+    }
+
+    // Prepare request body, as XML when the operation only consumes XML
+    var consumes []string
+    if op != nil {
+        consumes = op.Consumes
+    }
+    contentType := requestMediaType(consumes, e.ContentType)
+    var bodyBytes []byte
     if method == "POST" || method == "PUT" || method == "PATCH" {
         var dataToSend interface{}
         if bodyData != nil {
@@ -60,90 +859,406 @@ func (e *APIExecutor) BuildAndExecuteRequest(ctx context.Context, method, path s
         }
 
         if dataToSend != nil {
-            jsonData, err := json.Marshal(dataToSend)
+            var data []byte
+            var err error
+            if isXMLMediaType(contentType) {
+                schema, rootName := bodySchema(op)
+                data, err = marshalXMLBody(schema, rootName, dataToSend)
+            } else {
+                data, err = json.Marshal(dataToSend)
+            }
             if err != nil {
-                return "", 0, fmt.Errorf("failed to marshal request body: %w", err)
+                return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
+            }
+            if e.MaxRequestBytes > 0 && int64(len(data)) > e.MaxRequestBytes {
+                return nil, nil, fmt.Errorf("%w: body is %d bytes, limit is %d", ErrRequestTooLarge, len(data), e.MaxRequestBytes)
             }
-            body = bytes.NewReader(jsonData)
+            bodyBytes = data
         }
     } else {
         // Add remaining args as query parameters
-        if len(args) > 0 {
-            queryParams := []string{}
-            for key, value := range args {
-                queryParams = append(queryParams, fmt.Sprintf("%s=%v", key, value))
-            }
-            if strings.Contains(url, "?") {
-                url += "&" + strings.Join(queryParams, "&")
-            } else {
-                url += "?" + strings.Join(queryParams, "&")
-            }
+        for key, value := range args {
+            query[key] = value
+        }
+    }
+    for name, value := range e.DefaultQueryParams {
+        if _, exists := query[name]; !exists {
+            query[name] = value
         }
     }
+    url = appendQuery(url, query, queryParams, e.BooleanQueryFormat)
 
     // Create HTTP request
+    var body io.Reader
+    if bodyBytes != nil {
+        body = bytes.NewReader(bodyBytes)
+    }
     httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
     if err != nil {
-        return "", 0, fmt.Errorf("failed to create request: %w", err)
+        return nil, nil, fmt.Errorf("failed to create request: %w", err)
+    }
+    if err := checkAllowedHost(e.AllowedHosts, httpReq.URL); err != nil {
+        return nil, nil, err
+    }
+    if bodyBytes != nil {
+        httpReq.ContentLength = int64(len(bodyBytes))
     }
 
     // Set headers
-    if body != nil {
-        httpReq.Header.Set("Content-Type", "application/json")
+    if bodyBytes != nil {
+        httpReq.Header.Set("Content-Type", contentType)
+    }
+    var produces []string
+    if op != nil {
+        produces = op.Produces
+    }
+    switch e.AcceptPolicy {
+    case AcceptOmit:
+        // No Accept header at all
+    case AcceptFixed:
+        if e.Accept != "" {
+            httpReq.Header.Set("Accept", e.Accept)
+        }
+    default:
+        httpReq.Header.Set("Accept", acceptMediaTypes(produces, e.Accept))
     }
-    httpReq.Header.Set("Accept", "application/json")
+    if e.UserAgent != "" {
+        httpReq.Header.Set("User-Agent", e.UserAgent)
+    }
+    for name, value := range e.Headers {
+        httpReq.Header.Set(name, value)
+    }
+    for name, value := range headers {
+        httpReq.Header.Set(name, value)
+    }
+    if err := setIdempotencyKey(httpReq, e.IdempotencyHeader); err != nil {
+        return nil, nil, err
+    }
+    e.addCookies(httpReq, cookies)
 
-    // Add API key if configured
-    if e.APIKey != "" {
-        httpReq.Header.Set("X-API-Key", e.APIKey)
-        httpReq.Header.Set("Authorization", "Bearer "+e.APIKey)
+    // Add credentials if configured
+    if err := e.applyAuth(httpReq, method, path, op, callAPIKey); err != nil {
+        return nil, nil, err
     }
 
-    // Execute request
-    client := &http.Client{}
-    resp, err := client.Do(httpReq)
-    if err != nil {
-        return "", 0, fmt.Errorf("request failed: %w", err)
+    return httpReq, bodyBytes, nil
+}
+
+// requestPath rewrites an operation's spec path for the request URL:
+// StripPathPrefix is removed when the path starts with it at a segment
+// boundary, then AddPathPrefix is prepended
+func (e *APIExecutor) requestPath(path string) string {
+    if prefix := strings.Trim(e.StripPathPrefix, "/"); prefix != "" {
+        prefix = "/" + prefix
+        if path == prefix {
+            path = "/"
+        } else if strings.HasPrefix(path, prefix+"/") {
+            path = strings.TrimPrefix(path, prefix)
+        }
     }
-    defer func() { _ = resp.Body.Close() }()
+    if prefix := strings.Trim(e.AddPathPrefix, "/"); prefix != "" {
+        path = strings.TrimRight("/"+prefix+"/"+strings.TrimLeft(path, "/"), "/")
+    }
+    return path
+}
+
+// isRawPathParam reports whether a path parameter holds a slash-separated
+// value, such as a file path, whose slashes must not be escaped. That is
+// the case for parameters listed in RawPathParams and for those the spec
+// marks with format "path" or an x-raw-path or x-allow-reserved extension.
+func (e *APIExecutor) isRawPathParam(name string, op *spec.Operation) bool {
+    for _, raw := range e.RawPathParams {
+        if raw == name {
+            return true
+        }
+    }
+    if op == nil {
+        return false
+    }
+    for _, param := range op.Parameters {
+        if param.In != "path" || param.Name != name {
+            continue
+        }
+        if param.Format == "path" {
+            return true
+        }
+        for _, key := range []string{"x-raw-path", "x-allow-reserved"} {
+            if raw, ok := param.Extensions.GetBool(key); ok && raw {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// escapePathValue escapes a path parameter value. Raw values keep their
+// slashes, with each segment escaped on its own.
+func escapePathValue(value string, raw bool) string {
+    if !raw {
+        return neturl.PathEscape(value)
+    }
+    segments := strings.Split(value, "/")
+    for i, segment := range segments {
+        segments[i] = neturl.PathEscape(segment)
+    }
+    return strings.Join(segments, "/")
+}
+
+// appendQuery adds params to a URL's query string, serialized per their
+// declared parameters, with booleans written in the given format, and
+// sorted by name so identical calls produce identical URLs
+func appendQuery(rawURL string, params map[string]interface{}, declared map[string]*spec.Parameter, booleans BooleanQueryFormat) string {
+    if len(params) == 0 {
+        return rawURL
+    }
+    values := neturl.Values{}
+    for key, value := range params {
+        addQueryValue(values, key, value, declared[key], booleans)
+    }
+    if strings.Contains(rawURL, "?") {
+        return rawURL + "&" + values.Encode()
+    }
+    return rawURL + "?" + values.Encode()
+}
+
+// negotiateMediaType picks the media type for a JSON request or response
+// header. Among the types the spec declares, the configured one wins if
+// listed, else the first JSON type; without a usable spec type the
+// configured one applies, defaulting to application/json.
+func negotiateMediaType(declared []string, configured string) string {
+    var firstJSON string
+    for _, mediaType := range declared {
+        if configured != "" && strings.EqualFold(mediaType, configured) {
+            return mediaType
+        }
+        if firstJSON == "" && isJSONMediaType(mediaType) {
+            firstJSON = mediaType
+        }
+    }
+    if firstJSON != "" {
+        return firstJSON
+    }
+    if configured != "" {
+        return configured
+    }
+    return "application/json"
+}
+
+// acceptMediaTypes picks the Accept header for an operation. It negotiates
+// like negotiateMediaType, except that an operation producing only
+// non-JSON types (e.g. text/csv) is sent all of them, since asking for
+// JSON would draw a 406.
+func acceptMediaTypes(produces []string, configured string) string {
+    for _, mediaType := range produces {
+        if isJSONMediaType(mediaType) || (configured != "" && strings.EqualFold(mediaType, configured)) {
+            return negotiateMediaType(produces, configured)
+        }
+    }
+    if len(produces) > 0 {
+        return strings.Join(produces, ", ")
+    }
+    return negotiateMediaType(nil, configured)
+}
+
+// isJSONMediaType reports whether a media type carries JSON, e.g.
+// application/json or application/vnd.api+json
+func isJSONMediaType(mediaType string) bool {
+    mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+    return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// addCookies attaches the configured cookies and the per-call cookies to a
+// request, letting per-call values replace configured ones of the same name
+func (e *APIExecutor) addCookies(req *http.Request, perCall []*http.Cookie) {
+    overridden := make(map[string]bool, len(perCall))
+    for _, cookie := range perCall {
+        overridden[cookie.Name] = true
+    }
+
+    names := make([]string, 0, len(e.Cookies))
+    for name := range e.Cookies {
+        if !overridden[name] {
+            names = append(names, name)
+        }
+    }
+    sort.Strings(names)
+    for _, name := range names {
+        req.AddCookie(&http.Cookie{Name: name, Value: e.Cookies[name]})
+    }
+    for _, cookie := range perCall {
+        req.AddCookie(cookie)
+    }
+}
+
+// authHeaders are set from the server's own credentials and never taken
+// from tool arguments
+var authHeaders = map[string]bool{
+    "Authorization":       true,
+    "Proxy-Authorization": true,
+    "Cookie":              true,
+    "X-Api-Key":           true,
+    "Api-Key":             true,
+}
+
+// headerParamNames returns the names of an operation's header parameters
+func headerParamNames(op *spec.Operation) []string {
+    if op == nil {
+        return nil
+    }
+    var names []string
+    for _, param := range op.Parameters {
+        if param.In == "header" {
+            names = append(names, param.Name)
+        }
+    }
+    return names
+}
 
-    // Read response
-    responseBody, err := io.ReadAll(resp.Body)
+// isAuthHeader reports whether a header parameter carries credentials
+func isAuthHeader(name string) bool {
+    return authHeaders[http.CanonicalHeaderKey(name)]
+}
+
+// secrets returns the provider consulted for credentials: the configured
+// SecretProvider first, then the values set directly on the executor
+func (e *APIExecutor) secrets() SecretProvider {
+    return ChainSecrets{e.Secrets, StaticSecrets{
+        SecretAPIKey:            e.APIKey,
+        SecretBasicAuthPassword: e.BasicAuthPassword,
+    }}
+}
+
+// applyAuth sets the authentication headers for a request. Basic auth, when
+// configured, takes over the Authorization header from the API key. A
+// per-call API key, then a credential from CredentialFunc, replaces all
+// configured credentials.
+func (e *APIExecutor) applyAuth(req *http.Request, method, path string, op *spec.Operation, callAPIKey string) error {
+    if callAPIKey != "" {
+        Credential{APIKey: callAPIKey}.apply(req, e.AuthHeaderMode)
+        return nil
+    }
+    if e.CredentialFunc != nil {
+        if credential, ok := e.CredentialFunc(method, path, op); ok {
+            credential.apply(req, e.AuthHeaderMode)
+            return nil
+        }
+    }
+
+    secrets := e.secrets()
+
+    apiKey, err := lookupSecret(secrets, SecretAPIKey)
     if err != nil {
-        return "", resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+        return err
+    }
+    if apiKey != "" {
+        e.AuthHeaderMode.setAPIKey(req, apiKey)
     }
 
-    // Try to format JSON response
-    var jsonResponse interface{}
-    var content string
-    if err := json.Unmarshal(responseBody, &jsonResponse); err == nil {
-        formattedJSON, _ := json.MarshalIndent(jsonResponse, "", "  ")
-        content = string(formattedJSON)
-    } else {
-        content = string(responseBody)
+    if e.BasicAuthUsername != "" {
+        password, err := lookupSecret(secrets, SecretBasicAuthPassword)
+        if err != nil {
+            return err
+        }
+        req.SetBasicAuth(e.BasicAuthUsername, password)
+    }
+
+    if e.OAuth2 != nil {
+        token, err := e.OAuth2.Token()
+        if err != nil {
+            return err
+        }
+        token.SetAuthHeader(req)
     }
 
-    return content, resp.StatusCode, nil
+    return nil
+}
+
+// formatNDJSON renders array items as newline-delimited JSON, one compact
+// item per line
+func formatNDJSON(items []interface{}) string {
+    lines := make([]string, 0, len(items))
+    for _, item := range items {
+        line, _ := json.Marshal(item)
+        lines = append(lines, string(line))
+    }
+    return strings.Join(lines, "\n")
+}
+
+// RequestPreview describes the request a tool call would send. It is
+// returned as the tool result when the executor runs in dry-run mode.
+type RequestPreview struct {
+    DryRun  bool              `json:"dryRun"`
+    Method  string            `json:"method"`
+    URL     string            `json:"url"`
+    Headers map[string]string `json:"headers,omitempty"`
+    Body    interface{}       `json:"body,omitempty"`
+}
+
+// previewRequest renders a built request as an indented JSON preview with
+// credentials redacted, including the custom API key header if any.
+func previewRequest(req *http.Request, bodyBytes []byte, authHeader string) (string, error) {
+    preview := RequestPreview{
+        DryRun:  true,
+        Method:  req.Method,
+        URL:     req.URL.String(),
+        Headers: redactHeaders(req.Header, authHeader),
+    }
+
+    if bodyBytes != nil {
+        var body interface{}
+        if err := unmarshalJSONNumbers(bodyBytes, &body); err == nil {
+            preview.Body = body
+        } else {
+            preview.Body = string(bodyBytes)
+        }
+    }
+
+    data, err := json.MarshalIndent(preview, "", "  ")
+    if err != nil {
+        return "", fmt.Errorf("failed to render request preview: %w", err)
+    }
+    return string(data), nil
+}
+
+// sensitiveHeaders lists request headers whose values must never be echoed
+var sensitiveHeaders = map[string]bool{
+    "Authorization": true,
+    "X-Api-Key":     true,
+    "Cookie":        true,
+}
+
+// redactHeaders flattens request headers, masking credential values and
+// the value of authHeader when it is set
+func redactHeaders(header http.Header, authHeader string) map[string]string {
+    out := make(map[string]string, len(header))
+    for name, values := range header {
+        if sensitiveHeaders[http.CanonicalHeaderKey(name)] || (authHeader != "" && http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(authHeader)) {
+            out[name] = "[REDACTED]"
+            continue
+        }
+        out[name] = strings.Join(values, ", ")
+    }
+    return out
 }
 
 // FindOperationByToolName finds the operation that matches a tool name
 func FindOperationByToolName(toolName string, swagger *spec.Swagger, filter *APIFilter) (string, string, *spec.Operation) {
+    if swagger == nil || swagger.Paths == nil {
+        return "", "", nil
+    }
     for path, pathItem := range swagger.Paths.Paths {
         operations := map[string]*spec.Operation{
-            "GET":    pathItem.Get,
-            "POST":   pathItem.Post,
-            "PUT":    pathItem.Put,
-            "DELETE": pathItem.Delete,
-            "PATCH":  pathItem.Patch,
+            "GET":     pathItem.Get,
+            "POST":    pathItem.Post,
+            "PUT":     pathItem.Put,
+            "DELETE":  pathItem.Delete,
+            "PATCH":   pathItem.Patch,
+            "HEAD":    pathItem.Head,
+            "OPTIONS": pathItem.Options,
         }
 
         for method, op := range operations {
-            if op == nil {
-                continue
-            }
-            
-            // Check if operation should be excluded
-            if filter != nil && filter.ShouldExcludeOperation(method, path, op) {
+            if !exposesOperation(filter, method, path, op) {
                 continue
             }
             
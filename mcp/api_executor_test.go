@@ -0,0 +1,2132 @@
+package mcp
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/spec"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const executorTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "limit", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "body", "in": "body", "schema": {"type": "object"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/pets/{petId}": {
+      "put": {
+        "operationId": "updatePet",
+        "parameters": [
+          {"name": "petId", "in": "path", "required": true, "type": "string"},
+          {"name": "body", "in": "body", "schema": {"type": "object"}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "delete": {
+        "operationId": "deletePet",
+        "parameters": [{"name": "petId", "in": "path", "required": true, "type": "string"}],
+        "responses": {"204": {"description": "Deleted"}}
+      }
+    }
+  }
+}`
+
+// connectTestClient connects an in-memory MCP client to server.
+func connectTestClient(t *testing.T, server *Server) *sdk.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := sdk.NewInMemoryTransports()
+	serverSession, err := server.GetMCPServer().GetServer().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := sdk.NewClient(&sdk.Implementation{Name: "test-client", Version: "1.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// callTool invokes a tool on server through an in-memory MCP client.
+func callTool(t *testing.T, server *Server, name string, args map[string]any) *sdk.CallToolResult {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := connectTestClient(t, server).CallTool(ctx, &sdk.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("tools/call %s failed: %v", name, err)
+	}
+	return result
+}
+
+// resultText returns the text of the first content block of a tool result.
+func resultText(t *testing.T, result *sdk.CallToolResult) string {
+	t.Helper()
+
+	if len(result.Content) == 0 {
+		t.Fatal("tool result has no content")
+	}
+	text, ok := result.Content[0].(*sdk.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", result.Content[0])
+	}
+	return text.Text
+}
+
+// newTestServer creates a Server from config, defaulting to the executor test spec.
+func newTestServer(t *testing.T, config *Config) *Server {
+	t.Helper()
+
+	if config.SwaggerSpec == nil && len(config.SwaggerData) == 0 {
+		config.WithSwaggerData([]byte(executorTestSwagger))
+	}
+	server, err := New(config)
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	return server
+}
+
+func TestDryRun_DoesNotCallAPI(t *testing.T) {
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "secret-key").
+		WithDryRun(true))
+
+	result := callTool(t, server, "updatepet", map[string]any{
+		"petId": "42",
+		"body":  map[string]any{"name": "Rex"},
+	})
+	if result.IsError {
+		t.Fatalf("dry run returned error: %s", resultText(t, result))
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Fatalf("expected no upstream requests in dry-run mode, got %d", n)
+	}
+
+	var preview RequestPreview
+	if err := json.Unmarshal([]byte(resultText(t, result)), &preview); err != nil {
+		t.Fatalf("preview is not valid JSON: %v", err)
+	}
+	if !preview.DryRun || preview.Method != "PUT" {
+		t.Errorf("unexpected preview header fields: %+v", preview)
+	}
+	if want := backend.URL + "/pets/42"; preview.URL != want {
+		t.Errorf("preview URL = %s, want %s", preview.URL, want)
+	}
+	body, ok := preview.Body.(map[string]interface{})
+	if !ok || body["name"] != "Rex" {
+		t.Errorf("preview body = %#v, want {name: Rex}", preview.Body)
+	}
+	if got := preview.Headers["Authorization"]; got != "[REDACTED]" {
+		t.Errorf("Authorization header should be redacted, got %q", got)
+	}
+	if got := preview.Headers["X-Api-Key"]; got != "[REDACTED]" {
+		t.Errorf("X-API-Key header should be redacted, got %q", got)
+	}
+}
+
+func TestDryRun_DeleteResolvesPath(t *testing.T) {
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer backend.Close()
+
+	executor := NewAPIExecutor(backend.URL, "")
+	executor.DryRun = true
+
+	content, status, err := executor.BuildAndExecuteRequest(context.Background(), "DELETE", "/pets/{petId}", map[string]interface{}{"petId": 7})
+	if err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if status != 0 || atomic.LoadInt32(&hits) != 0 {
+		t.Fatalf("dry run must not send a request (status=%d, hits=%d)", status, hits)
+	}
+
+	var preview RequestPreview
+	if err := json.Unmarshal([]byte(content), &preview); err != nil {
+		t.Fatalf("preview is not valid JSON: %v", err)
+	}
+	if want := backend.URL + "/pets/7"; preview.URL != want || preview.Method != "DELETE" {
+		t.Errorf("preview = %s %s, want DELETE %s", preview.Method, preview.URL, want)
+	}
+	if preview.Body != nil {
+		t.Errorf("DELETE preview should have no body, got %#v", preview.Body)
+	}
+}
+
+func TestRawResponsePassthrough_ByteForByte(t *testing.T) {
+	// Compact JSON with a float and key order that re-indenting would alter
+	const upstream = `{"z":1.50,"a":[1,2,3],"id":12345678901234567890}`
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(upstream))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithRawResponsePassthrough(true))
+
+	result := callTool(t, server, "listpets", nil)
+	if got := resultText(t, result); got != upstream {
+		t.Errorf("raw passthrough altered the body:\n got: %s\nwant: %s", got, upstream)
+	}
+
+	// Without passthrough the body is reformatted
+	executor := NewAPIExecutor(backend.URL, "")
+	executor.ResponseFormat = ResponseFormatPretty
+	content, _, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if content == upstream {
+		t.Error("expected the pretty executor to reformat JSON responses")
+	}
+}
+
+func TestPerCallAuth_UsesAndStripsAPIKey(t *testing.T) {
+	type seen struct{ auth, apiKey, query string }
+	requests := make(chan seen, 2)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- seen{r.Header.Get("Authorization"), r.Header.Get("X-API-Key"), r.URL.RawQuery}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "server-key").
+		WithPerCallAuth(true))
+
+	callTool(t, server, "listpets", map[string]any{"limit": 2, "_apiKey": "user-key"})
+	got := <-requests
+	if got.auth != "Bearer user-key" || got.apiKey != "user-key" {
+		t.Errorf("expected the per-call key, got Authorization %q, X-API-Key %q", got.auth, got.apiKey)
+	}
+	if got.query != "limit=2" {
+		t.Errorf("expected _apiKey to be stripped from the query, got %q", got.query)
+	}
+
+	callTool(t, server, "listpets", nil)
+	if got := <-requests; got.auth != "Bearer server-key" {
+		t.Errorf("expected the server key without _apiKey, got %q", got.auth)
+	}
+}
+
+func TestResponseValidation_MissingRequiredField(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "Rex"}`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets/{petId}": {
+      "get": {
+        "operationId": "getPet",
+        "parameters": [{"name": "petId", "in": "path", "required": true, "type": "string"}],
+        "responses": {"200": {"description": "A pet", "schema": {
+          "type": "object",
+          "required": ["id", "name"],
+          "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}
+        }}}
+      }
+    }
+  }
+}`
+	args := map[string]any{"petId": "1"}
+
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if got := resultText(t, callTool(t, server, "getpet", args)); strings.Contains(got, "warning") {
+		t.Errorf("validation is off by default, got %q", got)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithResponseValidation(true))
+	result := callTool(t, server, "getpet", args)
+	if result.IsError {
+		t.Fatalf("warnings must not fail the call: %s", resultText(t, result))
+	}
+	got := resultText(t, result)
+	if !strings.HasPrefix(got, `{"name":"Rex"}`) || !strings.Contains(got, "Response validation warnings:\n- response.id is required") {
+		t.Errorf("expected the body followed by a warning, got %q", got)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithStrictResponseValidation(true))
+	_, _, err := server.CallTool(context.Background(), "getpet", args)
+	if !errors.Is(err, ErrInvalidResponse) || !strings.Contains(err.Error(), "response.id is required") {
+		t.Errorf("strict mode: got %v, want ErrInvalidResponse", err)
+	}
+}
+
+func TestMaxConcurrency_LimitsInFlightCalls(t *testing.T) {
+	const limit, calls = 2, 6
+	var current, peak atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithMaxConcurrency(limit))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := server.CallTool(context.Background(), "listpets", nil); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("CallTool failed: %v", err)
+	}
+	if got := peak.Load(); got > limit {
+		t.Errorf("%d calls reached the upstream at once, limit is %d", got, limit)
+	}
+
+	// A queued call gives up when its context is cancelled
+	server.mcp.apiExecutor.inFlight.TryAcquire(limit)
+	defer server.mcp.apiExecutor.inFlight.Release(limit)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := server.CallTool(ctx, "listpets", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("queued call with an expired context = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestIncludeResponseHeaders_Envelope(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("X-Internal-Trace", "abc")
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	if got := resultText(t, callTool(t, server, "listpets", nil)); got != `[{"id":1}]` {
+		t.Errorf("headers are off by default, got %q", got)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithIncludeResponseHeaders([]string{"x-ratelimit-remaining", "ETag", "Link"}))
+	var envelope ResponseEnvelope
+	if err := json.Unmarshal([]byte(resultText(t, callTool(t, server, "listpets", nil))), &envelope); err != nil {
+		t.Fatalf("result is not an envelope: %v", err)
+	}
+	want := map[string]string{"X-Ratelimit-Remaining": "42", "Etag": `"v1"`}
+	if len(envelope.Headers) != len(want) {
+		t.Errorf("headers = %v, want %v", envelope.Headers, want)
+	}
+	for name, value := range want {
+		if envelope.Headers[name] != value {
+			t.Errorf("header %s = %q, want %q", name, envelope.Headers[name], value)
+		}
+	}
+	if items, _ := envelope.Body.([]interface{}); len(items) != 1 {
+		t.Errorf("body = %#v, want the decoded JSON array", envelope.Body)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithIncludeResponseHeaders([]string{"*"}))
+	if got := resultText(t, callTool(t, server, "listpets", nil)); !strings.Contains(got, `"X-Internal-Trace":"abc"`) {
+		t.Errorf("expected every header with *, got %q", got)
+	}
+}
+
+func TestResponseEnvelope_WrapsArraysAndObjects(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"id": 1}]`))
+		case http.MethodPut:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	if got := resultText(t, callTool(t, server, "listpets", nil)); got != `[{"id":1}]` {
+		t.Errorf("results are not enveloped by default, got %q", got)
+	}
+
+	server = newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, "").WithResponseEnvelope(true))
+	envelopeOf := func(content string) map[string]interface{} {
+		t.Helper()
+		var envelope map[string]interface{}
+		if err := json.Unmarshal([]byte(content), &envelope); err != nil {
+			t.Fatalf("result %q is not an envelope: %v", content, err)
+		}
+		return envelope
+	}
+
+	list := envelopeOf(resultText(t, callTool(t, server, "listpets", nil)))
+	update := envelopeOf(resultText(t, callTool(t, server, "updatepet", map[string]any{"petId": "1", "body": map[string]any{}})))
+	for name, envelope := range map[string]map[string]interface{}{"array": list, "object": update} {
+		if len(envelope) != 3 || envelope["status"] != float64(200) || envelope["contentType"] != "application/json" {
+			t.Errorf("%s envelope = %v, want status, contentType and data", name, envelope)
+		}
+	}
+	if items, _ := list["data"].([]interface{}); len(items) != 1 {
+		t.Errorf("array data = %#v", list["data"])
+	}
+	if object, _ := update["data"].(map[string]interface{}); object["id"] != float64(1) {
+		t.Errorf("object data = %#v", update["data"])
+	}
+
+	// Calls from Go get the same content as MCP clients
+	content, _, err := server.CallTool(context.Background(), "listpets", nil)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if envelope := envelopeOf(content); envelope["status"] != float64(200) || envelope["data"] == nil {
+		t.Errorf("CallTool content = %q, want the envelope", content)
+	}
+
+	// An empty body is enveloped with null data
+	deleted := envelopeOf(resultText(t, callTool(t, server, "deletepet", map[string]any{"petId": "1"})))
+	if data, ok := deleted["data"]; deleted["status"] != float64(204) || !ok || data != nil {
+		t.Errorf("empty response envelope = %v, want status 204 and null data", deleted)
+	}
+}
+
+func TestNoContentResponse_ReportsSuccess(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	result := callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+	if got, want := resultText(t, result), `{"status":204,"message":"No Content"}`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNonErrorCodes_ReturnedAsResults(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"pet not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"error":"pet exists"}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithNonErrorCodes([]int{http.StatusNotFound}))
+
+	result := callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+	if result.IsError {
+		t.Errorf("404 was reported as an error: %s", resultText(t, result))
+	}
+	if got := resultText(t, result); got != `{"error":"pet not found"}` {
+		t.Errorf("404 result = %q, want the body", got)
+	}
+
+	result = callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if !result.IsError {
+		t.Errorf("409 is not listed and should be an error: %s", resultText(t, result))
+	}
+}
+
+func TestResponseFormatting_Modes(t *testing.T) {
+	const upstream = "{\"z\": 1.50,\n \"a\": [1, 2]}"
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(upstream))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name   string
+		format ResponseFormat
+		want   string
+	}{
+		{"default is compact", "", `{"z":1.50,"a":[1,2]}`},
+		{"compact", ResponseFormatCompact, `{"z":1.50,"a":[1,2]}`},
+		{"pretty", ResponseFormatPretty, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"z\": 1.50\n}"},
+		{"raw", ResponseFormatRaw, upstream},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, DefaultConfig().
+				WithAPIConfig(backend.URL, "").
+				WithResponseFormatting(tt.format))
+			if got := resultText(t, callTool(t, server, "listpets", nil)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseFormatting_PreservesLargeIntegers(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"conflictingId": 9007199254740993}`))
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": 9007199254740993, "name": "Rex"}`))
+		default:
+			_, _ = w.Write([]byte(`[{"id": 9007199254740993}, {"id": 18446744073709551615}]`))
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseFormatting(ResponseFormatPretty))
+	if got := resultText(t, callTool(t, server, "listpets", nil)); !strings.Contains(got, "9007199254740993") || !strings.Contains(got, "18446744073709551615") {
+		t.Errorf("pretty output lost precision: %s", got)
+	}
+	if got := resultText(t, callTool(t, server, "deletepet", map[string]any{"petId": "1"})); !strings.Contains(got, "9007199254740993") {
+		t.Errorf("error output lost precision: %s", got)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithNDJSONArrays(true).
+		WithWriteSummaries(true))
+	if got := resultText(t, callTool(t, server, "listpets", nil)); got != "{\"id\":9007199254740993}\n{\"id\":18446744073709551615}" {
+		t.Errorf("NDJSON output = %q", got)
+	}
+	if got := resultText(t, callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})); !strings.Contains(got, `"id":9007199254740993`) {
+		t.Errorf("write summary lost precision: %s", got)
+	}
+}
+
+func TestNDJSONArrays_ListResponse(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Rex"}, {"id": 2, "name": "Tom"}]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithNDJSONArrays(true))
+
+	got := resultText(t, callTool(t, server, "listpets", nil))
+	want := "{\"id\":1,\"name\":\"Rex\"}\n{\"id\":2,\"name\":\"Tom\"}"
+	if got != want {
+		t.Errorf("NDJSON output:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestBaseURLFunc_RoutesByRegion(t *testing.T) {
+	newRegion := func(name string, hits *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(hits, 1)
+			_, _ = w.Write([]byte(`{"region":"` + name + `"}`))
+		}))
+	}
+	var euHits, usHits, defaultHits int32
+	eu := newRegion("eu", &euHits)
+	defer eu.Close()
+	us := newRegion("us", &usHits)
+	defer us.Close()
+	fallback := newRegion("default", &defaultHits)
+	defer fallback.Close()
+
+	const regionSwagger = `{
+	  "swagger": "2.0",
+	  "info": {"title": "Regional API", "version": "1.0.0"},
+	  "paths": {
+	    "/eu/orders": {"get": {"operationId": "listEuOrders", "responses": {"200": {"description": "OK"}}}},
+	    "/us/orders": {"get": {"operationId": "listUsOrders", "responses": {"200": {"description": "OK"}}}},
+	    "/status": {"get": {"operationId": "getStatus", "responses": {"200": {"description": "OK"}}}}
+	  }
+	}`
+
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(regionSwagger)).
+		WithAPIConfig(fallback.URL, "").
+		WithBaseURLFunc(func(method, path string, op *spec.Operation) string {
+			switch {
+			case strings.HasPrefix(path, "/eu/"):
+				return eu.URL
+			case strings.HasPrefix(path, "/us/"):
+				return us.URL
+			}
+			return ""
+		}))
+
+	for tool, want := range map[string]string{"listeuorders": "eu", "listusorders": "us", "getstatus": "default"} {
+		if got := resultText(t, callTool(t, server, tool, nil)); !strings.Contains(got, `"region":"`+want+`"`) {
+			t.Errorf("%s routed to %s, want region %s", tool, got, want)
+		}
+	}
+	if euHits != 1 || usHits != 1 || defaultHits != 1 {
+		t.Errorf("hits eu=%d us=%d default=%d, want 1 each", euHits, usHits, defaultHits)
+	}
+}
+
+func TestCookies_ConfiguredAndPerCall(t *testing.T) {
+	var session, tenant, query string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err == nil {
+			session = c.Value
+		}
+		if c, err := r.Cookie("tenant"); err == nil {
+			tenant = c.Value
+		}
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Cookie API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {
+        "operationId": "listItems",
+        "parameters": [{"name": "tenant", "in": "cookie", "type": "string"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithCookies(map[string]string{"session": "abc123", "tenant": "default"}).
+		WithCookieParams())
+
+	callTool(t, server, "listitems", nil)
+	if session != "abc123" || tenant != "default" {
+		t.Errorf("expected configured cookies, got session=%q tenant=%q", session, tenant)
+	}
+
+	callTool(t, server, "listitems", map[string]any{"tenant": "acme"})
+	if session != "abc123" || tenant != "acme" {
+		t.Errorf("expected per-call tenant cookie, got session=%q tenant=%q", session, tenant)
+	}
+	if query != "" {
+		t.Errorf("cookie param leaked into query: %q", query)
+	}
+}
+
+func TestUserAgent_DefaultAndOverride(t *testing.T) {
+	var userAgent string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithServerInfo("pets", "v2.3.4", ""))
+	callTool(t, server, "listpets", nil)
+	if userAgent != "mcp-swagger-server/2.3.4" {
+		t.Errorf("expected default User-Agent, got %q", userAgent)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithUserAgent("pet-bot/1.0"))
+	callTool(t, server, "listpets", nil)
+	if userAgent != "pet-bot/1.0" {
+		t.Errorf("expected overridden User-Agent, got %q", userAgent)
+	}
+}
+
+func TestToolAnnotations_PerMethod(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().WithAPIConfig("http://localhost:1", ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := connectTestClient(t, server).ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+
+	type hints struct {
+		readOnly, idempotent bool
+		destructive          *bool
+	}
+	yes, no := true, false
+	want := map[string]hints{
+		"listpets":  {readOnly: true},
+		"createpet": {destructive: &no},
+		"updatepet": {destructive: &yes, idempotent: true},
+		"deletepet": {destructive: &yes, idempotent: true},
+	}
+	for _, tool := range result.Tools {
+		w, ok := want[tool.Name]
+		if !ok {
+			continue
+		}
+		delete(want, tool.Name)
+		a := tool.Annotations
+		if a == nil {
+			t.Errorf("%s: missing annotations", tool.Name)
+			continue
+		}
+		if a.ReadOnlyHint != w.readOnly || a.IdempotentHint != w.idempotent {
+			t.Errorf("%s: readOnly=%v idempotent=%v, want %v/%v", tool.Name, a.ReadOnlyHint, a.IdempotentHint, w.readOnly, w.idempotent)
+		}
+		if (a.DestructiveHint == nil) != (w.destructive == nil) || (a.DestructiveHint != nil && *a.DestructiveHint != *w.destructive) {
+			t.Errorf("%s: destructiveHint=%v, want %v", tool.Name, a.DestructiveHint, w.destructive)
+		}
+	}
+	for name := range want {
+		t.Errorf("tool %s not listed", name)
+	}
+}
+
+func TestParamCoercion_ConvertsStringArgs(t *testing.T) {
+	var received map[string]interface{}
+	var query url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		query = r.URL.Query()
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [
+          {"name": "notify", "in": "query", "type": "boolean"},
+          {"name": "body", "in": "body", "schema": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string"},
+              "age": {"type": "integer"},
+              "weight": {"type": "number"},
+              "vaccinated": {"type": "boolean"},
+              "tags": {"type": "array", "items": {"type": "object", "properties": {"priority": {"type": "integer"}}}}
+            }
+          }}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithParamCoercion(true))
+
+	result := callTool(t, server, "createpet", map[string]any{
+		"notify": "true",
+		"body": map[string]any{
+			"name":       "123",
+			"age":        "5",
+			"weight":     "4.5",
+			"vaccinated": "true",
+			"tags":       []any{map[string]any{"priority": "2"}},
+		},
+	})
+	if result.IsError {
+		t.Fatalf("tools/call failed: %s", resultText(t, result))
+	}
+
+	if received["age"] != float64(5) || received["weight"] != 4.5 || received["vaccinated"] != true {
+		t.Errorf("scalar fields not coerced: %v", received)
+	}
+	if received["name"] != "123" {
+		t.Errorf("string field should stay a string: %v", received["name"])
+	}
+	tags, _ := received["tags"].([]interface{})
+	if len(tags) != 1 || tags[0].(map[string]interface{})["priority"] != float64(2) {
+		t.Errorf("nested field not coerced: %v", received["tags"])
+	}
+	if query.Get("notify") != "true" {
+		t.Errorf("notify query = %q", query.Get("notify"))
+	}
+
+	// Without coercion the strings are rejected before any request is sent
+	received = nil
+	server = newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	_, _, err := server.CallTool(context.Background(), "createpet", map[string]interface{}{
+		"body": map[string]interface{}{"age": "5"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "body.age must be integer, got string") {
+		t.Errorf("CallTool without coercion = %v, want a body.age type error", err)
+	}
+	if received != nil {
+		t.Errorf("request sent despite invalid arguments: %v", received)
+	}
+}
+
+func TestArgumentValidation_PathQualifiedErrors(t *testing.T) {
+	requests := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Customer API", "version": "1.0.0"},
+  "paths": {
+    "/customers": {
+      "post": {
+        "operationId": "createCustomer",
+        "parameters": [
+          {"name": "body", "in": "body", "required": true, "schema": {
+            "type": "object",
+            "required": ["name", "address"],
+            "properties": {
+              "name": {"type": "string"},
+              "age": {"type": "integer"},
+              "address": {
+                "type": "object",
+                "required": ["zip"],
+                "properties": {"street": {"type": "string"}, "zip": {"type": "string"}}
+              }
+            }
+          }}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+
+	tests := []struct {
+		name string
+		body map[string]any
+		want string
+	}{
+		{"missing nested field", map[string]any{"name": "Ada", "address": map[string]any{"street": "Main St"}}, "body.address.zip is required"},
+		{"wrong type", map[string]any{"name": "Ada", "age": "forty", "address": map[string]any{"zip": "12345"}}, "body.age must be integer, got string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := callTool(t, server, "createcustomer", map[string]any{"body": tt.body})
+			if !result.IsError {
+				t.Fatalf("expected an error result, got %s", resultText(t, result))
+			}
+			if got := resultText(t, result); !strings.Contains(got, tt.want) {
+				t.Errorf("error %q does not contain %q", got, tt.want)
+			}
+
+			_, _, err := server.CallTool(context.Background(), "createcustomer", map[string]interface{}{"body": tt.body})
+			var argErr *ArgumentError
+			if !errors.As(err, &argErr) || len(argErr.Problems) != 1 || argErr.Problems[0] != tt.want {
+				t.Errorf("CallTool error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent despite invalid arguments", requests)
+	}
+
+	result := callTool(t, server, "createcustomer", map[string]any{"body": map[string]any{"name": "Ada", "age": 40, "address": map[string]any{"zip": "12345"}}})
+	if result.IsError || requests != 1 {
+		t.Errorf("valid call: IsError=%v, %d requests", result.IsError, requests)
+	}
+}
+
+func TestHeadAndOptions_RegisteredAsTools(t *testing.T) {
+	var methods []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/pets/7":
+			w.Header().Set("ETag", `"v3"`)
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodOptions:
+			w.Header().Set("Allow", "GET, POST, OPTIONS")
+			_, _ = w.Write([]byte(`{"methods":["GET","POST"]}`))
+		}
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "options": {"operationId": "petsCapabilities", "responses": {"200": {"description": "OK"}}}
+    },
+    "/pets/{petId}": {
+      "head": {
+        "operationId": "petExists",
+        "parameters": [{"name": "petId", "in": "path", "required": true, "type": "string"}],
+        "responses": {"200": {"description": "Exists"}, "404": {"description": "Missing"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if names := strings.Join(server.ListTools(), ","); names != "petexists,petscapabilities" {
+		t.Fatalf("tools = %s, want petexists,petscapabilities", names)
+	}
+
+	result := callTool(t, server, "petexists", map[string]any{"petId": "7"})
+	if result.IsError {
+		t.Fatalf("HEAD call failed: %s", resultText(t, result))
+	}
+	var head struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(resultText(t, result)), &head); err != nil {
+		t.Fatalf("HEAD content is not status and headers: %v", err)
+	}
+	if head.Status != http.StatusOK || head.Headers["Etag"] != `"v3"` {
+		t.Errorf("HEAD content = %+v", head)
+	}
+
+	if result := callTool(t, server, "petexists", map[string]any{"petId": "8"}); !result.IsError {
+		t.Errorf("HEAD 404 should be an error, got %s", resultText(t, result))
+	}
+
+	result = callTool(t, server, "petscapabilities", nil)
+	if result.IsError || resultText(t, result) != `{"methods":["GET","POST"]}` {
+		t.Errorf("OPTIONS result = %v", result.Content)
+	}
+	if want := "HEAD /pets/7,HEAD /pets/8,OPTIONS /pets"; strings.Join(methods, ",") != want {
+		t.Errorf("requests = %v, want %s", methods, want)
+	}
+}
+
+func TestFormatValidation_RejectsMalformedValues(t *testing.T) {
+	requests := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "User API", "version": "1.0.0"},
+  "paths": {
+    "/users": {
+      "post": {
+        "operationId": "createUser",
+        "parameters": [
+          {"name": "requestId", "in": "query", "type": "string", "format": "uuid"},
+          {"name": "body", "in": "body", "schema": {
+            "type": "object",
+            "properties": {"email": {"type": "string", "format": "email"}}
+          }}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	validUUID := "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	invalidEmail := map[string]any{"requestId": validUUID, "body": map[string]any{"email": "not-an-email"}}
+
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, "").WithFormatValidation(true))
+	result := callTool(t, server, "createuser", invalidEmail)
+	if !result.IsError || !strings.Contains(resultText(t, result), "body.email must be a valid email") {
+		t.Errorf("invalid email: %v", result.Content)
+	}
+	result = callTool(t, server, "createuser", map[string]any{"requestId": "42"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "requestId must be a valid uuid") {
+		t.Errorf("invalid uuid: %v", result.Content)
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent despite invalid formats", requests)
+	}
+
+	result = callTool(t, server, "createuser", map[string]any{"requestId": validUUID, "body": map[string]any{"email": "ada@example.com"}})
+	if result.IsError || requests != 1 {
+		t.Errorf("valid call: IsError=%v, %d requests", result.IsError, requests)
+	}
+
+	// Formats are not checked unless enabled
+	lenient := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if result := callTool(t, lenient, "createuser", invalidEmail); result.IsError || requests != 2 {
+		t.Errorf("format validation is on by default: IsError=%v, %d requests", result.IsError, requests)
+	}
+}
+
+func TestStrictParams_RejectsUndeclaredArguments(t *testing.T) {
+	var query url.Values
+	var body []byte
+	requests := 0
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query = r.URL.Query()
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	swagger := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "limit", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK"}}
+      },
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "body", "in": "body", "schema": {
+          "type": "object",
+          "properties": {
+            "name": {"type": "string"},
+            "labels": {"type": "object", "additionalProperties": {"type": "string"}}
+          }
+        }}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+	// Lenient (the default): the made-up argument is sent as a query parameter
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(swagger)).
+		WithAPIConfig(backend.URL, ""))
+	callTool(t, server, "listpets", map[string]any{"limit": 5, "color": "red"})
+	if query.Get("color") != "red" {
+		t.Errorf("lenient query = %v, want color forwarded", query)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(swagger)).
+		WithAPIConfig(backend.URL, "").
+		WithStrictParams(true))
+	requests = 0
+
+	result := callTool(t, server, "listpets", map[string]any{"limit": 5, "color": "red"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "color is not declared in the spec") {
+		t.Errorf("strict result = %s, want color rejected", resultText(t, result))
+	}
+	result = callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex", "age": 3}})
+	if !result.IsError || !strings.Contains(resultText(t, result), "body.age is not declared in the spec") {
+		t.Errorf("strict result = %s, want body.age rejected", resultText(t, result))
+	}
+	if requests != 0 {
+		t.Errorf("%d requests sent for rejected calls", requests)
+	}
+
+	// Declared arguments, and free-form maps, still pass
+	result = callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex", "labels": map[string]any{"size": "small"}}})
+	if result.IsError || !strings.Contains(string(body), `"size":"small"`) {
+		t.Errorf("strict call with declared fields = %s, body %s", resultText(t, result), body)
+	}
+}
+
+func TestStrictParams_WithRequestTransform(t *testing.T) {
+	var query url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithStrictParams(true).
+		WithRequestTransform(func(toolName, method, path string, args map[string]interface{}) error {
+			args["tenant"] = "acme" // not declared by the spec
+			return nil
+		}, "tenant"))
+
+	// What the transform adds passes the strict check
+	result := callTool(t, server, "listpets", map[string]any{})
+	if result.IsError || query.Get("tenant") != "acme" {
+		t.Fatalf("transformed call: %s, query %q", resultText(t, result), query.Encode())
+	}
+	if _, _, err := server.CallTool(context.Background(), "listpets", nil); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	// What the client sends is still held to the spec
+	result = callTool(t, server, "listpets", map[string]any{"color": "red"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "color is not declared in the spec") {
+		t.Errorf("strict result = %s, want color rejected", resultText(t, result))
+	}
+	if _, _, err := server.CallTool(context.Background(), "listpets", map[string]interface{}{"color": "red"}); err == nil || !strings.Contains(err.Error(), "color is not declared in the spec") {
+		t.Errorf("CallTool error = %v, want color rejected", err)
+	}
+}
+func TestArrayRequestBody_BulkCreate(t *testing.T) {
+	var received interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets/bulk": {
+      "post": {
+        "operationId": "bulkCreatePets",
+        "parameters": [
+          {"name": "pets", "in": "body", "required": true, "schema": {
+            "type": "array",
+            "items": {"$ref": "#/definitions/Pet"}
+          }}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/pets/import": {
+      "post": {
+        "operationId": "importPets",
+        "parameters": [
+          {"name": "pets", "in": "body", "schema": {"items": {"$ref": "#/definitions/Pet"}}}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  },
+  "definitions": {
+    "Pet": {
+      "type": "object",
+      "required": ["name"],
+      "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+
+	body, _ := server.mcp.toolInputSchema("bulkcreatepets")["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	items, _ := body["items"].(map[string]interface{})
+	properties, _ := items["properties"].(map[string]interface{})
+	if body["type"] != "array" || items["type"] != "object" || properties["name"] == nil {
+		t.Fatalf("expected an array of Pet objects, got %v", body)
+	}
+
+	// An array schema that leaves out "type" is still an array
+	untyped, _ := server.mcp.toolInputSchema("importpets")["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	if untyped["type"] != "array" {
+		t.Errorf("expected an untyped schema with items to be an array, got %v", untyped)
+	}
+
+	pets := []any{map[string]any{"name": "Rex", "age": 3}, map[string]any{"name": "Tom"}}
+	result := callTool(t, server, "bulkcreatepets", map[string]any{"body": pets})
+	if result.IsError {
+		t.Fatalf("tools/call failed: %s", resultText(t, result))
+	}
+	sent, ok := received.([]interface{})
+	if !ok || len(sent) != 2 || sent[1].(map[string]interface{})["name"] != "Tom" {
+		t.Errorf("expected the array as the top-level body, got %#v", received)
+	}
+
+	result = callTool(t, server, "bulkcreatepets", map[string]any{"body": []any{map[string]any{"age": 1}}})
+	if !result.IsError || !strings.Contains(resultText(t, result), "body[0].name is required") {
+		t.Errorf("expected a missing item field to be reported, got %s", resultText(t, result))
+	}
+}
+
+func TestApplyDefaults_FillsOmittedBodyFields(t *testing.T) {
+	var received map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "body", "in": "body", "schema": {
+          "type": "object",
+          "properties": {
+            "name": {"type": "string"},
+            "status": {"type": "string", "enum": ["available", "sold"], "default": "available"},
+            "vaccinated": {"type": "boolean", "default": false},
+            "owner": {"type": "object", "properties": {
+              "name": {"type": "string"},
+              "notify": {"type": "boolean", "default": true}
+            }}
+          }
+        }}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithApplyDefaults(true))
+
+	// Called through the Go API: MCP clients' arguments are already
+	// completed from the input schema's defaults by the SDK
+	ctx := context.Background()
+	if _, _, err := server.CallTool(ctx, "createpet", map[string]interface{}{
+		"body": map[string]interface{}{"name": "Rex", "vaccinated": true, "owner": map[string]interface{}{"name": "Ann"}},
+	}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if received["status"] != "available" {
+		t.Errorf("expected default status, got %v", received["status"])
+	}
+	if received["vaccinated"] != true {
+		t.Errorf("default overrode the caller's value: vaccinated=%v", received["vaccinated"])
+	}
+	owner, _ := received["owner"].(map[string]interface{})
+	if owner["notify"] != true || owner["name"] != "Ann" {
+		t.Errorf("expected nested default merged into owner, got %v", owner)
+	}
+
+	// Disabled by default
+	server = newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if _, _, err := server.CallTool(ctx, "createpet", map[string]interface{}{"body": map[string]interface{}{"name": "Rex"}}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if _, ok := received["status"]; ok {
+		t.Errorf("defaults applied without WithApplyDefaults: %v", received)
+	}
+}
+
+func TestContentType_ConfiguredAndFromSpec(t *testing.T) {
+	var contentType, accept string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		accept = r.Header.Get("Accept")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "body", "in": "body", "schema": {"type": "object"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    },
+    "/owners": {
+      "post": {
+        "operationId": "createOwner",
+        "consumes": ["application/merge-patch+json"],
+        "produces": ["application/hal+json"],
+        "parameters": [{"name": "body", "in": "body", "schema": {"type": "object"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	ctx := context.Background()
+	body := map[string]interface{}{"body": map[string]interface{}{"name": "Rex"}}
+
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if _, _, err := server.CallTool(ctx, "createpet", copyArgs(body)); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if contentType != "application/json" || accept != "application/json" {
+		t.Errorf("expected JSON defaults, got Content-Type %q, Accept %q", contentType, accept)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithContentType("application/vnd.api+json").
+		WithAccept("application/vnd.api+json"))
+	if _, _, err := server.CallTool(ctx, "createpet", copyArgs(body)); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if contentType != "application/vnd.api+json" || accept != "application/vnd.api+json" {
+		t.Errorf("expected vendor media types, got Content-Type %q, Accept %q", contentType, accept)
+	}
+
+	// The operation's consumes/produces take precedence
+	if _, _, err := server.CallTool(ctx, "createowner", copyArgs(body)); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if contentType != "application/merge-patch+json" || accept != "application/hal+json" {
+		t.Errorf("expected the spec's media types, got Content-Type %q, Accept %q", contentType, accept)
+	}
+}
+
+func TestAccept_NonJSONProduces(t *testing.T) {
+	var accept string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write([]byte("id,name\n1,Rex\n"))
+	}))
+	defer backend.Close()
+
+	specs := map[string]string{
+		"swagger 2.0": `{
+  "swagger": "2.0",
+  "info": {"title": "Report API", "version": "1.0.0"},
+  "produces": ["application/json"],
+  "paths": {
+    "/report": {
+      "get": {
+        "operationId": "getReport",
+        "produces": ["text/csv"],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`,
+		"openapi 3.0": `{
+  "openapi": "3.0.3",
+  "info": {"title": "Report API", "version": "1.0.0"},
+  "paths": {
+    "/report": {
+      "get": {
+        "operationId": "getReport",
+        "responses": {
+          "200": {"description": "OK", "content": {"text/csv": {"schema": {"type": "string"}}}},
+          "default": {"description": "Error", "content": {"text/plain": {"schema": {"type": "string"}}}}
+        }
+      }
+    },
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "responses": {"200": {"description": "OK", "content": {"application/json": {"schema": {"type": "array", "items": {}}}}}}
+      }
+    }
+  }
+}`,
+	}
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+
+			content, _, err := server.CallTool(context.Background(), "getreport", nil)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if !strings.HasPrefix(accept, "text/csv") || strings.Contains(accept, "json") {
+				t.Errorf("Accept = %q, want text/csv", accept)
+			}
+			if content != "id,name\n1,Rex\n" {
+				t.Errorf("CSV content = %q", content)
+			}
+
+			if _, _, err := server.CallTool(context.Background(), "listpets", nil); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if accept != "application/json" {
+				t.Errorf("Accept = %q, want application/json", accept)
+			}
+		})
+	}
+}
+
+func TestAccept_OmittedAndFixed(t *testing.T) {
+	var accept []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Values("Accept")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}
+    },
+    "/owners": {
+      "get": {
+        "operationId": "listOwners",
+        "produces": ["application/hal+json"],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	tests := []struct {
+		name   string
+		config *Config
+		want   map[string]string // Accept sent per tool; absent for no header
+	}{
+		{"without accept header", DefaultConfig().WithoutAcceptHeader(), nil},
+		{"empty accept", DefaultConfig().WithAccept(""), nil},
+		{"fixed", DefaultConfig().WithFixedAccept("application/vnd.pets.v2+json"),
+			map[string]string{"listpets": "application/vnd.pets.v2+json", "listowners": "application/vnd.pets.v2+json"}},
+		{"fixed empty", DefaultConfig().WithFixedAccept(""), nil},
+		// A later value negotiates again instead of staying omitted
+		{"empty then set", DefaultConfig().WithAccept("").WithAccept("application/xml"),
+			map[string]string{"listpets": "application/xml", "listowners": "application/hal+json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, tt.config.WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+			// Whether or not the operation declares produces
+			for _, tool := range []string{"listpets", "listowners"} {
+				accept = []string{"unset"}
+				if _, _, err := server.CallTool(context.Background(), tool, nil); err != nil {
+					t.Fatalf("CallTool %s failed: %v", tool, err)
+				}
+				want, sent := tt.want[tool]
+				if sent && (len(accept) != 1 || accept[0] != want) || !sent && accept != nil {
+					t.Errorf("%s sent Accept %q, want %q", tool, accept, want)
+				}
+			}
+		})
+	}
+}
+
+func TestXMLRequestBody(t *testing.T) {
+	type pet struct {
+		XMLName xml.Name `xml:"Pet"`
+		ID      int      `xml:"id,attr"`
+		Name    string   `xml:"name"`
+		Weight  float64  `xml:"weight"`
+		Tags    []string `xml:"tags>tag"`
+	}
+	var contentType string
+	var received pet
+	var parseErr error
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		received = pet{}
+		parseErr = xml.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "consumes": ["application/xml"],
+        "parameters": [{"name": "body", "in": "body", "schema": {
+          "type": "object",
+          "xml": {"name": "Pet"},
+          "properties": {
+            "id": {"type": "integer", "xml": {"attribute": true}},
+            "name": {"type": "string"},
+            "weight": {"type": "number"},
+            "tags": {"type": "array", "xml": {"wrapped": true}, "items": {"type": "string", "xml": {"name": "tag"}}}
+          }
+        }}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	_, status, err := server.CallTool(context.Background(), "createpet", map[string]interface{}{
+		"body": map[string]interface{}{"id": float64(7), "name": "Rex & Co", "weight": 1000000.5, "tags": []interface{}{"good", "dog"}},
+	})
+	if err != nil || status != http.StatusCreated {
+		t.Fatalf("CallTool = %d, %v", status, err)
+	}
+	if contentType != "application/xml" {
+		t.Errorf("Content-Type = %q, want application/xml", contentType)
+	}
+	if parseErr != nil {
+		t.Fatalf("server failed to parse XML body: %v", parseErr)
+	}
+	want := pet{XMLName: xml.Name{Local: "Pet"}, ID: 7, Name: "Rex & Co", Weight: 1000000.5, Tags: []string{"good", "dog"}}
+	if fmt.Sprint(received) != fmt.Sprint(want) {
+		t.Errorf("received %+v, want %+v", received, want)
+	}
+}
+
+func TestQueryParams_SentWithBody(t *testing.T) {
+	var query url.Values
+	var received map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		received = nil
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Search API", "version": "1.0.0"},
+  "paths": {
+    "/search": {
+      "post": {
+        "operationId": "search",
+        "parameters": [
+          {"name": "lang", "in": "query", "type": "string"},
+          {"name": "body", "in": "body", "schema": {"type": "object", "properties": {"q": {"type": "string"}}}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+	if _, _, err := server.CallTool(context.Background(), "search", map[string]interface{}{
+		"lang": "en",
+		"body": map[string]interface{}{"q": "cats & dogs"},
+	}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if query.Get("lang") != "en" {
+		t.Errorf("expected lang=en in the query, got %q", query.Encode())
+	}
+	if received["q"] != "cats & dogs" {
+		t.Errorf("expected the JSON body, got %v", received)
+	}
+	if _, ok := received["lang"]; ok {
+		t.Errorf("query parameter leaked into the body: %v", received)
+	}
+}
+
+func TestQueryParams_ObjectStyles(t *testing.T) {
+	var rawQuery string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "openapi": "3.0.3",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "filter", "in": "query", "style": "deepObject", "explode": true, "schema": {"type": "object"}},
+          {"name": "page", "in": "query", "schema": {"type": "object"}},
+          {"name": "point", "in": "query", "explode": false, "schema": {"type": "object"}},
+          {"name": "ids", "in": "query", "schema": {"type": "array", "items": {"type": "integer"}}},
+          {"name": "tags", "in": "query", "explode": false, "schema": {"type": "array", "items": {"type": "string"}}}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, ""))
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"deepObject", map[string]interface{}{"filter": map[string]interface{}{"name": "Rex", "age": 3}}, "filter%5Bage%5D=3&filter%5Bname%5D=Rex"},
+		{"form explode", map[string]interface{}{"page": map[string]interface{}{"limit": 10, "offset": 20}}, "limit=10&offset=20"},
+		{"form", map[string]interface{}{"point": map[string]interface{}{"x": 1, "y": 2}}, "point=x%2C1%2Cy%2C2"},
+		{"array explode", map[string]interface{}{"ids": []interface{}{1, 2}}, "ids=1&ids=2"},
+		{"array form", map[string]interface{}{"tags": []interface{}{"a", "b"}}, "tags=a%2Cb"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := server.CallTool(context.Background(), "listpets", tt.args); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if rawQuery != tt.want {
+				t.Errorf("query = %q, want %q", rawQuery, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryParams_BooleanFormats(t *testing.T) {
+	var rawQuery string
+	var body map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		body = nil
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [
+          {"name": "notify", "in": "query", "type": "boolean"},
+          {"name": "flags", "in": "query", "type": "array", "items": {"type": "boolean"}},
+          {"name": "legacy", "in": "query", "type": "boolean", "x-boolean-format": "1-0"},
+          {"name": "body", "in": "body", "schema": {"type": "object"}}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	args := func() map[string]interface{} {
+		return map[string]interface{}{
+			"notify": true,
+			"flags":  []interface{}{true, false},
+			"legacy": false,
+			"body":   map[string]interface{}{"indoor": true},
+		}
+	}
+
+	tests := []struct {
+		format BooleanQueryFormat
+		want   string
+	}{
+		{"", "flags=true%2Cfalse&legacy=0&notify=true"},
+		{BooleanTrueFalse, "flags=true%2Cfalse&legacy=0&notify=true"},
+		{BooleanOneZero, "flags=1%2C0&legacy=0&notify=1"},
+		{BooleanYesNo, "flags=yes%2Cno&legacy=0&notify=yes"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			server := newTestServer(t, DefaultConfig().
+				WithSwaggerData([]byte(spec)).
+				WithAPIConfig(backend.URL, "").
+				WithBooleanQueryFormat(tt.format))
+			if _, _, err := server.CallTool(context.Background(), "createpet", args()); err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if rawQuery != tt.want {
+				t.Errorf("query = %q, want %q", rawQuery, tt.want)
+			}
+			// JSON bodies keep their booleans
+			if body["indoor"] != true {
+				t.Errorf("body = %v, want indoor true", body)
+			}
+		})
+	}
+}
+
+func TestHeaderParams_ExposedAndSent(t *testing.T) {
+	var header http.Header
+	var query url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "securityDefinitions": {"token": {"type": "apiKey", "in": "header", "name": "X-Auth-Token"}},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [
+          {"name": "X-Idempotency-Key", "in": "header", "type": "string", "required": true},
+          {"name": "Authorization", "in": "header", "type": "string"},
+          {"name": "X-Auth-Token", "in": "header", "type": "string"},
+          {"name": "body", "in": "body", "schema": {"type": "object"}}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig(backend.URL, "secret"))
+
+	op := server.config.SwaggerSpec.Paths.Paths["/pets"].Post
+	properties := server.mcp.buildParametersSchema(op.Parameters)["properties"].(map[string]interface{})
+	if _, ok := properties["X-Idempotency-Key"]; !ok {
+		t.Errorf("expected the header parameter in the schema, got %v", properties)
+	}
+	if _, ok := properties["Authorization"]; ok {
+		t.Errorf("Authorization must not be exposed, got %v", properties)
+	}
+	if _, ok := properties["X-Auth-Token"]; ok {
+		t.Errorf("the security scheme header must not be exposed, got %v", properties)
+	}
+
+	if _, _, err := server.CallTool(context.Background(), "createpet", map[string]interface{}{
+		"X-Idempotency-Key": "abc-123",
+		"Authorization":     "Bearer injected",
+		"body":              map[string]interface{}{"name": "Rex"},
+	}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if got := header.Get("X-Idempotency-Key"); got != "abc-123" {
+		t.Errorf("expected the header to be sent, got %q", got)
+	}
+	if got := header.Get("Authorization"); got != "Bearer secret" {
+		t.Errorf("expected the configured credentials, got Authorization %q", got)
+	}
+	if query.Has("X-Idempotency-Key") {
+		t.Errorf("header parameter leaked into the query: %v", query)
+	}
+}
+
+func TestMaxRequestBytes_RejectsLargeBodies(t *testing.T) {
+	var hits int32
+	var contentLength int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		contentLength = r.ContentLength
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithMaxRequestBytes(64))
+
+	ctx := context.Background()
+	_, _, err := server.CallTool(ctx, "createpet", map[string]interface{}{
+		"body": map[string]interface{}{"name": strings.Repeat("x", 100)},
+	})
+	if !errors.Is(err, ErrRequestTooLarge) {
+		t.Fatalf("expected ErrRequestTooLarge, got %v", err)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Fatal("oversized request reached the API")
+	}
+
+	if _, _, err := server.CallTool(ctx, "createpet", map[string]interface{}{
+		"body": map[string]interface{}{"name": "Rex"},
+	}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if contentLength != int64(len(`{"name":"Rex"}`)) {
+		t.Errorf("expected an explicit Content-Length, got %d", contentLength)
+	}
+}
+
+func TestCompressedResponses_AreDecoded(t *testing.T) {
+	payload := []byte(`{"id":1,"name":"Rex"}`)
+	encodings := map[string]func(w io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+	}
+
+	for encoding, newWriter := range encodings {
+		t.Run(encoding, func(t *testing.T) {
+			backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", encoding)
+				zw := newWriter(w)
+				_, _ = zw.Write(payload)
+				_ = zw.Close()
+			}))
+			defer backend.Close()
+
+			// Compress regardless of the request, as some APIs do, and stop
+			// the transport from negotiating (and decoding) gzip itself
+			executor := NewAPIExecutor(backend.URL, "")
+			executor.HTTPClient = &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+			content, status, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets/1", map[string]interface{}{})
+			if err != nil || status != http.StatusOK {
+				t.Fatalf("request failed: status %d, err %v", status, err)
+			}
+			if content != `{"id":1,"name":"Rex"}` {
+				t.Errorf("expected decoded JSON, got %q", content)
+			}
+		})
+	}
+}
+
+func TestResponseTransform_RedactsFields(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Rex", "internal_id": "db-42"}]`))
+	}))
+	defer backend.Close()
+
+	var calledFor string
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseTransform(func(toolName string, status int, body []byte) ([]byte, error) {
+			calledFor = toolName
+			var pets []map[string]interface{}
+			if err := json.Unmarshal(body, &pets); err != nil {
+				return nil, err
+			}
+			for _, pet := range pets {
+				delete(pet, "internal_id")
+			}
+			return json.Marshal(pets)
+		}))
+
+	content := resultText(t, callTool(t, server, "listpets", nil))
+	if strings.Contains(content, "internal_id") || !strings.Contains(content, "Rex") {
+		t.Errorf("expected internal_id to be redacted, got %s", content)
+	}
+	if calledFor != "listpets" {
+		t.Errorf("expected the transform to receive the tool name, got %q", calledFor)
+	}
+
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseTransform(func(string, int, []byte) ([]byte, error) {
+			return nil, errors.New("redaction failed")
+		}))
+	result := callTool(t, server, "listpets", nil)
+	if !result.IsError || !strings.Contains(resultText(t, result), "redaction failed") {
+		t.Errorf("expected the transform error as a tool error, got %+v", result)
+	}
+}
+
+func TestRequestTransform_InjectsArguments(t *testing.T) {
+	var query url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithRequestTransform(func(toolName, method, path string, args map[string]interface{}) error {
+			if toolName == "listpets" {
+				args["tenant_id"] = "acme"
+				if _, ok := args["limit"]; !ok {
+					args["limit"] = 25
+				}
+			}
+			return nil
+		}))
+
+	callTool(t, server, "listpets", map[string]any{"tenant_id": "other"})
+	if query.Get("tenant_id") != "acme" || query.Get("limit") != "25" {
+		t.Errorf("expected injected tenant_id and limit, got %q", query.Encode())
+	}
+
+	callTool(t, server, "listpets", map[string]any{"limit": 5})
+	if query.Get("limit") != "5" {
+		t.Errorf("expected the caller's limit to be kept, got %q", query.Encode())
+	}
+}
+
+func TestRequestTransform_SuppliesRequiredArgument(t *testing.T) {
+	var query url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	swagger := `{
+  "swagger": "2.0",
+  "info": {"title": "t", "version": "1"},
+  "paths": {"/pets": {"get": {"operationId": "listPets", "parameters": [
+    {"name": "tenant_id", "in": "query", "type": "string", "required": true},
+    {"name": "species", "in": "query", "type": "string", "required": true}
+  ], "responses": {"200": {"description": "OK"}}}}}
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(swagger)).
+		WithAPIConfig(backend.URL, "").
+		WithRequestTransform(func(toolName, method, path string, args map[string]interface{}) error {
+			args["tenant_id"] = "acme"
+			return nil
+		}, "tenant_id"))
+
+	result := callTool(t, server, "listpets", map[string]any{"species": "cat"})
+	if result.IsError {
+		t.Fatalf("call without the supplied argument failed: %s", resultText(t, result))
+	}
+	if query.Get("tenant_id") != "acme" {
+		t.Errorf("expected the transform's tenant_id, got %q", query.Encode())
+	}
+
+	// Arguments the transform does not supply are still required
+	result = callTool(t, server, "listpets", map[string]any{})
+	if !result.IsError || !strings.Contains(resultText(t, result), "species is required") {
+		t.Errorf("expected species to be required, got %s", resultText(t, result))
+	}
+}
+
+func TestHeaders_SentWithEveryCall(t *testing.T) {
+	var header http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "secret").
+		WithHeaders(map[string]string{"X-Tenant": "acme", "Authorization": "ignored"}))
+	callTool(t, server, "listpets", nil)
+
+	if header.Get("X-Tenant") != "acme" {
+		t.Errorf("expected the configured header, got %v", header)
+	}
+	if header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("credentials must take precedence, got %q", header.Get("Authorization"))
+	}
+}
+
+func TestDefaultQueryParams_SentUnlessOverridden(t *testing.T) {
+	var query url.Values
+	var body []byte
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		body, _ = io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithDefaultQueryParams(map[string]string{"api-version": "2023-01-01"}))
+
+	callTool(t, server, "listpets", map[string]any{"limit": 5})
+	if query.Get("api-version") != "2023-01-01" || query.Get("limit") != "5" {
+		t.Errorf("GET query = %v, want the default alongside limit", query)
+	}
+
+	callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if query.Get("api-version") != "2023-01-01" {
+		t.Errorf("POST query = %v, want the default", query)
+	}
+	if strings.Contains(string(body), "api-version") {
+		t.Errorf("the default must not reach the body: %s", body)
+	}
+
+	callTool(t, server, "listpets", map[string]any{"api-version": "2024-05-01"})
+	if got := query["api-version"]; len(got) != 1 || got[0] != "2024-05-01" {
+		t.Errorf("api-version = %v, want the call's value only", got)
+	}
+}
+
+func TestBaseURL_TrailingSlashAndQuery(t *testing.T) {
+	var requested string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.RequestURI()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	executor := NewAPIExecutor(backend.URL+"/api/?v=2", "")
+	if _, _, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets", map[string]interface{}{"limit": 5}); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if requested != "/api/pets?v=2&limit=5" {
+		t.Errorf("requested %q, want /api/pets?v=2&limit=5", requested)
+	}
+}
+
+func TestPathPrefix_StripAndAdd(t *testing.T) {
+	var requested string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	// The spec paths repeat the /v2 the base URL already ends in
+	duplicated := `{
+  "swagger": "2.0",
+  "info": {"title": "Test API", "version": "1.0.0"},
+  "paths": {
+    "/v2/pets/{petId}": {"get": {"operationId": "getPet", "parameters": [{"name": "petId", "in": "path", "required": true, "type": "string"}], "responses": {"200": {"description": "OK"}}}},
+    "/health": {"get": {"operationId": "health", "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(duplicated)).
+		WithAPIConfig(backend.URL+"/v2", "").
+		WithStripPathPrefix("/v2"))
+	callTool(t, server, "getpet", map[string]any{"petId": "7"})
+	if requested != "/v2/pets/7" {
+		t.Errorf("stripped request path = %q, want /v2/pets/7", requested)
+	}
+	callTool(t, server, "health", nil)
+	if requested != "/v2/health" {
+		t.Errorf("path without the prefix = %q, want /v2/health", requested)
+	}
+
+	// The spec paths omit the /api the API expects
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithAddPathPrefix("api/"))
+	callTool(t, server, "deletepet", map[string]any{"petId": "7"})
+	if requested != "/api/pets/7" {
+		t.Errorf("prefixed request path = %q, want /api/pets/7", requested)
+	}
+}
+
+func TestPathParams_RawSlashes(t *testing.T) {
+	var requested string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.EscapedPath()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Files API", "version": "1.0.0"},
+  "paths": {
+    "/files/{path}": {
+      "get": {
+        "operationId": "getFile",
+        "parameters": [{"name": "path", "in": "path", "type": "string", "format": "path", "required": true}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/buckets/{bucket}/objects/{key}": {
+      "get": {
+        "operationId": "getObject",
+        "parameters": [
+          {"name": "bucket", "in": "path", "type": "string", "required": true},
+          {"name": "key", "in": "path", "type": "string", "required": true}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig(backend.URL, "").
+		WithRawPathParams("key"))
+	ctx := context.Background()
+
+	tests := []struct {
+		tool string
+		args map[string]interface{}
+		want string
+	}{
+		// format: path in the spec
+		{"getfile", map[string]interface{}{"path": "a/b/c.txt"}, "/files/a/b/c.txt"},
+		{"getfile", map[string]interface{}{"path": "docs/my file?.txt"}, "/files/docs/my%20file%3F.txt"},
+		// allow-listed by name; other path params are fully escaped
+		{"getobject", map[string]interface{}{"bucket": "a/b", "key": "x/y z"}, "/buckets/a%2Fb/objects/x/y%20z"},
+	}
+	for _, tt := range tests {
+		if _, _, err := server.CallTool(ctx, tt.tool, tt.args); err != nil {
+			t.Fatalf("%s: CallTool failed: %v", tt.tool, err)
+		}
+		if requested != tt.want {
+			t.Errorf("%s %v: requested %q, want %q", tt.tool, tt.args, requested, tt.want)
+		}
+	}
+}
+
+func TestOperationTimeouts_OverrideGlobalTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithTimeout(100*time.Millisecond).
+		WithOperationTimeouts(map[string]time.Duration{
+			"listpets":      2 * time.Second,
+			"/pets/{petId}": 2 * time.Second,
+		}))
+
+	if _, status, err := server.CallTool(context.Background(), "listpets", nil); err != nil || status != http.StatusOK {
+		t.Errorf("listpets = %d, %v; want the tool-name override to allow the slow call", status, err)
+	}
+	if _, status, err := server.CallTool(context.Background(), "deletepet", map[string]any{"petId": "1"}); err != nil || status != http.StatusOK {
+		t.Errorf("deletepet = %d, %v; want the path override to allow the slow call", status, err)
+	}
+	if _, _, err := server.CallTool(context.Background(), "createpet", map[string]any{"body": map[string]any{"name": "Rex"}}); err == nil {
+		t.Error("createpet succeeded; want the global timeout to apply")
+	}
+}
@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// allPagesArg is the tool argument that turns on auto-pagination for a call
+const allPagesArg = "allPages"
+
+// defaultMaxPages caps auto-pagination when PageFollow.MaxPages is not set
+const defaultMaxPages = 10
+
+// PageFollow describes how to reach the next page of a list operation so a
+// tool can fetch every page in one call
+type PageFollow struct {
+	// NextPath is the dotted JSON path of the next-page token or URL in the
+	// response body, e.g. "next" or "meta.next_cursor". Leave empty to use
+	// the Link header (rel="next") instead.
+	NextPath string `json:"nextPath,omitempty"`
+
+	// NextParam is the query parameter the token is sent in (e.g. "cursor").
+	// When empty the token is a URL whose query parameters are applied to
+	// the next request.
+	NextParam string `json:"nextParam,omitempty"`
+
+	// ItemsPath is the dotted JSON path of the item array in each page; empty
+	// means the response body is the array itself
+	ItemsPath string `json:"itemsPath,omitempty"`
+
+	// MaxPages caps the number of pages fetched (default 10)
+	MaxPages int `json:"maxPages,omitempty"`
+}
+
+// ExecuteAllPages runs a list operation and follows its next-page links,
+// returning the items of all pages combined into a single JSON array. It
+// stops after follow.MaxPages pages; a failing page ends the walk with that
+// page's result. All pages draw on the one MaxUpstreamAttempts budget.
+func (e *APIExecutor) ExecuteAllPages(ctx context.Context, method, path string, op *spec.Operation, args map[string]interface{}, follow PageFollow) (*APIResult, error) {
+	ctx = withAttemptBudget(ctx, e.MaxUpstreamAttempts)
+	maxPages := follow.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+
+	var items []interface{}
+	var last *APIResult
+	pageArgs := copyArgs(args)
+	for page := 0; page < maxPages; page++ {
+		result, err := e.ExecuteOperation(ctx, method, path, op, copyArgs(pageArgs))
+		if err != nil || result.StatusCode >= 400 || result.StatusCode == 0 {
+			return result, err
+		}
+		last = result
+
+		var body interface{}
+		if err := unmarshalJSONNumbers(result.Body, &body); err != nil {
+			return nil, fmt.Errorf("page %d is not JSON: %w", page+1, err)
+		}
+		pageItems, ok := lookupJSONPath(body, follow.ItemsPath).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("page %d has no item array at %q", page+1, follow.ItemsPath)
+		}
+		items = append(items, pageItems...)
+
+		next := nextPageToken(follow, body, result)
+		if next == "" {
+			break
+		}
+		if err := applyNextPage(pageArgs, follow, next); err != nil {
+			return nil, err
+		}
+	}
+
+	combined, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine pages: %w", err)
+	}
+	return &APIResult{
+		Content:    e.formatResponse(combined),
+		StatusCode: last.StatusCode,
+		Header:     last.Header,
+		Body:       combined,
+	}, nil
+}
+
+// nextPageToken extracts the next-page token or URL from a page, returning
+// "" on the last page
+func nextPageToken(follow PageFollow, body interface{}, result *APIResult) string {
+	if follow.NextPath == "" {
+		return linkNext(result.Header.Values("Link"))
+	}
+	switch next := lookupJSONPath(body, follow.NextPath).(type) {
+	case string:
+		return next
+	case json.Number:
+		return next.String()
+	default:
+		return ""
+	}
+}
+
+// applyNextPage points args at the next page, either by setting the token
+// parameter or by adopting the query parameters of a next-page URL
+func applyNextPage(args map[string]interface{}, follow PageFollow, next string) error {
+	if follow.NextParam != "" {
+		args[follow.NextParam] = next
+		return nil
+	}
+	nextURL, err := url.Parse(next)
+	if err != nil {
+		return fmt.Errorf("invalid next page URL %q: %w", next, err)
+	}
+	for name, values := range nextURL.Query() {
+		args[name] = values[0]
+	}
+	return nil
+}
+
+// linkNext returns the rel="next" target of RFC 8288 Link header values
+func linkNext(values []string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			for _, param := range parts[1:] {
+				name, rel, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, r := range strings.Fields(strings.Trim(rel, `"`)) {
+					if strings.EqualFold(r, "next") {
+						return target
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// lookupJSONPath follows a dotted path of object keys through decoded JSON;
+// an empty path returns v itself
+func lookupJSONPath(v interface{}, path string) interface{} {
+	if path == "" {
+		return v
+	}
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[key]
+	}
+	return v
+}
+
+// copyArgs returns a shallow copy of tool arguments, since building a
+// request consumes them
+func copyArgs(args map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+	return copied
+}
+
+// addAllPagesProperty exposes the auto-pagination switch in a tool input
+// schema
+func addAllPagesProperty(schema map[string]interface{}, follow PageFollow) {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	maxPages := follow.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+	properties[allPagesArg] = map[string]interface{}{
+		"type":        "boolean",
+		"description": fmt.Sprintf("Follow next-page links and return the items of all pages combined (up to %d pages)", maxPages),
+	}
+}
@@ -113,6 +113,26 @@ func TestInferBaseURL_Basic(t *testing.T) {
 			},
 			want: "https://api.example.com/v1",
 		},
+		{
+			name: "base path without leading slash",
+			swagger: &spec.Swagger{
+				SwaggerProps: spec.SwaggerProps{
+					Host:     "api.example.com",
+					BasePath: "v1/",
+				},
+			},
+			want: "https://api.example.com/v1",
+		},
+		{
+			name: "root base path",
+			swagger: &spec.Swagger{
+				SwaggerProps: spec.SwaggerProps{
+					Host:     "api.example.com",
+					BasePath: "/",
+				},
+			},
+			want: "https://api.example.com",
+		},
 		{
 			name: "no host",
 			swagger: &spec.Swagger{
@@ -189,4 +209,25 @@ func TestGetJSONType_Basic(t *testing.T) {
 			t.Errorf("getJSONType(%s) = %s, expected %s", test.input, result, test.expected)
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		base, path, want string
+	}{
+		{"http://x/api/", "/users", "http://x/api/users"},
+		{"http://x/api", "/users", "http://x/api/users"},
+		{"http://x/api", "users", "http://x/api/users"},
+		{"http://x/api//", "//users", "http://x/api/users"},
+		{"http://x/api", "/", "http://x/api/"},
+		{"http://x/api/?v=2", "/users", "http://x/api/users?v=2"},
+		{"http://x", "/users/{id}", "http://x/users/{id}"},
+		{"", "/users", "/users"},
+	}
+
+	for _, tt := range tests {
+		if got := joinURL(tt.base, tt.path); got != tt.want {
+			t.Errorf("joinURL(%q, %q) = %q, want %q", tt.base, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+// ErrAttemptBudgetExhausted is returned when a tool call would send more
+// upstream requests than MaxUpstreamAttempts allows
+var ErrAttemptBudgetExhausted = errors.New("upstream attempt budget exhausted")
+
+// attemptBudget counts the upstream requests one tool call may still send
+type attemptBudget struct {
+	limit     int64
+	remaining atomic.Int64
+}
+
+type attemptBudgetKey struct{}
+
+// withAttemptBudget returns a context carrying a fresh budget of max
+// attempts, unless max is 0 or ctx already carries one: the pages of an
+// auto-paginated call share the budget of the call
+func withAttemptBudget(ctx context.Context, max int) context.Context {
+	if max <= 0 || ctx.Value(attemptBudgetKey{}) != nil {
+		return ctx
+	}
+	budget := &attemptBudget{limit: int64(max)}
+	budget.remaining.Store(int64(max))
+	return context.WithValue(ctx, attemptBudgetKey{}, budget)
+}
+
+// spendAttempt takes one attempt from the budget in ctx, failing with
+// ErrAttemptBudgetExhausted once none are left. A context without a budget
+// allows any number of attempts.
+func spendAttempt(ctx context.Context) error {
+	budget, _ := ctx.Value(attemptBudgetKey{}).(*attemptBudget)
+	if budget == nil {
+		return nil
+	}
+	if budget.remaining.Add(-1) < 0 {
+		return fmt.Errorf("%w: a call may send at most %d requests", ErrAttemptBudgetExhausted, budget.limit)
+	}
+	return nil
+}
+
+// budgetedClient returns a copy of client that also spends an attempt on
+// each redirect it follows, since a followed 307 or 308 resends the request
+func budgetedClient(client *http.Client) *http.Client {
+	budgeted := *client
+	checkRedirect := client.CheckRedirect
+	budgeted.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if checkRedirect != nil {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= defaultMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", defaultMaxRedirects)
+		}
+		return spendAttempt(req.Context())
+	}
+	return &budgeted
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUpstreamAttemptBudget_CountsRedirectResends(t *testing.T) {
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		// Keep resending the POST, as a misbehaving load balancer might
+		http.Redirect(w, r, r.URL.Path, http.StatusTemporaryRedirect)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithUpstreamAttemptBudget(3))
+
+	_, _, err := server.CallTool(context.Background(), "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if !errors.Is(err, ErrAttemptBudgetExhausted) {
+		t.Fatalf("expected ErrAttemptBudgetExhausted, got %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("expected 3 upstream attempts, got %d", got)
+	}
+
+	// Each call gets a budget of its own
+	atomic.StoreInt32(&hits, 0)
+	_, _, err = server.CallTool(context.Background(), "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if !errors.Is(err, ErrAttemptBudgetExhausted) || atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("second call: %d attempts, err %v", atomic.LoadInt32(&hits), err)
+	}
+}
+
+func TestUpstreamAttemptBudget_SharedAcrossPages(t *testing.T) {
+	backend, hits := pagedBackend(t)
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(autoPaginateTestSwagger)).
+		WithAPIConfig(backend.URL, "").
+		WithUpstreamAttemptBudget(2).
+		WithAutoPagination(map[string]PageFollow{
+			"listitems": {NextPath: "meta.next_cursor", NextParam: "cursor", ItemsPath: "data"},
+		}))
+
+	_, _, err := server.CallTool(context.Background(), "listitems", map[string]any{"allPages": true})
+	if !errors.Is(err, ErrAttemptBudgetExhausted) {
+		t.Fatalf("expected ErrAttemptBudgetExhausted, got %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", got)
+	}
+
+	// A single page fits the budget
+	atomic.StoreInt32(hits, 0)
+	if _, _, err := server.CallTool(context.Background(), "listitems", nil); err != nil {
+		t.Fatalf("single page call failed: %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("expected 1 upstream call, got %d", got)
+	}
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheEntries bounds the response cache when no size is configured
+const defaultCacheEntries = 1000
+
+// responseCache is an in-memory LRU cache of GET responses with a fixed TTL
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front = most recently used
+}
+
+type cacheEntry struct {
+	key     string
+	result  APIResult
+	expires time.Time
+}
+
+// newResponseCache creates a cache holding up to maxEntries responses for
+// ttl each. A non-positive maxEntries selects defaultCacheEntries.
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheEntries
+	}
+	return &responseCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// get returns a copy of the cached result for key, if present and fresh.
+// Expired entries with an ETag are kept for revalidation with stale.
+func (c *responseCache) get(key string) (*APIResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		if entry.result.Header.Get("ETag") == "" {
+			c.lru.Remove(elem)
+			delete(c.entries, key)
+		}
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.copyResult(), true
+}
+
+// stale returns a copy of an expired result for key that carries an ETag,
+// so the request can be made conditional with If-None-Match
+func (c *responseCache) stale(key string) (*APIResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if entry.result.Header.Get("ETag") == "" {
+		return nil, false
+	}
+	return entry.copyResult(), true
+}
+
+// copyResult returns a copy of the entry's result that callers may modify
+func (e *cacheEntry) copyResult() *APIResult {
+	result := e.result
+	result.Header = e.result.Header.Clone()
+	return &result
+}
+
+// put stores result under key, evicting the least recently used entry when
+// the cache is full
+func (c *responseCache) put(key string, result *APIResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, result: *result, expires: time.Now().Add(c.ttl)}
+	entry.result.Header = result.Header.Clone()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheKey identifies a GET request by its URL, the credentials it is sent
+// with, including the custom API key header authHeader if set, and the
+// values of the operation's header parameters headerParams, so responses
+// are never shared across auth scopes or, say, tenants chosen by header.
+// These values are hashed rather than kept in memory verbatim.
+func cacheKey(req *http.Request, authHeader string, headerParams []string) string {
+	scope := sha256.New()
+	names := append([]string{"Authorization", "X-Api-Key", "Cookie", authHeader}, headerParams...)
+	for _, name := range names {
+		scope.Write([]byte(name + ":" + strings.Join(req.Header.Values(name), ", ") + "\n"))
+	}
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(scope.Sum(nil))
+}
+
+// cacheable reports whether a response may be stored: successful, not an
+// event stream and not marked no-store or no-cache
+func cacheable(resp *http.Response) bool {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || isEventStream(resp.Header) {
+		return false
+	}
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		switch strings.ToLower(strings.TrimSpace(directive)) {
+		case "no-store", "no-cache":
+			return false
+		}
+	}
+	return true
+}
+
+type skipCacheKey struct{}
+
+// SkipCache returns a context whose tool calls bypass the response cache.
+// MCP clients can request the same by setting "noCache": true in the
+// tools/call _meta.
+func SkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+// cacheSkipped reports whether ctx was created by SkipCache
+func cacheSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipCacheKey{}).(bool)
+	return skip
+}
@@ -0,0 +1,247 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// countingBackend serves a JSON list and counts the requests per path.
+func countingBackend(t *testing.T, cacheControl string) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		_, _ = w.Write([]byte(`[{"id": 1}]`))
+	}))
+	t.Cleanup(backend.Close)
+	return backend, &hits
+}
+
+func TestResponseCache_RepeatedGetServedFromCache(t *testing.T) {
+	backend, hits := countingBackend(t, "")
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseCache(time.Minute, 10))
+
+	first := resultText(t, callTool(t, server, "listpets", map[string]any{"limit": 5}))
+	second := resultText(t, callTool(t, server, "listpets", map[string]any{"limit": 5}))
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("expected 1 upstream call, got %d", got)
+	}
+	if first != second {
+		t.Errorf("cached result differs: %q vs %q", first, second)
+	}
+
+	// A different URL is a different entry
+	callTool(t, server, "listpets", map[string]any{"limit": 6})
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected 2 upstream calls, got %d", got)
+	}
+
+	// Writes are never cached
+	callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+	callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+	if got := atomic.LoadInt32(hits); got != 4 {
+		t.Errorf("expected 4 upstream calls, got %d", got)
+	}
+}
+
+func TestResponseCache_Expires(t *testing.T) {
+	backend, hits := countingBackend(t, "")
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseCache(50*time.Millisecond, 0))
+
+	callTool(t, server, "listpets", nil)
+	time.Sleep(100 * time.Millisecond)
+	callTool(t, server, "listpets", nil)
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected the expired entry to be refetched, got %d calls", got)
+	}
+}
+
+func TestResponseCache_RevalidatesWithETag(t *testing.T) {
+	var bodies, notModified int32
+	var ifNoneMatch string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifNoneMatch = r.Header.Get("If-None-Match")
+		if ifNoneMatch == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&bodies, 1)
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Rex"}]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseCache(20*time.Millisecond, 0))
+
+	first := resultText(t, callTool(t, server, "listpets", nil))
+	time.Sleep(40 * time.Millisecond)
+	second := callTool(t, server, "listpets", nil)
+	if second.IsError || resultText(t, second) != first {
+		t.Errorf("expected the cached body after a 304, got %q (first %q)", resultText(t, second), first)
+	}
+	if ifNoneMatch != `"v1"` {
+		t.Errorf("expected If-None-Match with the cached ETag, got %q", ifNoneMatch)
+	}
+	if b, n := atomic.LoadInt32(&bodies), atomic.LoadInt32(&notModified); b != 1 || n != 1 {
+		t.Errorf("expected 1 body download and 1 revalidation, got %d and %d", b, n)
+	}
+
+	// The revalidated entry is fresh again
+	callTool(t, server, "listpets", nil)
+	if n := atomic.LoadInt32(&notModified); n != 1 {
+		t.Errorf("expected the renewed entry to be served from cache, got %d revalidations", n)
+	}
+}
+
+func TestResponseCache_RespectsNoStore(t *testing.T) {
+	for _, cacheControl := range []string{"no-store", "private, no-cache"} {
+		backend, hits := countingBackend(t, cacheControl)
+		server := newTestServer(t, DefaultConfig().
+			WithAPIConfig(backend.URL, "").
+			WithResponseCache(time.Minute, 10))
+
+		callTool(t, server, "listpets", nil)
+		callTool(t, server, "listpets", nil)
+		if got := atomic.LoadInt32(hits); got != 2 {
+			t.Errorf("Cache-Control %q: expected 2 upstream calls, got %d", cacheControl, got)
+		}
+	}
+}
+
+func TestResponseCache_PerCallBypass(t *testing.T) {
+	backend, hits := countingBackend(t, "")
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResponseCache(time.Minute, 10))
+
+	callTool(t, server, "listpets", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := connectTestClient(t, server).CallTool(ctx, &sdk.CallToolParams{
+		Meta: sdk.Meta{"noCache": true},
+		Name: "listpets",
+	})
+	if err != nil {
+		t.Fatalf("tools/call failed: %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected noCache to reach upstream, got %d calls", got)
+	}
+
+	executor := server.GetMCPServer().apiExecutor
+	if _, _, err := executor.BuildAndExecuteRequest(SkipCache(context.Background()), "GET", "/pets", map[string]interface{}{}); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 3 {
+		t.Errorf("expected SkipCache to reach upstream, got %d calls", got)
+	}
+}
+
+func TestResponseCache_ScopedByCredentials(t *testing.T) {
+	backend, hits := countingBackend(t, "")
+	secrets := StaticSecrets{SecretAPIKey: "alice"}
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithSecretProvider(secrets).
+		WithResponseCache(time.Minute, 10))
+
+	callTool(t, server, "listpets", nil)
+	secrets[SecretAPIKey] = "bob"
+	callTool(t, server, "listpets", nil)
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected separate cache entries per API key, got %d calls", got)
+	}
+
+	// The same holds for a key sent in a custom header
+	backend, hits = countingBackend(t, "")
+	secrets[SecretAPIKey] = "alice"
+	server = newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithSecretProvider(secrets).
+		WithAuthHeaderMode("X-Auth-Token").
+		WithResponseCache(time.Minute, 10))
+
+	callTool(t, server, "listpets", nil)
+	secrets[SecretAPIKey] = "bob"
+	callTool(t, server, "listpets", nil)
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("expected separate cache entries per custom-header key, got %d calls", got)
+	}
+}
+
+func TestResponseCache_ScopedByHeaderParams(t *testing.T) {
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write([]byte(`{"tenant": "` + r.Header.Get("X-Tenant-Id") + `"}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "X-Tenant-Id", "in": "header", "type": "string"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`)).
+		WithAPIConfig(backend.URL, "").
+		WithResponseCache(time.Minute, 10))
+
+	for _, tenant := range []string{"acme", "globex"} {
+		got := resultText(t, callTool(t, server, "listpets", map[string]any{"X-Tenant-Id": tenant}))
+		if got != `{"tenant":"`+tenant+`"}` {
+			t.Errorf("tenant %s got %s", tenant, got)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("expected separate cache entries per header parameter value, got %d calls", got)
+	}
+
+	// The same header value is still served from the cache
+	callTool(t, server, "listpets", map[string]any{"X-Tenant-Id": "acme"})
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("expected a repeated tenant call to hit the cache, got %d calls", got)
+	}
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newResponseCache(time.Minute, 2)
+	cache.put("a", &APIResult{Content: "a"})
+	cache.put("b", &APIResult{Content: "b"})
+	cache.get("a")
+	cache.put("c", &APIResult{Content: "c"})
+
+	if _, ok := cache.get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("expected %s to be cached", key)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// callbackTimeout bounds each POST of a result to a callback URL
+const callbackTimeout = 30 * time.Second
+
+// CallRequest is the body of POST /call
+type CallRequest struct {
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	CallbackURL string                 `json:"callbackUrl,omitempty"` // Run in the background and POST the CallResult here
+}
+
+// CallResult reports a tool call made through POST /call, either as the
+// response or, for asynchronous calls, as the body POSTed to the callback
+type CallResult struct {
+	Ticket  string `json:"ticket,omitempty"` // Identifies an asynchronous call
+	Tool    string `json:"tool"`
+	Status  int    `json:"status"` // Upstream HTTP status code
+	Content string `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// handleCall handles POST /call. Without a callbackUrl the tool result is
+// returned directly; with one the call is accepted with a ticket (202),
+// run in the background, and its CallResult POSTed to the callback URL.
+func (h *HTTPServer) handleCall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid call request: %v", err), http.StatusBadRequest)
+		return
+	}
+	swagger, filter := h.server.mcp.exposedSpec()
+	if _, _, op := FindOperationByToolName(req.Tool, swagger, filter); op == nil && !h.server.mcp.isResourceTool(req.Tool) {
+		http.Error(w, fmt.Sprintf("unknown tool %q", req.Tool), http.StatusNotFound)
+		return
+	}
+	if req.CallbackURL == "" {
+		writeJSON(w, http.StatusOK, h.server.callResult(r.Context(), req.Tool, req.Arguments))
+		return
+	}
+
+	if err := validateCallbackURL(req.CallbackURL, h.server.config); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ticket, err := newTicket()
+	if err != nil {
+		http.Error(w, "failed to create ticket", http.StatusInternalServerError)
+		return
+	}
+
+	// The call outlives the request but not the server
+	go func() {
+		result := h.server.callResult(h.ctx, req.Tool, req.Arguments)
+		result.Ticket = ticket
+		if err := postCallback(h.ctx, h.server.mcp.apiExecutor.client(), req.CallbackURL, result); err != nil {
+			log.Printf("Failed to deliver result of %s (ticket %s) to callback: %v", req.Tool, ticket, err)
+		}
+	}()
+
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted", "ticket": ticket})
+}
+
+// callResult runs a tool call and reports its outcome
+func (s *Server) callResult(ctx context.Context, tool string, args map[string]interface{}) CallResult {
+	content, status, err := s.CallTool(ctx, tool, args)
+	result := CallResult{Tool: tool, Status: status, Content: content}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// validateCallbackURL checks that a callback URL is an absolute http(s) URL
+// on one of config's CallbackHosts, using https unless insecure http is
+// allowed
+func validateCallbackURL(callbackURL string, config *Config) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid callbackUrl %q: must be an absolute http or https URL", callbackURL)
+	}
+	if len(config.CallbackHosts) == 0 {
+		return fmt.Errorf("invalid callbackUrl: %w: no callback hosts are configured", ErrHostNotAllowed)
+	}
+	if err := checkAllowedHost(config.CallbackHosts, u); err != nil {
+		return fmt.Errorf("invalid callbackUrl: %w", err)
+	}
+	if !config.AllowInsecureHTTP {
+		if err := checkSecureScheme(u); err != nil {
+			return fmt.Errorf("invalid callbackUrl: %w", err)
+		}
+	}
+	return nil
+}
+
+// newTicket returns a random identifier for an asynchronous call
+func newTicket() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// postCallback POSTs result as JSON to callbackURL through client, without
+// following redirects that could lead off the callback hosts
+func postCallback(ctx context.Context, client *http.Client, callbackURL string, result CallResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, callbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := noRedirects.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode response: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// coerceParameterArgs converts string arguments to the integer, number or
+// boolean types declared by params, including properties of the body.
+// Strings that don't parse as the declared type are left for the upstream
+// to reject.
+func coerceParameterArgs(params []spec.Parameter, args map[string]interface{}, body interface{}) interface{} {
+	for _, param := range params {
+		if param.In == "body" {
+			if param.Schema != nil {
+				body = coerceSchemaValue(param.Schema, body)
+			}
+			continue
+		}
+		value, exists := args[param.Name]
+		if !exists {
+			continue
+		}
+		if param.Type == "array" && param.Items != nil {
+			if items, ok := value.([]interface{}); ok {
+				for i, item := range items {
+					items[i] = coerceString(item, param.Items.Type)
+				}
+			}
+			continue
+		}
+		args[param.Name] = coerceString(value, param.Type)
+	}
+	return body
+}
+
+// coerceSchemaValue converts the strings in value to the scalar types
+// schema declares, descending into object properties and array items
+func coerceSchemaValue(schema *spec.Schema, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, property := range schema.Properties {
+			property := property
+			if existing, exists := v[name]; exists {
+				v[name] = coerceSchemaValue(&property, existing)
+			}
+		}
+		return v
+	case []interface{}:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range v {
+				v[i] = coerceSchemaValue(schema.Items.Schema, item)
+			}
+		}
+		return v
+	}
+	for _, typ := range schema.Type {
+		if coerced := coerceString(value, typ); coerced != value {
+			return coerced
+		}
+	}
+	return value
+}
+
+// coerceString parses value as typ when it is a string, returning it
+// unchanged otherwise or when it doesn't parse
+func coerceString(value interface{}, typ string) interface{} {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	s = strings.TrimSpace(s)
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// coercibleTypes are the scalar types string arguments are converted to
+var coercibleTypes = map[string]bool{"integer": true, "number": true, "boolean": true}
+
+// allowStringScalars widens integer, number and boolean types in an input
+// schema to also accept strings, so clients that send every argument as a
+// string pass validation and reach coercion
+func allowStringScalars(schema map[string]interface{}) {
+	switch typ := schema["type"].(type) {
+	case string:
+		if coercibleTypes[typ] {
+			schema["type"] = []interface{}{typ, "string"}
+		}
+	case []interface{}:
+		for _, t := range typ {
+			if name, _ := t.(string); coercibleTypes[name] {
+				schema["type"] = append(typ, "string")
+				break
+			}
+		}
+	}
+
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		for _, property := range properties {
+			if propertySchema, ok := property.(map[string]interface{}); ok {
+				allowStringScalars(propertySchema)
+			}
+		}
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		allowStringScalars(items)
+	}
+}
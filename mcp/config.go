@@ -2,13 +2,18 @@ package mcp
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
 	"io"
+	"net/http"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/go-openapi/spec"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // APIFilter represents different types of API filtering
@@ -19,6 +24,10 @@ type APIFilter struct {
 	// Path patterns to exclude (supports wildcards like /api/v1/*)
 	ExcludePathPatterns []string
 	
+	// Regular expressions matched against paths to exclude (e.g. "/internal/").
+	// Invalid expressions are reported by New.
+	ExcludePathRegexps []string
+	
 	// Operation IDs to exclude
 	ExcludeOperationIDs []string
 	
@@ -28,11 +37,25 @@ type APIFilter struct {
 	// Tag-based filtering - exclude operations with these tags
 	ExcludeTags []string
 	
+	// Exclude operations marked `deprecated: true`
+	ExcludeDeprecated bool
+	
 	// Include only specific paths (if provided, only these will be included)
 	IncludeOnlyPaths []string
 	
 	// Include only specific operation IDs
 	IncludeOnlyOperationIDs []string
+	
+	// Custom inclusion rule checked alongside the others: operations it
+	// returns false for are excluded
+	OperationFilter func(method, path string, operation *spec.Operation) bool
+	
+	// ExcludePathRegexps as compiled by New
+	excludePathRegexps []*regexp.Regexp
+	
+	// Operation tool names kept when Config.MaxTools truncates the tool
+	// set. Only set on the server's own copy of the filter.
+	limitedToolNames map[string]bool
 }
 
 // Config holds the configuration for the MCP server
@@ -41,9 +64,33 @@ type Config struct {
 	APIBaseURL string
 	APIKey     string
 	
+	// Additional credentials
+	BasicAuthUsername string
+	BasicAuthPassword string
+	SecretProvider    SecretProvider           // Consulted for secrets before the values above
+	OAuth2            *OAuth2ClientCredentials // Bearer tokens from the client-credentials grant
+	PerCallAuth       bool                     // Take the API key per call from the _apiKey tool argument
+	AuthHeaderMode    AuthHeaderMode           // Headers the API key is sent in (default both X-API-Key and Bearer)
+	
+	// CredentialFunc picks credentials per operation (falls back to the
+	// credentials above when it returns false)
+	CredentialFunc func(method, path string, op *spec.Operation) (Credential, bool)
+	
+	// Cookies sent with every upstream call; with CookieParams, operations'
+	// cookie parameters are also exposed as tool arguments
+	Cookies      map[string]string
+	CookieParams bool
+	
 	// Swagger specification
-	SwaggerSpec *spec.Swagger
-	SwaggerData []byte // Raw swagger data for lazy loading
+	SwaggerSpec      *spec.Swagger
+	SwaggerData      []byte // Raw swagger data for lazy loading
+	SpecWatchPath    string // Spec file to watch and hot-reload while running
+	ToolManifestPath string // JSON file the registered tool definitions are written to
+	
+	// External $ref resolution: relative refs resolve against SpecLocation,
+	// remote refs are only fetched from its host or AllowedRefHosts
+	SpecLocation    string
+	AllowedRefHosts []string
 	
 	// Server configuration
 	Name        string
@@ -55,6 +102,141 @@ type Config struct {
 	
 	// API filtering configuration
 	Filter *APIFilter
+	
+	// MaxTools caps the number of registered tools after filtering, a
+	// grouped resource tool counting once (0 = unlimited). New fails when
+	// the spec yields more, unless TruncateTools keeps the first MaxTools
+	// by name.
+	MaxTools      int
+	TruncateTools bool
+
+	// BaseURLFunc selects the base URL per operation (falls back to APIBaseURL when it returns "")
+	BaseURLFunc func(method, path string, op *spec.Operation) string
+	
+	// ServerVariableArgs exposes the variables of a templated OpenAPI 3
+	// server URL as _server_<name> tool arguments
+	ServerVariableArgs bool
+	
+	// RawPathParams names path parameters whose slashes are sent unescaped
+	RawPathParams []string
+	
+	// Rewrites of operation paths in request URLs, for base URLs that
+	// already include a prefix the spec paths repeat, or lack one they omit
+	StripPathPrefix string
+	AddPathPrefix   string
+	
+	// RichDescriptions adds the method, path and required parameters to
+	// tool descriptions
+	RichDescriptions bool
+	
+	// ResourceGrouping registers one manage_<resource> tool per resource
+	// path, whose action argument selects the operation
+	ResourceGrouping bool
+	
+	// MaxConcurrency caps simultaneous upstream calls (0 = unlimited)
+	MaxConcurrency int
+	
+	// PreferredResponseCode selects which documented success response
+	// describes tool output (0 = the lowest 2xx with a schema)
+	PreferredResponseCode int
+	
+	// ResponseValidation checks 2xx responses against their documented
+	// schema and appends mismatches as warnings; StrictResponseValidation
+	// fails the call instead
+	ResponseValidation       bool
+	StrictResponseValidation bool
+
+	// Upstream HTTP client configuration
+	ProxyURL           string            // Explicit proxy (http, https or socks5); defaults to the HTTP(S)_PROXY environment
+	InsecureSkipVerify bool              // Skip TLS certificate verification (self-signed staging APIs only)
+	RootCAs            *x509.CertPool    // Custom CA pool for verifying upstream certificates
+	UserAgent          string            // User-Agent header (default "mcp-swagger-server/<Version>")
+	ContentType        string            // Request body Content-Type when the operation declares none (default application/json)
+	Accept             string            // Accept header when the operation declares no produces (default application/json)
+	AcceptPolicy       AcceptPolicy      // Negotiate Accept (default), send it verbatim, or omit it
+	MinTLSVersion      uint16            // Minimum TLS version for upstream calls (default tls.VersionTLS12)
+	Timeout            time.Duration     // Overall timeout for each upstream call (0 = none)
+	ConnectTimeout     time.Duration     // Timeout for connecting and the TLS handshake (0 = Go's defaults)
+	Headers            map[string]string // Extra headers sent with every upstream call
+	DefaultQueryParams map[string]string // Query parameters sent with every upstream call unless the call sets them
+	IdempotencyHeader  string            // Header carrying a generated idempotency key on POST and PATCH calls
+	RedirectPolicy     RedirectPolicy    // How 3xx redirects are handled (default RedirectFollow)
+	MaxRedirects       int               // Longest redirect chain followed (default 10)
+	AllowedHosts       []string          // Hosts upstream calls may reach (empty = any)
+	AllowInsecureHTTP  bool              // Permit a plaintext http:// base URL to a non-loopback host
+
+	// MaxUpstreamAttempts caps the requests one tool call may send, followed
+	// redirects and auto-paginated pages included (0 = no cap)
+	MaxUpstreamAttempts int
+
+	// OperationTimeouts overrides Timeout for the operations it names, by
+	// tool name or path template
+	OperationTimeouts map[string]time.Duration
+
+	// BooleanQueryFormat sets how boolean query arguments are written
+	// (default BooleanTrueFalse)
+	BooleanQueryFormat BooleanQueryFormat
+
+	// Execution options
+	DryRun                 bool           // Return a preview of each request instead of sending it
+	RawResponsePassthrough bool           // Return upstream response bodies byte-for-byte
+	ResponseFormat         ResponseFormat // compact (default), pretty or raw JSON responses
+	SummarizeWrites        bool           // Return a status + id summary for write operations
+	NDJSONArrays           bool           // Render array responses as newline-delimited JSON
+	ApplyDefaults          bool           // Fill omitted body/form fields from spec defaults
+	ParamCoercion          bool           // Convert string args to declared integer/number/boolean types
+	FormatValidation       bool           // Reject string args that do not match their format (email, uuid, date...)
+	StrictParams           bool           // Reject args and body fields the spec does not declare
+	MaxRequestBytes        int64          // Reject request bodies larger than this (0 = unlimited)
+	IncludeResponseHeaders []string       // Response headers returned with the body ("*" = all)
+	ResponseEnvelope       bool           // Wrap every successful result as {"status", "contentType", "data"}
+	NonErrorCodes          []int          // Status codes >= 400 returned as normal results, not errors
+	
+	// Recording of upstream calls for replay testing: RecordPath appends
+	// each call and its response to a JSON Lines file, ReplayPath serves
+	// responses from such a file instead of calling the API
+	RecordPath string
+	ReplayPath string
+	
+	// Request and response hooks: RequestTransform rewrites tool arguments
+	// before each call, ResponseTransform rewrites response bodies before
+	// formatting
+	RequestTransform  RequestTransform
+	ResponseTransform ResponseTransform
+	
+	// Arguments RequestTransform supplies, which clients need not send
+	RequestTransformArgs []string
+	
+	// Bounds on reading text/event-stream responses (0 = 10s and 1 MiB)
+	StreamMaxDuration time.Duration
+	StreamMaxBytes    int64
+
+	// Response caching for GET calls (disabled when the TTL is zero)
+	ResponseCacheTTL        time.Duration
+	ResponseCacheMaxEntries int
+
+	// Pagination aliases (uniform limit/offset/page arguments)
+	PaginationAliases   bool
+	PaginationOverrides map[string]PaginationParams // Explicit mappings keyed by tool name
+	PageFollow          map[string]PageFollow       // Auto-pagination settings keyed by tool name
+
+	// AsyncCallbacks enables POST /call on the HTTP transport, which can run
+	// a tool call in the background and POST its result to a callback URL
+	// on one of CallbackHosts
+	AsyncCallbacks bool
+	CallbackHosts  []string
+
+	// HTTPMiddleware wraps the HTTP transport's handler, outermost first
+	HTTPMiddleware []func(http.Handler) http.Handler
+
+	// Upstream probe for the deep health check: GET HealthProbePath, or
+	// HEAD the base URL when it is empty
+	HealthProbePath    string
+	HealthProbeTimeout time.Duration // Default 5s
+
+	// Observability
+	Tracer  trace.Tracer     // Optional OpenTelemetry tracer for tool and HTTP spans
+	Metrics MetricsCollector // Optional collector for per-tool call metrics
 }
 
 // Transport interface for different transport methods
@@ -85,6 +267,23 @@ func (t *HTTPTransport) Connect(ctx context.Context, server *mcp.Server) (*mcp.S
 	return nil, fmt.Errorf("HTTP transport requires special handling, use Server.RunHTTP instead")
 }
 
+// StdioAndHTTPTransport serves MCP over stdio while the HTTP transport,
+// including its /health and /tools endpoints, runs alongside it on the
+// same Server
+type StdioAndHTTPTransport struct {
+	HTTP  *HTTPTransport
+	Stdio Transport // Transport for the stdio side (default StdioTransport)
+}
+
+// Connect connects the stdio side; Server.Run starts the HTTP side
+func (t *StdioAndHTTPTransport) Connect(ctx context.Context, server *mcp.Server) (*mcp.ServerSession, error) {
+	stdio := t.Stdio
+	if stdio == nil {
+		stdio = &StdioTransport{}
+	}
+	return stdio.Connect(ctx, server)
+}
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -114,6 +313,282 @@ func (c *Config) WithAPIConfig(baseURL, apiKey string) *Config {
 	return c
 }
 
+// WithBaseURLFunc computes the base URL per operation, e.g. to route
+// region-prefixed paths to different hosts. When fn returns an empty string
+// the static APIBaseURL is used.
+func (c *Config) WithBaseURLFunc(fn func(method, path string, op *spec.Operation) string) *Config {
+	c.BaseURLFunc = fn
+	return c
+}
+
+// WithCredentialFunc picks the credentials per operation, e.g. a separate
+// API key for /admin/* when one server wraps sections of an API that
+// authenticate differently. When fn returns false the configured API key,
+// basic auth or OAuth2 credentials are used.
+func (c *Config) WithCredentialFunc(fn func(method, path string, op *spec.Operation) (Credential, bool)) *Config {
+	c.CredentialFunc = fn
+	return c
+}
+
+// WithMaxConcurrency allows at most n upstream calls in flight at once,
+// across all transports and sessions. Further calls queue until a slot
+// frees up or their context is cancelled. n <= 0 means no limit.
+func (c *Config) WithMaxConcurrency(n int) *Config {
+	c.MaxConcurrency = n
+	return c
+}
+
+// WithServerVariableArgs lets callers fill in the variables of a templated
+// OpenAPI 3 server URL, such as https://{region}.api.example.com, per call:
+// each variable becomes an optional _server_<name> tool argument that is
+// substituted into the base URL, falling back to the variable's default.
+// Values outside a variable's enum are rejected. Without it the defaults are
+// always used.
+func (c *Config) WithServerVariableArgs(enabled bool) *Config {
+	c.ServerVariableArgs = enabled
+	return c
+}
+
+// WithAllowInsecureHTTP permits a plaintext http:// base URL to a remote
+// host. By default New refuses one, since the API key would cross the
+// network unencrypted; localhost and loopback addresses are always allowed.
+func (c *Config) WithAllowInsecureHTTP(allowed bool) *Config {
+	c.AllowInsecureHTTP = allowed
+	return c
+}
+
+// WithMaxTools fails server creation when the spec yields more than n
+// tools after filtering, since some MCP clients can't cope with hundreds
+// of tools. Use filters to reduce the set, or WithTruncateTools to keep
+// the first n.
+func (c *Config) WithMaxTools(n int) *Config {
+	c.MaxTools = n
+	return c
+}
+
+// WithTruncateTools keeps the first MaxTools tools, sorted by name, and
+// logs a warning instead of failing when the limit is exceeded
+func (c *Config) WithTruncateTools(truncate bool) *Config {
+	c.TruncateTools = truncate
+	return c
+}
+
+// WithRichDescriptions appends the HTTP method and path and a concise list
+// of required parameters with their types to each tool description, so
+// the model can tell when and how to call it. See GenerateRichToolDescription.
+func (c *Config) WithRichDescriptions(enabled bool) *Config {
+	c.RichDescriptions = enabled
+	return c
+}
+
+// WithResourceGrouping collapses the operations on a resource, e.g. GET
+// /pets, POST /pets and GET/PUT/DELETE /pets/{petId}, into a single
+// manage_pets tool whose action argument (list, get, create, update or
+// delete) selects the operation, saving tokens on large specs. Resources
+// with a single operation keep their own tool. ListToolsDetailed and the
+// /tools listing still describe the individual operations.
+func (c *Config) WithResourceGrouping(enabled bool) *Config {
+	c.ResourceGrouping = enabled
+	return c
+}
+
+// toolDescription describes an operation's tool according to the config
+func (c *Config) toolDescription(method, path string, op *spec.Operation) string {
+	if c != nil && c.RichDescriptions {
+		return GenerateRichToolDescription(method, path, op)
+	}
+	return GenerateToolDescription(method, path, op)
+}
+
+// WithPreferredResponseCode picks the response schema describing a tool's
+// output when an operation documents several success codes, e.g. 201 over
+// 200. Operations without a schema for code fall back to their lowest 2xx
+// with a schema, then to the default response.
+func (c *Config) WithPreferredResponseCode(code int) *Config {
+	c.PreferredResponseCode = code
+	return c
+}
+
+// WithResponseValidation checks each 2xx JSON response against the schema
+// the operation documents for its status code and appends any mismatches,
+// such as a missing required field, to the tool result as warnings. Useful
+// for debugging APIs that drift from their spec.
+func (c *Config) WithResponseValidation(enabled bool) *Config {
+	c.ResponseValidation = enabled
+	return c
+}
+
+// WithStrictResponseValidation validates responses like
+// WithResponseValidation but fails the call when one does not match
+func (c *Config) WithStrictResponseValidation(enabled bool) *Config {
+	c.StrictResponseValidation = enabled
+	return c
+}
+
+// WithRawPathParams sends the named path parameters with their slashes
+// intact, for routes like /files/{path} that take a file path. Other unsafe
+// characters are still escaped. Parameters with format "path" or an
+// x-raw-path extension in the spec are treated this way automatically.
+func (c *Config) WithRawPathParams(names ...string) *Config {
+	c.RawPathParams = append(c.RawPathParams, names...)
+	return c
+}
+
+// WithIdempotency sends a freshly generated UUID in headerName (default
+// Idempotency-Key) with every POST and PATCH call, so an API that honours
+// idempotency keys can recognise a resent request instead of creating a
+// duplicate. The key is stable for one tool call and covers the resends
+// of that call, which are the followed 307 and 308 redirects counted by
+// WithUpstreamAttemptBudget; the server does not retry failed calls
+// itself, and a call the client repeats gets a new key. A value the call
+// supplies through a header parameter is kept.
+func (c *Config) WithIdempotency(headerName string) *Config {
+	if headerName == "" {
+		headerName = DefaultIdempotencyHeader
+	}
+	c.IdempotencyHeader = headerName
+	return c
+}
+
+// WithStripPathPrefix removes prefix from the start of operation paths when
+// building request URLs, e.g. "/v2" when the base URL already ends in /v2
+// and the spec paths repeat it. Paths without the prefix are unchanged. The
+// base URL, whether configured or inferred from the spec's basePath, is
+// never rewritten; tool names still come from the spec paths.
+func (c *Config) WithStripPathPrefix(prefix string) *Config {
+	c.StripPathPrefix = prefix
+	return c
+}
+
+// WithAddPathPrefix prepends prefix to operation paths when building
+// request URLs, for specs whose paths omit a prefix the API expects, e.g.
+// "/api". It is applied after WithStripPathPrefix, between the base URL and
+// the spec path.
+func (c *Config) WithAddPathPrefix(prefix string) *Config {
+	c.AddPathPrefix = prefix
+	return c
+}
+
+// WithBasicAuth authenticates upstream calls with HTTP basic auth. The
+// password may be left empty when a SecretProvider supplies it.
+func (c *Config) WithBasicAuth(username, password string) *Config {
+	c.BasicAuthUsername = username
+	c.BasicAuthPassword = password
+	return c
+}
+
+// WithSecretProvider sources credentials (API key, basic auth password,
+// OAuth client secret) from provider, falling back to values set directly
+// on the config. The provider is queried per request.
+func (c *Config) WithSecretProvider(provider SecretProvider) *Config {
+	c.SecretProvider = provider
+	return c
+}
+
+// WithOAuth2ClientCredentials authenticates upstream calls with bearer
+// tokens from the OAuth2 client-credentials grant. Tokens are fetched on
+// first use, shared by concurrent calls and refreshed before they expire.
+// An empty clientSecret is looked up from the SecretProvider.
+func (c *Config) WithOAuth2ClientCredentials(tokenURL, clientID, clientSecret string, scopes []string) *Config {
+	c.OAuth2 = &OAuth2ClientCredentials{
+		TokenURL:     tokenURL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       scopes,
+	}
+	return c
+}
+
+// WithCookies sends the given cookies (name to value) with every upstream
+// call, e.g. for APIs that authenticate with a session cookie
+func (c *Config) WithCookies(cookies map[string]string) *Config {
+	c.Cookies = cookies
+	return c
+}
+
+// WithAuthHeaderMode selects the headers the API key is sent in:
+// AuthHeaderBoth (the default) sends X-API-Key and Authorization: Bearer,
+// AuthHeaderAPIKey and AuthHeaderBearer send only one of them, and any
+// other value is the name of a custom header, e.g. "X-Auth-Token". APIs
+// that reject an unexpected second credential need one of the latter.
+func (c *Config) WithAuthHeaderMode(mode AuthHeaderMode) *Config {
+	c.AuthHeaderMode = mode
+	return c
+}
+
+// WithPerCallAuth lets each tool call supply its own API key in the
+// reserved _apiKey argument, e.g. when every end user of a shared server
+// has their own token. The key replaces the configured credentials for that
+// request only and is never forwarded as a parameter. Only enable it when
+// the MCP clients are trusted to choose the credentials.
+func (c *Config) WithPerCallAuth(enabled bool) *Config {
+	c.PerCallAuth = enabled
+	return c
+}
+
+// WithCookieParams exposes operations' `in: cookie` parameters as tool
+// arguments and sends them as cookies on each call
+func (c *Config) WithCookieParams() *Config {
+	c.CookieParams = true
+	return c
+}
+
+// WithSpecWatch reloads the spec from path whenever the file changes while
+// the server runs, without dropping client connections
+func (c *Config) WithSpecWatch(path string) *Config {
+	c.SpecWatchPath = path
+	return c
+}
+
+// WithToolManifestPath writes the registered tools (name, description,
+// method, path, input schema and annotations) as JSON to path when the
+// server is created and whenever the spec is reloaded
+func (c *Config) WithToolManifestPath(path string) *Config {
+	c.ToolManifestPath = path
+	return c
+}
+
+// WithSpecLocation records the file path or URL the spec was read from, so
+// relative $refs to other documents resolve against it
+func (c *Config) WithSpecLocation(location string) *Config {
+	c.SpecLocation = location
+	return c
+}
+
+// WithAllowedRefHosts allows remote $refs to be fetched from hosts. The host
+// of a spec loaded from a URL is always allowed.
+func (c *Config) WithAllowedRefHosts(hosts ...string) *Config {
+	c.AllowedRefHosts = append(c.AllowedRefHosts, hosts...)
+	return c
+}
+
+// parseOptions returns the $ref resolution options for parsing the spec
+func (c *Config) parseOptions() ParseOptions {
+	return ParseOptions{Location: c.SpecLocation, AllowedRefHosts: c.AllowedRefHosts}
+}
+
+// WithStreamLimits bounds how upstream text/event-stream responses are
+// read: until the stream ends, maxDuration passes or maxBytes have arrived.
+// The tool result lists the complete events received, with "truncated" set
+// when a limit cut the stream short. Zero keeps the defaults of 10s and
+// 1 MiB; Timeout, when shorter, still bounds the whole call.
+func (c *Config) WithStreamLimits(maxDuration time.Duration, maxBytes int64) *Config {
+	c.StreamMaxDuration = maxDuration
+	c.StreamMaxBytes = maxBytes
+	return c
+}
+
+// WithResponseCache caches successful GET responses in memory for ttl,
+// keeping at most maxEntries (0 selects a default of 1000). Responses marked
+// Cache-Control no-store or no-cache are not cached, and entries are scoped
+// to the credentials the request was sent with. Individual calls can bypass
+// the cache with SkipCache or a "noCache": true tools/call _meta field.
+func (c *Config) WithResponseCache(ttl time.Duration, maxEntries int) *Config {
+	c.ResponseCacheTTL = ttl
+	c.ResponseCacheMaxEntries = maxEntries
+	return c
+}
+
 // WithTransport sets the transport method
 func (c *Config) WithTransport(transport Transport) *Config {
 	c.Transport = transport
@@ -170,6 +645,15 @@ func (c *Config) WithExcludeMethods(methods ...string) *Config {
 	return c
 }
 
+// WithExcludeDeprecated excludes operations marked as deprecated
+func (c *Config) WithExcludeDeprecated() *Config {
+	if c.Filter == nil {
+		c.Filter = &APIFilter{}
+	}
+	c.Filter.ExcludeDeprecated = true
+	return c
+}
+
 // WithExcludeTags sets tags to exclude
 func (c *Config) WithExcludeTags(tags ...string) *Config {
 	if c.Filter == nil {
@@ -197,6 +681,437 @@ func (c *Config) WithIncludeOnlyOperationIDs(ids ...string) *Config {
 	return c
 }
 
+// WithOperationFilter sets a custom rule deciding which operations become
+// tools, e.g. by description or parameter count. An operation must pass
+// both this function and the declarative filters to be included.
+func (c *Config) WithOperationFilter(include func(method, path string, operation *spec.Operation) bool) *Config {
+	if c.Filter == nil {
+		c.Filter = &APIFilter{}
+	}
+	c.Filter.OperationFilter = include
+	return c
+}
+
+// WithProxy routes upstream API calls through the given proxy URL
+// (e.g. http://proxy:3128 or socks5://proxy:1080), overriding the
+// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables
+func (c *Config) WithProxy(proxyURL string) *Config {
+	c.ProxyURL = proxyURL
+	return c
+}
+
+// WithInsecureSkipVerify disables TLS certificate verification for upstream
+// API calls. Intended for self-signed staging APIs only; a warning is logged
+// when enabled. Prefer WithRootCAs where possible.
+func (c *Config) WithInsecureSkipVerify(skip bool) *Config {
+	c.InsecureSkipVerify = skip
+	return c
+}
+
+// WithRootCAs verifies upstream certificates against the given CA pool
+// instead of the system roots
+func (c *Config) WithRootCAs(pool *x509.CertPool) *Config {
+	c.RootCAs = pool
+	return c
+}
+
+// WithUserAgent overrides the User-Agent header sent on upstream calls
+func (c *Config) WithUserAgent(userAgent string) *Config {
+	c.UserAgent = userAgent
+	return c
+}
+
+// WithTimeout bounds each upstream call, including reading the response
+// body, to d. Zero disables the timeout.
+func (c *Config) WithTimeout(d time.Duration) *Config {
+	c.Timeout = d
+	return c
+}
+
+// WithConnectTimeout bounds establishing each upstream connection, both
+// dialing and the TLS handshake, to d, so an unreachable host fails fast
+// while WithTimeout still allows slow response bodies. Zero keeps Go's
+// defaults.
+func (c *Config) WithConnectTimeout(d time.Duration) *Config {
+	c.ConnectTimeout = d
+	return c
+}
+
+// WithOperationTimeouts overrides the timeout of individual operations,
+// keyed by tool name (e.g. "generatereport") or path template (e.g.
+// "/reports/{id}"); a tool name match wins. Other calls keep the WithTimeout
+// timeout. Overrides may be longer or shorter than it.
+func (c *Config) WithOperationTimeouts(timeouts map[string]time.Duration) *Config {
+	if c.OperationTimeouts == nil {
+		c.OperationTimeouts = make(map[string]time.Duration, len(timeouts))
+	}
+	for key, timeout := range timeouts {
+		c.OperationTimeouts[key] = timeout
+	}
+	return c
+}
+
+// WithAsyncCallbacks enables the HTTP transport's POST /call endpoint.
+// A request naming a callbackUrl is answered at once with 202 and a
+// ticket; the upstream call runs in the background and its result is
+// POSTed to the callback. Without a callbackUrl the result is returned
+// directly. Callback URLs must name a host listed with WithCallbackHosts.
+func (c *Config) WithAsyncCallbacks(enabled bool) *Config {
+	c.AsyncCallbacks = enabled
+	return c
+}
+
+// WithCallbackHosts lists the hosts POST /call may deliver results to.
+// Without it every callbackUrl is refused, so a caller cannot have results
+// sent to internal services. Plain http is refused for hosts other than
+// the local machine unless WithAllowInsecureHTTP is set, and redirects
+// from a callback are not followed.
+func (c *Config) WithCallbackHosts(hosts []string) *Config {
+	c.CallbackHosts = hosts
+	return c
+}
+
+// WithHealthProbe configures the upstream probe behind /health?deep=true
+// and /readyz. path is requested with GET relative to the API base URL;
+// when empty, the base URL itself is probed with HEAD.
+func (c *Config) WithHealthProbe(path string, timeout time.Duration) *Config {
+	c.HealthProbePath = path
+	c.HealthProbeTimeout = timeout
+	return c
+}
+
+// WithAllowedHosts restricts upstream calls to the given hosts, guarding
+// against specs that point the server at internal endpoints such as cloud
+// metadata services. New fails if the resolved base URL's host is not
+// listed, and calls or redirects to other hosts are refused.
+func (c *Config) WithAllowedHosts(hosts []string) *Config {
+	c.AllowedHosts = hosts
+	return c
+}
+
+// WithRedirectPolicy sets how upstream redirects are handled: followed
+// (the default), returned as the result, or followed with credentials
+// stripped when they leave the original host
+func (c *Config) WithRedirectPolicy(policy RedirectPolicy) *Config {
+	c.RedirectPolicy = policy
+	return c
+}
+
+// WithMaxRedirects bounds the redirect chain a call follows to n
+// redirects; a longer or looping chain fails the call. Zero or less keeps
+// the default of 10.
+func (c *Config) WithMaxRedirects(n int) *Config {
+	c.MaxRedirects = n
+	return c
+}
+
+// WithUpstreamAttemptBudget caps the HTTP requests a single tool call may
+// send at n. Every attempt counts: the first request, each followed
+// redirect (which resends the request) and each page of an allPages call.
+// A call that runs out fails with ErrAttemptBudgetExhausted. Zero or less
+// removes the cap.
+func (c *Config) WithUpstreamAttemptBudget(n int) *Config {
+	c.MaxUpstreamAttempts = n
+	return c
+}
+
+// WithBooleanQueryFormat sets how boolean query arguments are written:
+// BooleanTrueFalse (the default), BooleanOneZero or BooleanYesNo. A
+// parameter's x-boolean-format extension overrides it. JSON bodies keep
+// their booleans.
+func (c *Config) WithBooleanQueryFormat(format BooleanQueryFormat) *Config {
+	c.BooleanQueryFormat = format
+	return c
+}
+
+// WithHeaders sends headers with every upstream call, e.g. a tenant or
+// API version header. Header parameters passed per call and credentials
+// take precedence.
+func (c *Config) WithHeaders(headers map[string]string) *Config {
+	if c.Headers == nil {
+		c.Headers = make(map[string]string, len(headers))
+	}
+	for name, value := range headers {
+		c.Headers[name] = value
+	}
+	return c
+}
+
+// WithDefaultQueryParams adds query parameters to every upstream call, e.g.
+// a required api-version. A value the tool call supplies for the same
+// parameter takes precedence.
+func (c *Config) WithDefaultQueryParams(params map[string]string) *Config {
+	if c.DefaultQueryParams == nil {
+		c.DefaultQueryParams = make(map[string]string, len(params))
+	}
+	for name, value := range params {
+		c.DefaultQueryParams[name] = value
+	}
+	return c
+}
+
+// WithContentType sets the Content-Type of request bodies, e.g.
+// "application/vnd.api+json". An operation's consumes (or OpenAPI 3
+// requestBody content) takes precedence; when it lists several JSON types
+// the configured one is used if present.
+func (c *Config) WithContentType(contentType string) *Config {
+	c.ContentType = contentType
+	return c
+}
+
+// WithAccept sets the Accept header sent on upstream calls. Like
+// WithContentType, an operation's produces takes precedence. An empty
+// accept sends no Accept header at all, as WithoutAcceptHeader does; a
+// non-empty one restores negotiation.
+func (c *Config) WithAccept(accept string) *Config {
+	c.Accept = accept
+	if accept == "" {
+		c.AcceptPolicy = AcceptOmit
+	} else {
+		c.AcceptPolicy = AcceptNegotiate
+	}
+	return c
+}
+
+// WithFixedAccept sends accept as the Accept header of every upstream
+// call, regardless of what the operation produces. An empty accept sends
+// none.
+func (c *Config) WithFixedAccept(accept string) *Config {
+	c.Accept = accept
+	c.AcceptPolicy = AcceptFixed
+	return c
+}
+
+// WithoutAcceptHeader sends upstream calls without an Accept header, for
+// APIs whose strict content negotiation answers 406 to the types sent
+func (c *Config) WithoutAcceptHeader() *Config {
+	c.AcceptPolicy = AcceptOmit
+	return c
+}
+
+// WithMinTLSVersion sets the minimum TLS version accepted for upstream
+// calls, e.g. tls.VersionTLS13. Defaults to TLS 1.2.
+func (c *Config) WithMinTLSVersion(version uint16) *Config {
+	c.MinTLSVersion = version
+	return c
+}
+
+// WithDryRun makes tool calls return the request they would send (method,
+// resolved URL, redacted headers and body) without calling the API
+func (c *Config) WithDryRun(enabled bool) *Config {
+	c.DryRun = enabled
+	return c
+}
+
+// WithFormatValidation rejects tool arguments whose string values do not
+// match their schema format before any request is sent, e.g. a malformed
+// email or uuid. It covers email, date, date-time, time, uuid, uri, ipv4
+// and ipv6. Off by default, since specs are not always strict about the
+// formats their APIs accept.
+func (c *Config) WithFormatValidation(enabled bool) *Config {
+	c.FormatValidation = enabled
+	return c
+}
+
+// WithStrictParams rejects tool calls carrying arguments the operation does
+// not declare, instead of sending them as query parameters or body fields,
+// so names the model made up never reach the API. Fields of a request body
+// are held to its schema too, unless the schema allows additional
+// properties. Off by default.
+func (c *Config) WithStrictParams(enabled bool) *Config {
+	c.StrictParams = enabled
+	return c
+}
+
+// WithRecorder appends every upstream call a tool makes, with its arguments
+// and the response, to a JSON Lines file at path. Credentials in the URL
+// and the per-call API key argument are redacted.
+func (c *Config) WithRecorder(path string) *Config {
+	c.RecordPath = path
+	return c
+}
+
+// WithReplayer serves tool calls from a file written by WithRecorder
+// instead of calling the API, matching on tool name and arguments. Calls
+// with no recording fail with ErrNoRecording.
+func (c *Config) WithReplayer(path string) *Config {
+	c.ReplayPath = path
+	return c
+}
+
+// WithRawResponsePassthrough returns upstream response bodies verbatim
+// instead of re-indenting JSON
+func (c *Config) WithRawResponsePassthrough(enabled bool) *Config {
+	c.RawResponsePassthrough = enabled
+	return c
+}
+
+// WithResponseFormatting sets how JSON responses are rendered as tool
+// content: ResponseFormatCompact (the default) minifies them to save
+// tokens, ResponseFormatPretty re-indents them, and ResponseFormatRaw
+// passes bodies through untouched
+func (c *Config) WithResponseFormatting(format ResponseFormat) *Config {
+	c.ResponseFormat = format
+	return c
+}
+
+// WithIncludeResponseHeaders returns the named response headers, such as
+// X-RateLimit-Remaining, Link or ETag, with each tool result. The content
+// becomes a ResponseEnvelope holding the headers and the body. Pass "*" for
+// every header; off by default to save tokens.
+func (c *Config) WithIncludeResponseHeaders(headers []string) *Config {
+	c.IncludeResponseHeaders = headers
+	return c
+}
+
+// WithResponseEnvelope wraps every successful (2xx) tool result in a
+// ResultEnvelope, {"status": 200, "contentType": ..., "data": <body>}, so
+// array, object, text and empty responses all share one shape. Headers
+// selected by WithIncludeResponseHeaders are added to it. Off by default,
+// returning bodies as they are.
+func (c *Config) WithResponseEnvelope(enabled bool) *Config {
+	c.ResponseEnvelope = enabled
+	return c
+}
+
+// WithNDJSONArrays renders JSON array responses as newline-delimited JSON
+// (one item per line), which streaming consumers can process incrementally
+func (c *Config) WithNDJSONArrays(enabled bool) *Config {
+	c.NDJSONArrays = enabled
+	return c
+}
+
+// WithApplyDefaults fills request body and form fields the caller left out
+// with the `default` values declared in the spec, including inside nested
+// objects. Values the caller set are never overridden. (The MCP SDK already
+// completes tools/call bodies from the input schema; this also covers form
+// fields and calls made through Server.CallTool or the APIExecutor.)
+func (c *Config) WithApplyDefaults(enabled bool) *Config {
+	c.ApplyDefaults = enabled
+	return c
+}
+
+// WithParamCoercion converts string arguments such as "5" or "true" to
+// the integer, number or boolean type the spec declares for the parameter
+// or body field, for clients that send every argument as a string. Tool
+// input schemas then also accept strings for those fields.
+func (c *Config) WithParamCoercion(enabled bool) *Config {
+	c.ParamCoercion = enabled
+	return c
+}
+
+// WithMaxRequestBytes rejects tool calls whose marshaled request body
+// exceeds n bytes, before anything is sent upstream. Zero disables the check.
+func (c *Config) WithMaxRequestBytes(n int64) *Config {
+	c.MaxRequestBytes = n
+	return c
+}
+
+// WithRequestTransform runs fn on every tool call's arguments before the
+// request is built. fn may add, remove or override arguments, e.g. to inject
+// a tenant ID the client cannot change. An error fails the call.
+//
+// supplied names the arguments fn fills in itself. They are dropped from
+// the tools' required arguments, so a call that omits them is not rejected
+// before fn runs; the arguments are still validated once fn has run.
+// StrictParams checks the arguments the client sent, so fn may also add
+// arguments the spec does not declare.
+func (c *Config) WithRequestTransform(fn func(toolName, method, path string, args map[string]interface{}) error, supplied ...string) *Config {
+	c.RequestTransform = fn
+	c.RequestTransformArgs = supplied
+	return c
+}
+
+// WithResponseTransform rewrites upstream response bodies before they reach
+// the client, e.g. to strip internal fields from certain tools' results. An
+// error returned by fn is reported as the tool call's error.
+func (c *Config) WithResponseTransform(fn func(toolName string, status int, body []byte) ([]byte, error)) *Config {
+	c.ResponseTransform = fn
+	return c
+}
+
+// WithWriteSummaries makes POST/PUT/PATCH/DELETE tools return a short
+// summary (status, id, name) instead of the echoed resource. The full body
+// stays available in the result metadata and structured content.
+func (c *Config) WithWriteSummaries(enabled bool) *Config {
+	c.SummarizeWrites = enabled
+	return c
+}
+
+// WithNonErrorCodes returns responses with the given status codes as normal
+// tool results rather than errors, for APIs where e.g. 404 means "not
+// found" or 409 an idempotent conflict that the model should reason about.
+// The result carries the response body as for any other success.
+func (c *Config) WithNonErrorCodes(codes []int) *Config {
+	c.NonErrorCodes = append(c.NonErrorCodes, codes...)
+	return c
+}
+
+// isErrorStatus reports whether a response status makes the tool result an
+// error
+func (c *Config) isErrorStatus(statusCode int) bool {
+	if statusCode < 400 {
+		return false
+	}
+	for _, code := range c.NonErrorCodes {
+		if code == statusCode {
+			return false
+		}
+	}
+	return true
+}
+
+// WithPaginationAliases lets agents paginate every list operation with the
+// same limit/offset/page arguments. They are mapped onto each operation's
+// own query parameters, detected from common names (maxResults, per_page,
+// skip, ...) unless an explicit mapping is given for the tool.
+func (c *Config) WithPaginationAliases(overrides map[string]PaginationParams) *Config {
+	c.PaginationAliases = true
+	c.PaginationOverrides = overrides
+	return c
+}
+
+// WithAutoPagination lets the named tools (keys are tool names) fetch all
+// pages of a list in one call. Each tool gains an "allPages" argument; when
+// it is true the next-page links described by its PageFollow are followed
+// and the items of every page are returned as one array.
+func (c *Config) WithAutoPagination(follow map[string]PageFollow) *Config {
+	c.PageFollow = follow
+	return c
+}
+
+// WithTracer enables OpenTelemetry spans around tool invocations and
+// upstream HTTP calls. Tracing is disabled when no tracer is set. Only the
+// OpenTelemetry API is required; the tracer comes from whichever SDK the
+// application configures.
+func (c *Config) WithTracer(tracer trace.Tracer) *Config {
+	c.Tracer = tracer
+	return c
+}
+
+// WithMetrics records call volume, latency and errors per tool. Use
+// NewMetrics for a built-in Prometheus-format collector, which the HTTP
+// transport then serves at /metrics.
+func (c *Config) WithMetrics(collector MetricsCollector) *Config {
+	c.Metrics = collector
+	return c
+}
+
+// WithHTTPMiddleware wraps every request the HTTP transport serves, e.g.
+// for logging, auth or rate limiting. The first middleware is outermost and
+// sees the request first; repeated calls append to the chain.
+func (c *Config) WithHTTPMiddleware(middleware ...func(http.Handler) http.Handler) *Config {
+	c.HTTPMiddleware = append(c.HTTPMiddleware, middleware...)
+	return c
+}
+
+// exposesOperation reports whether op exists and filter lets it through as
+// a tool; a nil filter lets every operation through. Tool registration and
+// every tool listing check operations through it, so they agree on the set.
+func exposesOperation(filter *APIFilter, method, path string, op *spec.Operation) bool {
+	return op != nil && !filter.ShouldExcludeOperation(method, path, op)
+}
+
 // ShouldExcludeOperation checks if an operation should be excluded from tool conversion
 func (f *APIFilter) ShouldExcludeOperation(method, path string, operation *spec.Operation) bool {
 	if f == nil {
@@ -246,6 +1161,23 @@ func (f *APIFilter) ShouldExcludeOperation(method, path string, operation *spec.
 		}
 	}
 
+	// Exclude by path regexp match. New compiles the expressions and
+	// rejects invalid ones; a filter used without New compiles them per
+	// call and fails closed on an invalid one.
+	regexps := f.excludePathRegexps
+	if regexps == nil && len(f.ExcludePathRegexps) > 0 {
+		compiled, err := compilePathRegexps(f.ExcludePathRegexps)
+		if err != nil {
+			return true
+		}
+		regexps = compiled
+	}
+	for _, re := range regexps {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+
 	// Exclude by operation ID
 	if operation.ID != "" {
 		for _, excludeID := range f.ExcludeOperationIDs {
@@ -255,6 +1187,11 @@ func (f *APIFilter) ShouldExcludeOperation(method, path string, operation *spec.
 		}
 	}
 
+	// Exclude deprecated operations
+	if f.ExcludeDeprecated && operation.Deprecated {
+		return true
+	}
+
 	// Exclude by HTTP method
 	for _, excludeMethod := range f.ExcludeMethods {
 		if strings.EqualFold(method, excludeMethod) {
@@ -273,9 +1210,45 @@ func (f *APIFilter) ShouldExcludeOperation(method, path string, operation *spec.
 		}
 	}
 
+	// Exclude operations the custom rule rejects
+	if f.OperationFilter != nil && !f.OperationFilter(method, path, operation) {
+		return true
+	}
+
+	// Exclude tools dropped by MaxTools truncation
+	if f.limitedToolNames != nil && !f.limitedToolNames[GenerateToolName(method, path, operation)] {
+		return true
+	}
+
 	return false
 }
 
+// compiled returns a copy of the filter with ExcludePathRegexps compiled,
+// reporting an invalid expression. New installs the copy, so the filter
+// the caller passed is never written and matching never compiles.
+func (f *APIFilter) compiled() (*APIFilter, error) {
+	regexps, err := compilePathRegexps(f.ExcludePathRegexps)
+	if err != nil {
+		return nil, err
+	}
+	compiled := *f
+	compiled.excludePathRegexps = regexps
+	return &compiled, nil
+}
+
+// compilePathRegexps compiles exclude path regular expressions
+func compilePathRegexps(exprs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude path regexp %q: %w", expr, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
 // matchesPattern checks if a path matches a pattern with wildcard support
 func matchesPattern(path, pattern string) bool {
 	// Simple wildcard matching using filepath.Match
@@ -0,0 +1,291 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the structure of a config file loaded by LoadConfigFile.
+// Keys use snake_case; JSON files use the same keys.
+type fileConfig struct {
+	Swagger           string   `yaml:"swagger"`     // Spec file, relative to the config file
+	SwaggerURL        string   `yaml:"swagger_url"` // Spec URL, used when swagger is unset
+	AllowedRefHosts   []string `yaml:"allowed_ref_hosts"`
+	AllowedHosts      []string `yaml:"allowed_hosts"` // Hosts API calls may reach
+	AllowInsecureHTTP bool     `yaml:"allow_insecure_http"`
+
+	APIBase   string `yaml:"api_base"`
+	APIKey    string `yaml:"api_key"`
+	BasicAuth *struct {
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	} `yaml:"basic_auth"`
+	OAuth2 *struct {
+		TokenURL     string   `yaml:"token_url"`
+		ClientID     string   `yaml:"client_id"`
+		ClientSecret string   `yaml:"client_secret"`
+		Scopes       []string `yaml:"scopes"`
+	} `yaml:"oauth2"`
+
+	Name        string `yaml:"name"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description"`
+
+	RichDescriptions bool `yaml:"rich_descriptions"`
+
+	Transport *struct {
+		Type string `yaml:"type"` // "stdio" (default), "http" or "stdio+http"
+		Host string `yaml:"host"`
+		Port int    `yaml:"port"`
+		Path string `yaml:"path"`
+	} `yaml:"transport"`
+
+	Filter *struct {
+		ExcludePaths            []string `yaml:"exclude_paths"`
+		ExcludePathPatterns     []string `yaml:"exclude_path_patterns"`
+		ExcludePathRegexps      []string `yaml:"exclude_path_regexps"`
+		ExcludeOperationIDs     []string `yaml:"exclude_operations"`
+		ExcludeMethods          []string `yaml:"exclude_methods"`
+		ExcludeTags             []string `yaml:"exclude_tags"`
+		IncludeOnlyPaths        []string `yaml:"include_only_paths"`
+		IncludeOnlyOperationIDs []string `yaml:"include_only_operations"`
+		ExcludeDeprecated       bool     `yaml:"exclude_deprecated"`
+	} `yaml:"filter"`
+
+	Headers            map[string]string `yaml:"headers"`
+	QueryParams        map[string]string `yaml:"query_params"`
+	Cookies            map[string]string `yaml:"cookies"`
+	CookieParams       bool              `yaml:"cookie_params"`
+	PerCallAuth        bool              `yaml:"per_call_auth"`
+	AuthHeader         string            `yaml:"auth_header"`     // both (default), x-api-key, bearer or a header name
+	Timeout            time.Duration     `yaml:"timeout"`         // e.g. "30s"
+	ConnectTimeout     time.Duration     `yaml:"connect_timeout"` // e.g. "5s"
+	ProxyURL           string            `yaml:"proxy_url"`
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify"`
+	UserAgent          string            `yaml:"user_agent"`
+	ContentType        string            `yaml:"content_type"`
+	Accept             string            `yaml:"accept"`
+	AcceptPolicy       string            `yaml:"accept_policy"` // negotiate (default), fixed or omit
+	StripPathPrefix    string            `yaml:"strip_path_prefix"`
+	AddPathPrefix      string            `yaml:"add_path_prefix"`
+	IdempotencyHeader  string            `yaml:"idempotency_header"`   // e.g. Idempotency-Key
+	BooleanQueryFormat string            `yaml:"boolean_query_format"` // true-false (default), 1-0 or yes-no
+
+	OperationTimeouts map[string]time.Duration `yaml:"operation_timeouts"` // By tool name or path
+
+	DryRun                 bool     `yaml:"dry_run"`
+	ResponseFormat         string   `yaml:"response_format"`     // compact (default), pretty or raw
+	ResponseValidation     string   `yaml:"response_validation"` // warn or strict
+	ApplyDefaults          bool     `yaml:"apply_defaults"`
+	ParamCoercion          bool     `yaml:"param_coercion"`
+	MaxRequestBytes        int64    `yaml:"max_request_bytes"`
+	MaxConcurrency         int      `yaml:"max_concurrency"`
+	MaxUpstreamAttempts    int      `yaml:"max_upstream_attempts"`
+	IncludeResponseHeaders []string `yaml:"include_response_headers"` // e.g. [ETag, Link], or ["*"]
+	ResponseEnvelope       bool     `yaml:"response_envelope"`
+
+	ResponseCache *struct {
+		TTL        time.Duration `yaml:"ttl"`
+		MaxEntries int           `yaml:"max_entries"`
+	} `yaml:"response_cache"`
+
+	EventStreams *struct {
+		MaxDuration time.Duration `yaml:"max_duration"`
+		MaxBytes    int64         `yaml:"max_bytes"`
+	} `yaml:"event_streams"`
+}
+
+// LoadConfigFile builds a Config from a YAML or JSON file. Settings left
+// out of the file keep their DefaultConfig values, and the spec named by
+// swagger or swagger_url is loaded. Unknown keys are rejected so typos
+// don't silently drop settings.
+func LoadConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file fileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	config := DefaultConfig().
+		WithAPIConfig(file.APIBase, file.APIKey).
+		WithAllowedRefHosts(file.AllowedRefHosts...)
+
+	if file.BasicAuth != nil {
+		config.WithBasicAuth(file.BasicAuth.Username, file.BasicAuth.Password)
+	}
+	if o := file.OAuth2; o != nil {
+		config.WithOAuth2ClientCredentials(o.TokenURL, o.ClientID, o.ClientSecret, o.Scopes)
+	}
+	if file.Name != "" {
+		config.Name = file.Name
+	}
+	if file.Version != "" {
+		config.Version = file.Version
+	}
+	if file.Description != "" {
+		config.Description = file.Description
+	}
+
+	config.RichDescriptions = file.RichDescriptions
+
+	if t := file.Transport; t != nil {
+		switch t.Type {
+		case "", "stdio":
+		case "http", "stdio+http":
+			host, urlPath := t.Host, t.Path
+			if host == "" {
+				host = "localhost"
+			}
+			if urlPath == "" {
+				urlPath = "/mcp"
+			}
+			if t.Type == "http" {
+				config.WithHTTPTransport(t.Port, host, urlPath)
+			} else {
+				config.WithTransportStdioAndHTTP(t.Port, host, urlPath)
+			}
+		default:
+			return nil, fmt.Errorf("invalid transport type %q in %s: expected stdio, http or stdio+http", t.Type, path)
+		}
+	}
+
+	if f := file.Filter; f != nil {
+		config.WithAPIFilter(&APIFilter{
+			ExcludePaths:            f.ExcludePaths,
+			ExcludePathPatterns:     f.ExcludePathPatterns,
+			ExcludePathRegexps:      f.ExcludePathRegexps,
+			ExcludeOperationIDs:     f.ExcludeOperationIDs,
+			ExcludeMethods:          f.ExcludeMethods,
+			ExcludeTags:             f.ExcludeTags,
+			IncludeOnlyPaths:        f.IncludeOnlyPaths,
+			IncludeOnlyOperationIDs: f.IncludeOnlyOperationIDs,
+			ExcludeDeprecated:       f.ExcludeDeprecated,
+		})
+	}
+
+	if len(file.Headers) > 0 {
+		config.WithHeaders(file.Headers)
+	}
+	if len(file.QueryParams) > 0 {
+		config.WithDefaultQueryParams(file.QueryParams)
+	}
+	if len(file.Cookies) > 0 {
+		config.WithCookies(file.Cookies)
+	}
+	config.CookieParams = file.CookieParams
+	config.PerCallAuth = file.PerCallAuth
+	switch mode := AuthHeaderMode(file.AuthHeader); {
+	case mode.customHeader() == "" || validHeaderName(file.AuthHeader):
+		config.AuthHeaderMode = mode
+	default:
+		return nil, fmt.Errorf("invalid auth_header %q in %s: expected both, x-api-key, bearer or a header name", file.AuthHeader, path)
+	}
+	config.Timeout = file.Timeout
+	config.ConnectTimeout = file.ConnectTimeout
+	if len(file.OperationTimeouts) > 0 {
+		config.WithOperationTimeouts(file.OperationTimeouts)
+	}
+	config.ProxyURL = file.ProxyURL
+	config.AllowedHosts = file.AllowedHosts
+	config.AllowInsecureHTTP = file.AllowInsecureHTTP
+	config.InsecureSkipVerify = file.InsecureSkipVerify
+	config.UserAgent = file.UserAgent
+	config.ContentType = file.ContentType
+	config.Accept = file.Accept
+	switch policy := AcceptPolicy(file.AcceptPolicy); policy {
+	case "", AcceptNegotiate, AcceptFixed, AcceptOmit:
+		config.AcceptPolicy = policy
+	default:
+		return nil, fmt.Errorf("invalid accept_policy %q in %s: expected negotiate, fixed or omit", file.AcceptPolicy, path)
+	}
+	config.StripPathPrefix = file.StripPathPrefix
+	config.AddPathPrefix = file.AddPathPrefix
+	if file.IdempotencyHeader != "" {
+		config.WithIdempotency(file.IdempotencyHeader)
+	}
+	switch format := BooleanQueryFormat(file.BooleanQueryFormat); format {
+	case "", BooleanTrueFalse, BooleanOneZero, BooleanYesNo:
+		config.BooleanQueryFormat = format
+	default:
+		return nil, fmt.Errorf("invalid boolean_query_format %q in %s: expected true-false, 1-0 or yes-no", file.BooleanQueryFormat, path)
+	}
+	config.DryRun = file.DryRun
+	switch format := ResponseFormat(file.ResponseFormat); format {
+	case "", ResponseFormatCompact, ResponseFormatPretty, ResponseFormatRaw:
+		config.ResponseFormat = format
+	default:
+		return nil, fmt.Errorf("invalid response_format %q in %s: expected compact, pretty or raw", file.ResponseFormat, path)
+	}
+	switch file.ResponseValidation {
+	case "":
+	case "warn":
+		config.ResponseValidation = true
+	case "strict":
+		config.StrictResponseValidation = true
+	default:
+		return nil, fmt.Errorf("invalid response_validation %q in %s: expected warn or strict", file.ResponseValidation, path)
+	}
+	config.ApplyDefaults = file.ApplyDefaults
+	config.ParamCoercion = file.ParamCoercion
+	config.MaxRequestBytes = file.MaxRequestBytes
+	config.MaxConcurrency = file.MaxConcurrency
+	config.MaxUpstreamAttempts = file.MaxUpstreamAttempts
+	config.IncludeResponseHeaders = file.IncludeResponseHeaders
+	config.ResponseEnvelope = file.ResponseEnvelope
+	if s := file.EventStreams; s != nil {
+		config.WithStreamLimits(s.MaxDuration, s.MaxBytes)
+	}
+	if c := file.ResponseCache; c != nil {
+		config.WithResponseCache(c.TTL, c.MaxEntries)
+	}
+
+	switch {
+	case file.Swagger != "":
+		specPath := file.Swagger
+		if !filepath.IsAbs(specPath) {
+			specPath = filepath.Join(filepath.Dir(path), specPath)
+		}
+		data, err := readFile(specPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read swagger file: %w", err)
+		}
+		config.WithSwaggerData(data).WithSpecLocation(specPath)
+	case file.SwaggerURL != "":
+		data, err := FetchSwaggerFromURL(file.SwaggerURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch swagger from URL: %w", err)
+		}
+		config.WithSwaggerData(data).WithSpecLocation(file.SwaggerURL)
+	}
+
+	return config, nil
+}
+
+// validHeaderName reports whether name is a valid HTTP header field name:
+// a non-empty token of the characters RFC 9110 allows
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("!#$%&'*+-.^_`|~", r):
+		default:
+			return false
+		}
+	}
+	return true
+}
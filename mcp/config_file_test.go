@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleConfigYAML = `
+swagger: specs/petstore.json
+allowed_ref_hosts: [schemas.example.com]
+api_base: https://api.example.com/v1
+api_key: secret-key
+basic_auth:
+  username: bot
+  password: hunter2
+oauth2:
+  token_url: https://auth.example.com/token
+  client_id: pets-client
+  scopes: [pets:read]
+name: pets
+version: v2.0.0
+description: Pet store tools
+transport:
+  type: http
+  port: 8127
+  path: /pets-mcp
+filter:
+  exclude_paths: [/admin]
+  exclude_path_patterns: ["/internal/*"]
+  exclude_path_regexps: ["^/debug/"]
+  exclude_operations: [deletePet]
+  exclude_methods: [DELETE]
+  exclude_tags: [experimental]
+  include_only_paths: [/pets]
+  include_only_operations: [listPets]
+  exclude_deprecated: true
+headers:
+  X-Tenant: acme
+cookies:
+  session: abc123
+timeout: 45s
+connect_timeout: 3s
+user_agent: pet-bot/1.0
+content_type: application/vnd.api+json
+accept_policy: omit
+max_request_bytes: 1048576
+response_cache:
+  ttl: 1m
+  max_entries: 50
+`
+
+func TestLoadConfigFile_YAML(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"specs/petstore.json": executorTestSwagger,
+		"server.yaml":         sampleConfigYAML,
+	})
+
+	config, err := LoadConfigFile(filepath.Join(dir, "server.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+
+	if config.APIBaseURL != "https://api.example.com/v1" || config.APIKey != "secret-key" {
+		t.Errorf("API config = %q, %q", config.APIBaseURL, config.APIKey)
+	}
+	if config.BasicAuthUsername != "bot" || config.BasicAuthPassword != "hunter2" {
+		t.Errorf("basic auth = %q, %q", config.BasicAuthUsername, config.BasicAuthPassword)
+	}
+	wantOAuth2 := &OAuth2ClientCredentials{TokenURL: "https://auth.example.com/token", ClientID: "pets-client", Scopes: []string{"pets:read"}}
+	if !reflect.DeepEqual(config.OAuth2, wantOAuth2) {
+		t.Errorf("oauth2 = %+v", config.OAuth2)
+	}
+	if config.Name != "pets" || config.Version != "v2.0.0" || config.Description != "Pet store tools" {
+		t.Errorf("server info = %q, %q, %q", config.Name, config.Version, config.Description)
+	}
+
+	transport, ok := config.Transport.(*HTTPTransport)
+	if !ok {
+		t.Fatalf("expected an HTTP transport, got %T", config.Transport)
+	}
+	if transport.Port != 8127 || transport.Host != "localhost" || transport.Path != "/pets-mcp" {
+		t.Errorf("transport = %+v", transport)
+	}
+
+	wantFilter := &APIFilter{
+		ExcludePaths:            []string{"/admin"},
+		ExcludePathPatterns:     []string{"/internal/*"},
+		ExcludePathRegexps:      []string{"^/debug/"},
+		ExcludeOperationIDs:     []string{"deletePet"},
+		ExcludeMethods:          []string{"DELETE"},
+		ExcludeTags:             []string{"experimental"},
+		IncludeOnlyPaths:        []string{"/pets"},
+		IncludeOnlyOperationIDs: []string{"listPets"},
+		ExcludeDeprecated:       true,
+	}
+	if !reflect.DeepEqual(config.Filter, wantFilter) {
+		t.Errorf("filter = %+v, want %+v", config.Filter, wantFilter)
+	}
+
+	if config.Headers["X-Tenant"] != "acme" || config.Cookies["session"] != "abc123" {
+		t.Errorf("headers = %v, cookies = %v", config.Headers, config.Cookies)
+	}
+	if config.Timeout != 45*time.Second || config.ConnectTimeout != 3*time.Second {
+		t.Errorf("timeout = %v, connect timeout = %v", config.Timeout, config.ConnectTimeout)
+	}
+	if config.UserAgent != "pet-bot/1.0" || config.ContentType != "application/vnd.api+json" || config.MaxRequestBytes != 1<<20 {
+		t.Errorf("client options = %q, %q, %d", config.UserAgent, config.ContentType, config.MaxRequestBytes)
+	}
+	if config.AcceptPolicy != AcceptOmit {
+		t.Errorf("accept policy = %q, want omit", config.AcceptPolicy)
+	}
+	if config.ResponseCacheTTL != time.Minute || config.ResponseCacheMaxEntries != 50 {
+		t.Errorf("response cache = %v, %d", config.ResponseCacheTTL, config.ResponseCacheMaxEntries)
+	}
+	if !reflect.DeepEqual(config.AllowedRefHosts, []string{"schemas.example.com"}) {
+		t.Errorf("allowed ref hosts = %v", config.AllowedRefHosts)
+	}
+
+	// The spec is read relative to the config file
+	if string(config.SwaggerData) != executorTestSwagger {
+		t.Error("expected the spec to be loaded from the config file's directory")
+	}
+	if config.SpecLocation != filepath.Join(dir, "specs/petstore.json") {
+		t.Errorf("spec location = %q", config.SpecLocation)
+	}
+}
+
+func TestLoadConfigFile_JSON(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"petstore.json": executorTestSwagger,
+		"server.json": `{
+  "swagger": "petstore.json",
+  "api_base": "https://api.example.com",
+  "filter": {"exclude_methods": ["DELETE", "PATCH"]},
+  "headers": {"X-Api-Version": "2"},
+  "timeout": "10s"
+}`,
+	})
+
+	config, err := LoadConfigFile(filepath.Join(dir, "server.json"))
+	if err != nil {
+		t.Fatalf("LoadConfigFile failed: %v", err)
+	}
+	if config.APIBaseURL != "https://api.example.com" || config.Timeout != 10*time.Second {
+		t.Errorf("config = %q, %v", config.APIBaseURL, config.Timeout)
+	}
+	if !reflect.DeepEqual(config.Filter.ExcludeMethods, []string{"DELETE", "PATCH"}) {
+		t.Errorf("filter = %+v", config.Filter)
+	}
+	if _, ok := config.Transport.(*StdioTransport); !ok {
+		t.Errorf("expected the default stdio transport, got %T", config.Transport)
+	}
+
+	server, err := New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, name := range server.ListTools() {
+		if strings.HasPrefix(name, "delete") {
+			t.Errorf("filter from the config file not applied: %v", server.ListTools())
+		}
+	}
+}
+
+func TestLoadConfigFile_RejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.yaml")
+	if err := os.WriteFile(path, []byte("api_bsae: https://api.example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfigFile(path); err == nil || !strings.Contains(err.Error(), "api_bsae") {
+		t.Fatalf("expected an unknown key error, got %v", err)
+	}
+}
+
+func TestLoadConfigFile_RejectsInvalidValues(t *testing.T) {
+	tests := []struct {
+		content string
+		wantErr string
+	}{
+		{"auth_header: X Api Key\n", `invalid auth_header "X Api Key"`},
+		{"boolean_query_format: on-off\n", `invalid boolean_query_format "on-off"`},
+		{"response_format: yaml\n", `invalid response_format "yaml"`},
+		{"accept_policy: always\n", `invalid accept_policy "always"`},
+		{"response_validation: lenient\n", `invalid response_validation "lenient"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantErr, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "server.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadConfigFile(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Standard modes in any case, and custom header names, are accepted
+	for _, mode := range []string{"Bearer", "X-API-Key", "X-Auth-Token"} {
+		path := filepath.Join(t.TempDir(), "server.yaml")
+		if err := os.WriteFile(path, []byte("auth_header: "+mode+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfigFile(path); err != nil {
+			t.Errorf("auth_header %q: %v", mode, err)
+		}
+	}
+}
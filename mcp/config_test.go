@@ -2,6 +2,8 @@ package mcp
 
 import (
 	"context"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/go-openapi/spec"
@@ -260,3 +262,274 @@ func TestConfig_ChainedMethods(t *testing.T) {
 		t.Error("Chained methods: HTTPTransport Path not set correctly")
 	}
 }
+
+func TestAPIFilter_ExcludePathRegexps(t *testing.T) {
+	filter := &APIFilter{
+		ExcludePathPatterns: []string{"/admin/*"},
+		ExcludePathRegexps:  []string{"/internal/", `^/v[0-9]+/debug$`},
+	}
+	op := &spec.Operation{}
+
+	tests := []struct {
+		path    string
+		exclude bool
+	}{
+		{"/internal/health", true},
+		{"/api/v1/internal/users/{id}", true},
+		{"/v2/debug", true},
+		{"/v2/debug/logs", false},
+		{"/api/v2/debug", false},
+		{"/admin/users", true}, // glob patterns still apply
+		{"/users", false},
+	}
+	for _, tt := range tests {
+		if got := filter.ShouldExcludeOperation("GET", tt.path, op); got != tt.exclude {
+			t.Errorf("ShouldExcludeOperation(%q) = %v, want %v", tt.path, got, tt.exclude)
+		}
+	}
+}
+
+func TestNew_InvalidExcludePathRegexp(t *testing.T) {
+	config := DefaultConfig().
+		WithSwaggerData([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "paths": {}}`)).
+		WithAPIFilter(&APIFilter{ExcludePathRegexps: []string{"/internal/(unclosed"}})
+
+	_, err := New(config)
+	if err == nil || !strings.Contains(err.Error(), "invalid exclude path regexp") {
+		t.Fatalf("expected invalid regexp error, got %v", err)
+	}
+}
+
+func TestAPIFilter_ExcludePathRegexpsConcurrent(t *testing.T) {
+	filter := &APIFilter{ExcludePathRegexps: []string{"^/pets/"}}
+	server, err := New(DefaultConfig().WithSwaggerData([]byte(executorTestSwagger)).WithAPIFilter(filter))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if filter.excludePathRegexps != nil {
+		t.Error("New wrote the compiled expressions into the caller's filter")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !server.config.Filter.ShouldExcludeOperation("GET", "/pets/{id}", &spec.Operation{}) {
+				t.Error("expected /pets/{id} to be excluded")
+			}
+			if filter.ShouldExcludeOperation("GET", "/owners", &spec.Operation{}) {
+				t.Error("expected /owners to be kept")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Without New an invalid expression cannot be reported, so nothing is exposed
+	invalid := &APIFilter{ExcludePathRegexps: []string{"(unclosed"}}
+	if !invalid.ShouldExcludeOperation("GET", "/owners", &spec.Operation{}) {
+		t.Error("expected an invalid expression to exclude the operation")
+	}
+}
+
+func TestNew_SpecWithoutTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{
+			name:    "empty paths",
+			config:  DefaultConfig().WithSwaggerData([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "paths": {}}`)),
+			wantErr: "spec defines no paths",
+		},
+		{
+			name:    "paths without operations",
+			config:  DefaultConfig().WithSwaggerData([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "1"}, "paths": {"/pets": {}}}`)),
+			wantErr: "spec defines 1 paths but no GET, POST, PUT, DELETE, PATCH, HEAD or OPTIONS operations",
+		},
+		{
+			name: "all operations filtered out",
+			config: DefaultConfig().
+				WithSwaggerData([]byte(executorTestSwagger)).
+				WithExcludeMethods("GET", "POST", "PUT", "DELETE"),
+			wantErr: "all 4 operations are excluded by the API filter",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNew_MalformedSpecs(t *testing.T) {
+	var unexpanded spec.Swagger
+	if err := unexpanded.UnmarshalJSON([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "t", "version": "1"},
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "parameters": [{"$ref": "#/parameters/Missing"}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`)); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{
+			name:    "no paths object",
+			config:  DefaultConfig().WithSwaggerData([]byte(`{"swagger": "2.0", "info": {"title": "t", "version": "1"}}`)),
+			wantErr: "spec defines no paths",
+		},
+		{
+			name: "broken body schema ref",
+			config: DefaultConfig().WithSwaggerData([]byte(`{
+  "swagger": "2.0",
+  "info": {"title": "t", "version": "1"},
+  "paths": {
+    "/pets": {"post": {"operationId": "createPet", "parameters": [{"in": "body", "name": "body", "schema": {"$ref": "#/definitions/Missing"}}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`)),
+			wantErr: `unresolved $ref "#/definitions/Missing" at paths./pets.post.parameters[0].schema`,
+		},
+		{
+			name:    "broken parameter ref in an unexpanded spec",
+			config:  DefaultConfig().WithSwaggerSpec(&unexpanded),
+			wantErr: `GET /pets: parameter 0 has unresolved $ref "#/parameters/Missing"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// The lower-level constructors take a spec without validating it and
+	// must not panic on one without paths
+	server := NewSwaggerMCPServer("http://localhost", &spec.Swagger{}, "")
+	server.RegisterTools()
+	if len(server.tools) != 0 {
+		t.Errorf("registered %d tools for a spec without paths", len(server.tools))
+	}
+	if _, _, op := FindOperationByToolName("listpets", &spec.Swagger{}, nil); op != nil {
+		t.Errorf("found an operation in a spec without paths")
+	}
+}
+
+func TestNew_MaxTools(t *testing.T) {
+	_, err := New(DefaultConfig().WithSwaggerData([]byte(executorTestSwagger)).WithMaxTools(3))
+	if err == nil || !strings.Contains(err.Error(), "spec yields 4 tools, more than the limit of 3") {
+		t.Fatalf("expected tool limit error, got %v", err)
+	}
+
+	// Filtering below the limit is fine
+	if _, err := New(DefaultConfig().
+		WithSwaggerData([]byte(executorTestSwagger)).
+		WithExcludeMethods("DELETE").
+		WithMaxTools(3)); err != nil {
+		t.Fatalf("filtered spec should fit the limit: %v", err)
+	}
+
+	server, err := New(DefaultConfig().
+		WithSwaggerData([]byte(executorTestSwagger)).
+		WithMaxTools(2).
+		WithTruncateTools(true))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	want := []string{"createpet", "deletepet"}
+	if names := server.ListTools(); strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("truncated tools = %v, want %v", names, want)
+	}
+	if registered := len(server.mcp.tools); registered != 2 {
+		t.Errorf("registered %d tools, want 2", registered)
+	}
+	if _, _, err := server.CallTool(context.Background(), "updatepet", nil); err == nil {
+		t.Error("truncated tool should not be callable")
+	}
+
+	// The limit is the server's own: a filter shared with another server
+	// is left as given
+	filter := &APIFilter{ExcludeTags: []string{"internal"}}
+	if _, err := New(DefaultConfig().
+		WithSwaggerData([]byte(executorTestSwagger)).
+		WithAPIFilter(filter).
+		WithMaxTools(1).
+		WithTruncateTools(true)); err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	unlimited, err := New(DefaultConfig().WithSwaggerData([]byte(executorTestSwagger)).WithAPIFilter(filter))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if registered := len(unlimited.mcp.tools); registered != 4 {
+		t.Errorf("shared filter: registered %d tools, want 4", registered)
+	}
+
+	// Reloading applies the limit to the new spec
+	if err := server.ReloadSpec([]byte(executorTestSwagger)); err != nil {
+		t.Fatalf("ReloadSpec failed: %v", err)
+	}
+	if registered := len(server.mcp.tools); registered != 2 {
+		t.Errorf("after reload: registered %d tools, want 2", registered)
+	}
+
+	// Grouped resource tools count once: the four pet operations are one tool
+	grouped, err := New(DefaultConfig().
+		WithSwaggerData([]byte(executorTestSwagger)).
+		WithResourceGrouping(true).
+		WithMaxTools(1))
+	if err != nil {
+		t.Fatalf("grouped tools should fit the limit: %v", err)
+	}
+	if _, ok := grouped.mcp.tools["manage_pets"]; !ok || len(grouped.mcp.tools) != 1 {
+		t.Errorf("grouped tools = %v, want manage_pets only", grouped.mcp.tools)
+	}
+}
+
+func TestOperationFilter_CombinesWithDeclarativeRules(t *testing.T) {
+	swagger := `{
+		"swagger": "2.0",
+		"info": {"title": "t", "version": "1"},
+		"paths": {
+			"/pets": {
+				"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}},
+				"post": {"responses": {"201": {"description": "Created"}}},
+				"delete": {"operationId": "deletePets", "responses": {"204": {"description": "Deleted"}}}
+			}
+		}
+	}`
+	server, err := New(DefaultConfig().
+		WithSwaggerData([]byte(swagger)).
+		WithExcludeMethods("DELETE").
+		WithOperationFilter(func(method, path string, op *spec.Operation) bool {
+			return op.ID != ""
+		}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	want := "listpets"
+	if names := server.ListTools(); strings.Join(names, ",") != want {
+		t.Errorf("ListTools = %v, want [%s]", names, want)
+	}
+	if detailed := server.ListToolsDetailed(); len(detailed) != 1 || detailed[0].Name != want {
+		t.Errorf("ListToolsDetailed = %v, want only %s", detailed, want)
+	}
+	if registered := len(server.mcp.tools); registered != 1 {
+		t.Errorf("registered %d tools, want 1", registered)
+	}
+	if calls := server.GenerateExampleCalls(); len(calls) != 1 || calls[0].Tool != want {
+		t.Errorf("GenerateExampleCalls = %v, want only %s", calls, want)
+	}
+}
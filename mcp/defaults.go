@@ -0,0 +1,42 @@
+package mcp
+
+import "github.com/go-openapi/spec"
+
+// applyParameterDefaults fills omitted form fields in args and omitted
+// properties of a body object from the defaults declared in params
+func applyParameterDefaults(params []spec.Parameter, args map[string]interface{}, body interface{}) {
+	for _, param := range params {
+		switch param.In {
+		case "formData":
+			if _, exists := args[param.Name]; !exists && param.Default != nil {
+				args[param.Name] = param.Default
+			}
+		case "body":
+			if param.Schema != nil {
+				applySchemaDefaults(param.Schema, body)
+			}
+		}
+	}
+}
+
+// applySchemaDefaults sets missing properties of value from the defaults in
+// schema, descending into the nested objects and arrays value already holds
+func applySchemaDefaults(schema *spec.Schema, value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, property := range schema.Properties {
+			property := property
+			if existing, exists := v[name]; exists {
+				applySchemaDefaults(&property, existing)
+			} else if property.Default != nil {
+				v[name] = property.Default
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for _, item := range v {
+				applySchemaDefaults(schema.Items.Schema, item)
+			}
+		}
+	}
+}
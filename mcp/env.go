@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables read by Config.WithEnv. The API key is read
+// through EnvSecrets, which maps the api_key secret to MCP_API_KEY.
+const (
+	EnvAPIBase = "MCP_API_BASE"
+	EnvAPIKey  = "MCP_API_KEY"
+)
+
+// LoadEnvFile reads KEY=VALUE pairs from a .env file into the process
+// environment. Variables that are already set are left untouched, so real
+// environment values always win over the file. Blank lines, # comments, an
+// optional "export " prefix and single or double quotes are supported.
+func LoadEnvFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open env file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, lineNum)
+		}
+		value = unquoteEnvValue(strings.TrimSpace(value))
+
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s:%d: failed to set %s: %w", path, lineNum, key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read env file: %w", err)
+	}
+	return nil
+}
+
+// unquoteEnvValue strips matching quotes (ignoring anything after the
+// closing quote), or a trailing # comment from an unquoted value
+func unquoteEnvValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			return value[1 : end+1]
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// WithEnv fills API settings that are still unset from the environment
+// (MCP_API_BASE, MCP_API_KEY). Values already set on the config, e.g. from
+// command-line flags, take precedence.
+func (c *Config) WithEnv() *Config {
+	if c.APIBaseURL == "" {
+		c.APIBaseURL = os.Getenv(EnvAPIBase)
+	}
+	if c.APIKey == "" {
+		c.APIKey, _ = EnvSecrets{}.Get(SecretAPIKey)
+	}
+	return c
+}
@@ -0,0 +1,82 @@
+package mcp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// unsetEnv clears key for the duration of the test.
+func unsetEnv(t *testing.T, key string) {
+	t.Helper()
+	t.Setenv(key, "") // registers restoration of the original value
+	_ = os.Unsetenv(key)
+}
+
+func TestLoadEnvFile_FeedsConfig(t *testing.T) {
+	unsetEnv(t, EnvAPIBase)
+	unsetEnv(t, EnvAPIKey)
+	unsetEnv(t, "MCP_TEST_QUOTED")
+
+	path := filepath.Join(t.TempDir(), ".env")
+	content := `# upstream settings
+MCP_API_BASE=https://api.example.com/v1
+export MCP_API_KEY="s3cr3t value"
+
+MCP_TEST_QUOTED='single' # comment
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LoadEnvFile(path); err != nil {
+		t.Fatalf("LoadEnvFile failed: %v", err)
+	}
+
+	config := DefaultConfig().WithEnv()
+	if config.APIBaseURL != "https://api.example.com/v1" {
+		t.Errorf("APIBaseURL = %q, want https://api.example.com/v1", config.APIBaseURL)
+	}
+	if config.APIKey != "s3cr3t value" {
+		t.Errorf("APIKey = %q, want %q", config.APIKey, "s3cr3t value")
+	}
+	if got := os.Getenv("MCP_TEST_QUOTED"); got != "single" {
+		t.Errorf("MCP_TEST_QUOTED = %q, want single", got)
+	}
+}
+
+func TestLoadEnvFile_DoesNotOverrideEnvironment(t *testing.T) {
+	t.Setenv(EnvAPIKey, "from-environment")
+	unsetEnv(t, EnvAPIBase)
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("MCP_API_KEY=from-file\nMCP_API_BASE=https://file.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadEnvFile(path); err != nil {
+		t.Fatalf("LoadEnvFile failed: %v", err)
+	}
+
+	// Explicit config values win over both
+	config := DefaultConfig().WithAPIConfig("https://flag.example.com", "").WithEnv()
+	if config.APIKey != "from-environment" {
+		t.Errorf("APIKey = %q, want from-environment", config.APIKey)
+	}
+	if config.APIBaseURL != "https://flag.example.com" {
+		t.Errorf("APIBaseURL = %q, want https://flag.example.com", config.APIBaseURL)
+	}
+}
+
+func TestLoadEnvFile_Errors(t *testing.T) {
+	if err := LoadEnvFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("NOT_A_PAIR\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadEnvFile(path); err == nil {
+		t.Error("expected error for a malformed line")
+	}
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default bounds on how much of an event stream one tool call reads
+const (
+	defaultStreamMaxDuration = 10 * time.Second
+	defaultStreamMaxBytes    = 1 << 20
+)
+
+// StreamEvent is one server-sent event of a streamed response
+type StreamEvent struct {
+	Event string      `json:"event,omitempty"`
+	ID    string      `json:"id,omitempty"`
+	Data  interface{} `json:"data"` // Embedded as JSON when the data is JSON, else a string
+}
+
+// StreamResult is the response body returned for a text/event-stream
+// response: the complete events received before the stream ended or a
+// limit was reached
+type StreamResult struct {
+	Events    []StreamEvent `json:"events"`
+	Truncated bool          `json:"truncated,omitempty"` // A limit was reached before the stream ended
+}
+
+// isEventStream reports whether a response is a server-sent event stream
+func isEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// readEventStream reads an event stream until it ends, maxDuration passes
+// or maxBytes have been read, whichever comes first, and returns the events
+// received as a JSON StreamResult. Reading stops by closing body, so an
+// open stream can never block the call. Zero limits use the defaults.
+func readEventStream(body io.ReadCloser, maxDuration time.Duration, maxBytes int64) ([]byte, error) {
+	if maxDuration <= 0 {
+		maxDuration = defaultStreamMaxDuration
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultStreamMaxBytes
+	}
+
+	timer := time.AfterFunc(maxDuration, func() { _ = body.Close() })
+	data, err := io.ReadAll(io.LimitReader(body, maxBytes))
+	expired := !timer.Stop()
+	if err != nil && !expired {
+		return nil, err
+	}
+
+	result := StreamResult{
+		Events:    parseEventStream(string(data)),
+		Truncated: expired || int64(len(data)) >= maxBytes,
+	}
+	return json.Marshal(result)
+}
+
+// parseEventStream splits event stream text into events. Following the
+// SSE format, an event ends at a blank line, its data lines are joined
+// with newlines, comments are skipped and an unterminated last event is
+// dropped, as is an event without data.
+func parseEventStream(text string) []StreamEvent {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	events := []StreamEvent{}
+	var event StreamEvent
+	var data []string
+	lines := strings.Split(text, "\n")
+	// The last element follows the final newline: an unterminated line
+	for _, line := range lines[:len(lines)-1] {
+		if line == "" {
+			if data != nil {
+				event.Data = streamEventData(strings.Join(data, "\n"))
+				events = append(events, event)
+			}
+			event, data = StreamEvent{}, nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "id":
+			event.ID = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+	return events
+}
+
+// streamEventData embeds JSON event data as JSON and keeps other data as a
+// string
+func streamEventData(data string) interface{} {
+	if json.Valid([]byte(data)) {
+		return json.RawMessage(data)
+	}
+	return data
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEventStream_BoundedAccumulation(t *testing.T) {
+	done := make(chan struct{})
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 1; i <= 2; i++ {
+			_, _ = fmt.Fprintf(w, ": keep-alive\nevent: tick\nid: %d\ndata: {\"n\":%d}\n\n", i, i)
+		}
+		_, _ = fmt.Fprint(w, "data: plain\ndata: text\n\ndata: partial")
+		w.(http.Flusher).Flush()
+		// Hold the stream open until the test ends
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}))
+	defer backend.Close()
+	defer close(done)
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithStreamLimits(200*time.Millisecond, 0))
+
+	start := time.Now()
+	content, status, err := server.CallTool(context.Background(), "listpets", nil)
+	if err != nil || status != http.StatusOK {
+		t.Fatalf("listpets = %d, %v", status, err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("call took %v; the stream should be cut off after 200ms", elapsed)
+	}
+
+	var result struct {
+		Events []struct {
+			Event string          `json:"event"`
+			ID    string          `json:"id"`
+			Data  json.RawMessage `json:"data"`
+		} `json:"events"`
+		Truncated bool `json:"truncated"`
+	}
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		t.Fatalf("result is not a stream result: %v\n%s", err, content)
+	}
+	if !result.Truncated || len(result.Events) != 3 {
+		t.Fatalf("got %d events (truncated %v), want the 3 complete events, truncated: %s", len(result.Events), result.Truncated, content)
+	}
+	if first := result.Events[0]; first.Event != "tick" || first.ID != "1" || string(first.Data) != `{"n":1}` {
+		t.Errorf("first event = %+v", first)
+	}
+	if data := string(result.Events[2].Data); data != `"plain\ntext"` {
+		t.Errorf("multi-line data = %s, want the lines joined", data)
+	}
+}
+
+func TestEventStream_ByteBudget(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		for i := 0; i < 1000; i++ {
+			_, _ = fmt.Fprintf(w, "data: %d\n\n", i)
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithStreamLimits(0, 64))
+
+	content, _, err := server.CallTool(context.Background(), "listpets", nil)
+	if err != nil {
+		t.Fatalf("listpets failed: %v", err)
+	}
+	var result StreamResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		t.Fatalf("result is not a stream result: %v", err)
+	}
+	if !result.Truncated || len(result.Events) == 0 || len(result.Events) > 10 {
+		t.Errorf("got %d events (truncated %v), want a few within 64 bytes", len(result.Events), result.Truncated)
+	}
+}
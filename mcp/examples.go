@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/go-openapi/spec"
+)
+
+// ExampleCall is a tool call whose arguments are filled from the spec's
+// parameter and body examples (or defaults), e.g. for smoke testing an API
+// by passing each one to CallTool
+type ExampleCall struct {
+	Tool   string                 `json:"tool"`
+	Method string                 `json:"method"`
+	Path   string                 `json:"path"`
+	Args   map[string]interface{} `json:"args"`
+
+	// Missing lists required arguments the spec gives no example or
+	// default for; calls with missing arguments will likely fail
+	Missing []string `json:"missing,omitempty"`
+}
+
+// GenerateExampleCalls returns an example call for every available tool
+// (after filtering), sorted by tool name
+func (s *Server) GenerateExampleCalls() []ExampleCall {
+	swagger, filter := s.mcp.exposedSpec()
+	calls := []ExampleCall{}
+	if swagger == nil || swagger.Paths == nil {
+		return calls
+	}
+
+	for path, pathItem := range swagger.Paths.Paths {
+		for method, op := range map[string]*spec.Operation{
+			"GET":     pathItem.Get,
+			"POST":    pathItem.Post,
+			"PUT":     pathItem.Put,
+			"DELETE":  pathItem.Delete,
+			"PATCH":   pathItem.Patch,
+			"HEAD":    pathItem.Head,
+			"OPTIONS": pathItem.Options,
+		} {
+			if !exposesOperation(filter, method, path, op) {
+				continue
+			}
+			calls = append(calls, s.exampleCall(method, path, op))
+		}
+	}
+
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Tool < calls[j].Tool })
+	return calls
+}
+
+// exampleCall builds the example call for one operation
+func (s *Server) exampleCall(method, path string, op *spec.Operation) ExampleCall {
+	call := ExampleCall{
+		Tool:   GenerateToolName(method, path, op),
+		Method: method,
+		Path:   path,
+		Args:   map[string]interface{}{},
+	}
+	for _, param := range op.Parameters {
+		// Only arguments the tool accepts; credentials come from the server
+		if param.In == "header" && isAuthHeader(param.Name) {
+			continue
+		}
+		if param.In == "cookie" && !s.config.CookieParams {
+			continue
+		}
+
+		name := param.Name
+		var value interface{}
+		if param.In == "body" {
+			name = "body"
+			if param.Schema != nil {
+				value = schemaExampleValue(param.Schema)
+			}
+		} else {
+			value = parameterExample(param)
+			if value == nil {
+				value = param.Default
+			}
+		}
+
+		if value == nil {
+			if param.Required {
+				call.Missing = append(call.Missing, name)
+			}
+			continue
+		}
+		call.Args[name] = value
+	}
+
+	// The values come straight from the spec; give the caller its own copy
+	if data, err := json.Marshal(call.Args); err == nil {
+		var args map[string]interface{}
+		if json.Unmarshal(data, &args) == nil {
+			call.Args = args
+		}
+	}
+	return call
+}
+
+// schemaExampleValue returns a schema's example or default, or for objects
+// and arrays without one, a value assembled from their properties' or
+// items' examples. It returns nil when the schema offers nothing.
+func schemaExampleValue(schema *spec.Schema) interface{} {
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+
+	if len(schema.Properties) > 0 {
+		object := make(map[string]interface{})
+		for name, property := range schema.Properties {
+			property := property
+			if value := schemaExampleValue(&property); value != nil {
+				object[name] = value
+			}
+		}
+		if len(object) > 0 {
+			return object
+		}
+		return nil
+	}
+	if schema.Items != nil && schema.Items.Schema != nil {
+		if item := schemaExampleValue(schema.Items.Schema); item != nil {
+			return []interface{}{item}
+		}
+	}
+	return nil
+}
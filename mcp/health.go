@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultHealthProbeTimeout bounds the upstream probe when no timeout is configured
+const defaultHealthProbeTimeout = 5 * time.Second
+
+// UpstreamHealth is the result of probing the upstream API
+type UpstreamHealth struct {
+	URL        string `json:"url"`
+	Reachable  bool   `json:"reachable"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	LatencyMS  int64  `json:"latencyMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckUpstream probes the upstream API with a HEAD request to the base
+// URL, or a GET to the configured HealthProbePath. The upstream counts as
+// reachable when it answers with any status below 500.
+func (s *Server) CheckUpstream(ctx context.Context) UpstreamHealth {
+	timeout := s.config.HealthProbeTimeout
+	if timeout <= 0 {
+		timeout = defaultHealthProbeTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method, url := http.MethodHead, s.apiBaseURL()
+	if s.config.HealthProbePath != "" {
+		method, url = http.MethodGet, joinURL(url, s.config.HealthProbePath)
+	}
+	health := UpstreamHealth{URL: url}
+	if url == "" {
+		health.Error = "no API base URL configured"
+		return health
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		health.Error = fmt.Sprintf("invalid probe URL: %v", err)
+		return health
+	}
+
+	start := time.Now()
+	resp, err := s.mcp.apiExecutor.client().Do(req)
+	health.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	_ = resp.Body.Close()
+
+	health.StatusCode = resp.StatusCode
+	health.Reachable = resp.StatusCode < http.StatusInternalServerError
+	if !health.Reachable {
+		health.Error = resp.Status
+	}
+	return health
+}
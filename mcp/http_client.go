@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RedirectPolicy controls how upstream 3xx redirects are handled
+type RedirectPolicy int
+
+const (
+	// RedirectFollow follows redirects, like Go's default client
+	RedirectFollow RedirectPolicy = iota
+	// RedirectNone returns the 3xx response itself as the call's result
+	RedirectNone
+	// RedirectSameHost follows redirects but sends credentials only to the
+	// original host: redirects to another host lose the auth headers
+	RedirectSameHost
+)
+
+// defaultMaxRedirects is the number of redirects a call follows by
+// default, as with Go's default client
+const defaultMaxRedirects = 10
+
+// newHTTPClient builds the client shared by all upstream API calls. Proxy
+// settings come from HTTP_PROXY/HTTPS_PROXY/NO_PROXY unless an explicit
+// proxy is configured.
+func newHTTPClient(config *Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if config != nil && config.ProxyURL != "" {
+		// Validated by validateConfig
+		if proxyURL, err := url.Parse(config.ProxyURL); err == nil {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	if config != nil {
+		transport.TLSClientConfig = newTLSConfig(config)
+		if config.ConnectTimeout > 0 {
+			dialer := &net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}
+			transport.DialContext = dialer.DialContext
+			transport.TLSHandshakeTimeout = config.ConnectTimeout
+		}
+	}
+
+	client := &http.Client{Transport: transport}
+	if config != nil {
+		// With per-operation timeouts the executor bounds each call
+		// instead, since a client timeout would cap the longer overrides
+		if len(config.OperationTimeouts) == 0 {
+			client.Timeout = config.Timeout
+		}
+		maxRedirects := config.MaxRedirects
+		if maxRedirects <= 0 {
+			maxRedirects = defaultMaxRedirects
+		}
+		client.CheckRedirect = checkRedirect(config.RedirectPolicy, config.AllowedHosts, config.AuthHeaderMode.customHeader(), maxRedirects)
+	}
+	return client
+}
+
+// checkRedirect implements policy as an http.Client CheckRedirect function,
+// refusing redirects to hosts outside allowedHosts and chains longer than
+// maxRedirects. authHeader names a custom API key header dropped along with
+// the standard auth headers.
+func checkRedirect(policy RedirectPolicy, allowedHosts []string, authHeader string, maxRedirects int) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if policy == RedirectNone {
+			return http.ErrUseLastResponse
+		}
+		if err := checkAllowedHost(allowedHosts, req.URL); err != nil {
+			return fmt.Errorf("refusing redirect: %w", err)
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects: the redirect chain is too long or loops", maxRedirects)
+		}
+		if policy == RedirectSameHost && !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+			for name := range authHeaders {
+				req.Header.Del(name)
+			}
+			if authHeader != "" {
+				req.Header.Del(authHeader)
+			}
+		}
+		return nil
+	}
+}
+
+// checkAllowedHost returns ErrHostNotAllowed unless u's host is in
+// allowedHosts (case-insensitive, ignoring the port). An empty list allows
+// every host.
+func checkAllowedHost(allowedHosts []string, u *url.URL) error {
+	if len(allowedHosts) == 0 {
+		return nil
+	}
+	host := u.Hostname()
+	for _, allowed := range allowedHosts {
+		if host != "" && strings.EqualFold(host, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q is not in the allowed hosts", ErrHostNotAllowed, host)
+}
+
+// checkSecureScheme refuses plaintext http URLs to hosts other than the
+// local machine, which would send credentials over the wire unencrypted
+func checkSecureScheme(u *url.URL) error {
+	if !strings.EqualFold(u.Scheme, "http") || isLoopbackHost(u.Hostname()) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (use https, or allow it with WithAllowInsecureHTTP)", ErrInsecureHTTP, u.Redacted())
+}
+
+// isLoopbackHost reports whether host names the local machine
+func isLoopbackHost(host string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// validateProxyURL checks that a proxy URL is absolute and uses a scheme
+// supported by net/http
+func validateProxyURL(proxy string) error {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL %q: %w", proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("invalid proxy URL %q: scheme must be http, https, socks5 or socks5h", proxy)
+	}
+	if proxyURL.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q: missing host", proxy)
+	}
+	return nil
+}
+
+// newTLSConfig builds the TLS settings for upstream calls
+func newTLSConfig(config *Config) *tls.Config {
+	minVersion := config.MinTLSVersion
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	tlsConfig := &tls.Config{
+		RootCAs:    config.RootCAs,
+		MinVersion: minVersion,
+	}
+
+	if config.InsecureSkipVerify {
+		log.Printf("WARNING: TLS certificate verification is DISABLED for upstream API calls. " +
+			"Connections are open to man-in-the-middle attacks; do not use this in production.")
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig
+}
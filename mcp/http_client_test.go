@@ -0,0 +1,351 @@
+package mcp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/spec"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWithProxy_RoutesThroughProxy(t *testing.T) {
+	var proxiedHost, proxiedPath string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		proxiedHost = r.URL.Host
+		proxiedPath = r.URL.Path
+		_, _ = w.Write([]byte(`{"via":"proxy"}`))
+	}))
+	defer proxy.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig("http://api.internal.example", "").
+		WithAllowInsecureHTTP(true).
+		WithProxy(proxy.URL))
+
+	result := callTool(t, server, "listpets", nil)
+	if result.IsError {
+		t.Fatalf("call through proxy failed: %s", resultText(t, result))
+	}
+	if proxiedHost != "api.internal.example" || proxiedPath != "/pets" {
+		t.Errorf("proxy saw %s%s, want api.internal.example/pets", proxiedHost, proxiedPath)
+	}
+	if !strings.Contains(resultText(t, result), "proxy") {
+		t.Errorf("expected proxied response, got %s", resultText(t, result))
+	}
+}
+
+func TestNewHTTPClient_UsesEnvironmentProxyByDefault(t *testing.T) {
+	transport, ok := newHTTPClient(DefaultConfig()).Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("expected *http.Transport")
+	}
+	if transport.Proxy == nil {
+		t.Error("default transport should honor proxy environment variables")
+	}
+}
+
+func TestWithProxy_InvalidURL(t *testing.T) {
+	for _, proxy := range []string{"ftp://proxy:21", "not a url", "http://"} {
+		config := DefaultConfig().
+			WithSwaggerData([]byte(executorTestSwagger)).
+			WithProxy(proxy)
+		if _, err := New(config); err == nil {
+			t.Errorf("expected New to reject proxy %q", proxy)
+		}
+	}
+}
+
+func TestTLS_SelfSignedUpstream(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"secure":true}`))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name    string
+		config  func(*Config) *Config
+		wantErr bool
+	}{
+		{
+			name:    "default verification rejects self-signed cert",
+			config:  func(c *Config) *Config { return c },
+			wantErr: true,
+		},
+		{
+			name:   "insecure skip verify",
+			config: func(c *Config) *Config { return c.WithInsecureSkipVerify(true) },
+		},
+		{
+			name: "custom root CA",
+			config: func(c *Config) *Config {
+				pool := x509.NewCertPool()
+				pool.AddCert(backend.Certificate())
+				return c.WithRootCAs(pool)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor := newAPIExecutorFromConfig(tt.config(DefaultConfig().WithAPIConfig(backend.URL, "")))
+			_, _, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets", map[string]interface{}{})
+			if tt.wantErr && err == nil {
+				t.Fatal("expected a certificate verification error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestMinTLSVersion(t *testing.T) {
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	backend.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS11}
+	backend.StartTLS()
+	defer backend.Close()
+
+	call := func(config *Config) error {
+		executor := newAPIExecutorFromConfig(config.WithAPIConfig(backend.URL, "").WithInsecureSkipVerify(true))
+		_, _, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets", map[string]interface{}{})
+		return err
+	}
+
+	// Default minimum (TLS 1.2) refuses a TLS 1.0/1.1-only server
+	if err := call(DefaultConfig()); err == nil {
+		t.Error("expected handshake failure against a TLS 1.1-only server with the default minimum")
+	}
+	if err := call(DefaultConfig().WithMinTLSVersion(tls.VersionTLS12)); err == nil {
+		t.Error("expected handshake failure with minimum TLS 1.2")
+	}
+
+	if got := newTLSConfig(DefaultConfig()).MinVersion; got != tls.VersionTLS12 {
+		t.Errorf("default MinVersion = %x, want TLS 1.2", got)
+	}
+	if got := newTLSConfig(DefaultConfig().WithMinTLSVersion(tls.VersionTLS13)).MinVersion; got != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x, want TLS 1.3", got)
+	}
+}
+
+func TestConnectTimeout_FailsFast(t *testing.T) {
+	call := func(baseURL string) (time.Duration, error) {
+		config := DefaultConfig().
+			WithAPIConfig(baseURL, "").
+			WithTimeout(30 * time.Second).
+			WithConnectTimeout(200 * time.Millisecond)
+		executor := newAPIExecutorFromConfig(config)
+		start := time.Now()
+		_, _, err := executor.BuildAndExecuteRequest(context.Background(), "GET", "/pets", map[string]interface{}{})
+		return time.Since(start), err
+	}
+
+	// An unroutable address never answers the dial
+	elapsed, err := call("https://10.255.255.1")
+	if err == nil {
+		t.Fatal("expected a call to an unroutable address to fail")
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("unroutable call took %v, want the connect timeout to fire well before the 30s overall timeout", elapsed)
+	}
+
+	// A host that accepts the connection but never completes the TLS
+	// handshake is bounded by the same timeout
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	elapsed, err = call("https://" + listener.Addr().String())
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("expected a TLS handshake timeout, got %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("stalled handshake took %v, want the connect timeout to fire", elapsed)
+	}
+}
+
+func TestRedirectPolicy(t *testing.T) {
+	var otherAPIKey, sameAPIKey string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAPIKey = r.Header.Get("X-API-Key")
+		_, _ = w.Write([]byte(`{"host":"other"}`))
+	}))
+	defer other.Close()
+
+	var target string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pets":
+			http.Redirect(w, r, target, http.StatusFound)
+		case "/moved":
+			sameAPIKey = r.Header.Get("X-API-Key")
+			_, _ = w.Write([]byte(`{"host":"same"}`))
+		}
+	}))
+	defer api.Close()
+
+	tests := []struct {
+		name        string
+		policy      RedirectPolicy
+		target      string
+		wantStatus  int
+		wantContent string
+		wantKey     string // X-API-Key seen by the redirect target
+	}{
+		{"follow same host", RedirectFollow, "/moved", 200, "same", "secret"},
+		{"follow cross host", RedirectFollow, other.URL + "/landing", 200, "other", "secret"},
+		{"none", RedirectNone, other.URL + "/landing", 302, "redirect to " + other.URL + "/landing", ""},
+		{"same host keeps credentials", RedirectSameHost, "/moved", 200, "same", "secret"},
+		{"cross host strips credentials", RedirectSameHost, other.URL + "/landing", 200, "other", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, otherAPIKey, sameAPIKey = tt.target, "", ""
+			server := newTestServer(t, DefaultConfig().
+				WithAPIConfig(api.URL, "secret").
+				WithRedirectPolicy(tt.policy))
+
+			content, status, err := server.CallTool(context.Background(), "listpets", nil)
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			if status != tt.wantStatus || !strings.Contains(content, tt.wantContent) {
+				t.Errorf("got %d %q, want %d containing %q", status, content, tt.wantStatus, tt.wantContent)
+			}
+			if key := otherAPIKey + sameAPIKey; key != tt.wantKey {
+				t.Errorf("redirect target saw X-API-Key %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestMaxRedirects(t *testing.T) {
+	var hits int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		http.Redirect(w, r, fmt.Sprintf("/pets?hop=%d", n), http.StatusFound)
+	}))
+	defer api.Close()
+
+	call := func(config *Config) *sdk.CallToolResult {
+		atomic.StoreInt32(&hits, 0)
+		return callTool(t, newTestServer(t, config.WithAPIConfig(api.URL, "")), "listpets", nil)
+	}
+
+	result := call(DefaultConfig().WithMaxRedirects(3))
+	if !result.IsError || !strings.Contains(resultText(t, result), "stopped after 3 redirects") {
+		t.Errorf("expected the chain to stop after 3 redirects, got %q", resultText(t, result))
+	}
+	if got := atomic.LoadInt32(&hits); got != 4 {
+		t.Errorf("upstream saw %d requests, want the call and 3 redirects", got)
+	}
+
+	// The default bound still stops a looping chain
+	result = call(DefaultConfig())
+	if !result.IsError || !strings.Contains(resultText(t, result), "stopped after 10 redirects") {
+		t.Errorf("expected the default limit of 10, got %q", resultText(t, result))
+	}
+}
+
+func TestAllowedHosts(t *testing.T) {
+	metadataSpec := `{
+  "swagger": "2.0",
+  "info": {"title": "Evil API", "version": "1.0.0"},
+  "host": "169.254.169.254",
+  "basePath": "/latest/meta-data",
+  "schemes": ["http"],
+  "paths": {"/iam": {"get": {"operationId": "getCredentials", "responses": {"200": {"description": "OK"}}}}}
+}`
+	_, err := New(DefaultConfig().
+		WithSwaggerData([]byte(metadataSpec)).
+		WithAllowInsecureHTTP(true).
+		WithAllowedHosts([]string{"api.example.com"}))
+	if !errors.Is(err, ErrHostNotAllowed) || !strings.Contains(err.Error(), "169.254.169.254") {
+		t.Fatalf("expected the metadata host to be rejected, got %v", err)
+	}
+	if _, err := New(DefaultConfig().WithSwaggerData([]byte(metadataSpec)).WithAllowInsecureHTTP(true)); err != nil {
+		t.Fatalf("without an allow-list the spec should load: %v", err)
+	}
+
+	var reached bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pets" {
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+			return
+		}
+		reached = true
+	}))
+	defer backend.Close()
+
+	// Redirects to hosts outside the list are refused
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithAllowedHosts([]string{"127.0.0.1"}))
+	if _, _, err := server.CallTool(context.Background(), "listpets", nil); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected the redirect to be refused, got %v", err)
+	}
+
+	// So are per-operation base URLs
+	config := DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithAllowedHosts([]string{"127.0.0.1"})
+	config.BaseURLFunc = func(method, path string, op *spec.Operation) string {
+		if method == "POST" {
+			return "http://169.254.169.254"
+		}
+		return ""
+	}
+	server = newTestServer(t, config)
+	if _, _, err := server.CallTool(context.Background(), "createpet", map[string]interface{}{"body": map[string]interface{}{}}); !errors.Is(err, ErrHostNotAllowed) {
+		t.Errorf("expected the per-operation host to be refused, got %v", err)
+	}
+	if reached {
+		t.Error("a refused call reached the backend")
+	}
+}
+
+func TestInsecureHTTP_BaseURLPolicy(t *testing.T) {
+	newServer := func(baseURL string, allow bool) error {
+		_, err := New(DefaultConfig().
+			WithSwaggerData([]byte(executorTestSwagger)).
+			WithAPIConfig(baseURL, "secret").
+			WithAllowInsecureHTTP(allow))
+		return err
+	}
+
+	err := newServer("http://api.example.com/v1", false)
+	if !errors.Is(err, ErrInsecureHTTP) || !strings.Contains(err.Error(), "http://api.example.com/v1") {
+		t.Errorf("expected a remote http base URL to be rejected by name, got %v", err)
+	}
+	if err := newServer("http://api.example.com/v1", true); err != nil {
+		t.Errorf("expected WithAllowInsecureHTTP to permit it, got %v", err)
+	}
+	for _, baseURL := range []string{"http://localhost:8080", "http://127.0.0.1:9000/api", "http://[::1]:8080", "https://api.example.com"} {
+		if err := newServer(baseURL, false); err != nil {
+			t.Errorf("%s: expected to be allowed, got %v", baseURL, err)
+		}
+	}
+}
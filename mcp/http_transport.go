@@ -3,11 +3,14 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
-	"github.com/go-openapi/spec"
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -18,6 +21,7 @@ type HTTPServer struct {
 	host       string
 	path       string
 	httpServer *http.Server
+	ctx        context.Context // Lifetime of the server, for background calls
 }
 
 // NewHTTPServer creates a new HTTP server wrapper
@@ -39,6 +43,7 @@ func NewHTTPServer(server *Server, port int, host, path string) *HTTPServer {
 
 // Start starts the HTTP server
 func (h *HTTPServer) Start(ctx context.Context) error {
+	h.ctx = ctx
 	mux := http.NewServeMux()
 
 	// Add CORS middleware
@@ -70,27 +75,41 @@ func (h *HTTPServer) Start(ctx context.Context) error {
 	}, nil)
 	mux.HandleFunc(h.path, corsHandler(streamableHandler.ServeHTTP))
 
-	// Health check endpoint
-	mux.HandleFunc(basePath+"health", corsHandler(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		response := map[string]string{
-			"status":  "ok",
-			"server":  h.server.config.Name,
-			"version": h.server.config.Version,
-		}
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			log.Printf("Failed to encode health response: %v", err)
-		}
-	}))
+	// Health check endpoints; /readyz always probes the upstream API
+	mux.HandleFunc(basePath+"health", corsHandler(h.handleHealth))
+	mux.HandleFunc(basePath+"readyz", corsHandler(h.handleHealth))
 
 	// Tools list endpoint
 	mux.HandleFunc(basePath+"tools", corsHandler(h.handleToolsList))
+	mux.HandleFunc(basePath+"tools/{name}", corsHandler(h.handleToolDescribe))
+
+	// Resolved configuration endpoint (secrets redacted)
+	mux.HandleFunc(basePath+"config", corsHandler(h.handleConfig))
+
+	// Effective spec endpoint (only the operations exposed as tools)
+	mux.HandleFunc(basePath+"openapi.json", corsHandler(h.handleOpenAPI))
+
+	// REST tool calls, optionally asynchronous with a result callback
+	if h.server.config.AsyncCallbacks {
+		mux.HandleFunc(basePath+"call", corsHandler(h.handleCall))
+	}
+
+	// Metrics endpoint (only when the configured collector can serve itself)
+	if metricsHandler, ok := h.server.config.Metrics.(http.Handler); ok {
+		mux.HandleFunc(basePath+"metrics", corsHandler(metricsHandler.ServeHTTP))
+	}
+
+	// Apply user middleware so that the first one registered is outermost
+	var handler http.Handler = mux
+	middleware := h.server.config.HTTPMiddleware
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
 
 	addr := fmt.Sprintf("%s:%d", h.host, h.port)
 	h.httpServer = &http.Server{
 		Addr:    addr,
-		Handler: mux,
+		Handler: handler,
 	}
 
 	log.Printf("Starting HTTP MCP server on %s%s", addr, h.path)
@@ -109,6 +128,67 @@ func (h *HTTPServer) Start(ctx context.Context) error {
 	return nil
 }
 
+// handleHealth handles GET /health and /readyz. With ?deep=true (implied
+// for /readyz) it also probes the upstream API and answers 503 when the
+// upstream is unreachable.
+func (h *HTTPServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"status":  "ok",
+		"server":  h.server.config.Name,
+		"version": h.server.config.Version,
+	}
+	status := http.StatusOK
+
+	deep := r.URL.Query().Get("deep") == "true" || strings.HasSuffix(r.URL.Path, "/readyz")
+	if deep {
+		upstream := h.server.CheckUpstream(r.Context())
+		response["upstream"] = upstream
+		if !upstream.Reachable {
+			response["status"] = "not_ready"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Failed to encode health response: %v", err)
+	}
+}
+
+// handleConfig handles GET /config endpoint
+func (h *HTTPServer) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Report the transport actually serving this request
+	resolved := h.server.ResolvedConfig()
+	resolved.Transport = TransportInfo{Type: "http", Host: h.host, Port: h.port, Path: h.path}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resolved); err != nil {
+		log.Printf("Failed to encode config response: %v", err)
+	}
+}
+
+// handleOpenAPI handles GET /openapi.json, serving the spec reduced to the
+// operations exposed as tools
+func (h *HTTPServer) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	effective, err := h.server.EffectiveSpec()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, effective)
+}
+
 // handleToolsList handles GET /tools endpoint
 func (h *HTTPServer) handleToolsList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -116,94 +196,104 @@ func (h *HTTPServer) handleToolsList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tools := h.getAvailableTools()
+	query := r.URL.Query()
+	limit, err := queryInt(query, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := queryInt(query, "offset")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tools := filterTools(h.server.ListToolsDetailed(), query.Get("tag"), query.Get("method"), query.Get("q"))
+	total := len(tools)
+	tools = tools[min(offset, total):]
+	if limit > 0 && limit < len(tools) {
+		tools = tools[:limit]
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"tools": tools,
+		"tools":  tools,
+		"total":  total,
+		"offset": offset,
 	}); err != nil {
 		log.Printf("Failed to encode tools response: %v", err)
 	}
 }
 
-// getToolName generates tool name using shared utility
-func (h *HTTPServer) getToolName(method, path string, op *spec.Operation) string {
-	return GenerateToolName(method, path, op)
-}
-
-// getAvailableTools returns a list of available tools (applying filters)
-func (h *HTTPServer) getAvailableTools() []map[string]interface{} {
-	config := h.server.GetConfig()
-	if config.SwaggerSpec == nil {
-		return []map[string]interface{}{}
+// handleToolDescribe handles GET /tools/{name}, describing one tool in full
+func (h *HTTPServer) handleToolDescribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	tools := []map[string]interface{}{}
-	for path, pathItem := range config.SwaggerSpec.Paths.Paths {
-		if pathItem.Get != nil && !h.shouldExcludeOperation("GET", path, pathItem.Get, config.Filter) {
-			tools = append(tools, h.createToolInfo("GET", path, pathItem.Get))
-		}
-		if pathItem.Post != nil && !h.shouldExcludeOperation("POST", path, pathItem.Post, config.Filter) {
-			tools = append(tools, h.createToolInfo("POST", path, pathItem.Post))
-		}
-		if pathItem.Put != nil && !h.shouldExcludeOperation("PUT", path, pathItem.Put, config.Filter) {
-			tools = append(tools, h.createToolInfo("PUT", path, pathItem.Put))
-		}
-		if pathItem.Delete != nil && !h.shouldExcludeOperation("DELETE", path, pathItem.Delete, config.Filter) {
-			tools = append(tools, h.createToolInfo("DELETE", path, pathItem.Delete))
-		}
-		if pathItem.Patch != nil && !h.shouldExcludeOperation("PATCH", path, pathItem.Patch, config.Filter) {
-			tools = append(tools, h.createToolInfo("PATCH", path, pathItem.Patch))
-		}
+	info, err := h.server.DescribeTool(r.PathValue("name"))
+	if errors.Is(err, ErrToolNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
 	}
-
-	return tools
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
 }
 
-// shouldExcludeOperation checks if an operation should be excluded based on filters
-func (h *HTTPServer) shouldExcludeOperation(method, path string, operation *spec.Operation, filter *APIFilter) bool {
-	if filter == nil {
-		return false
+// queryInt parses an optional non-negative integer query parameter,
+// returning 0 when it is absent
+func queryInt(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
 	}
-	return filter.ShouldExcludeOperation(method, path, operation)
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q: expected a non-negative integer", name, raw)
+	}
+	return n, nil
 }
 
-// createToolInfo creates tool information from swagger operation
-func (h *HTTPServer) createToolInfo(method, path string, op *spec.Operation) map[string]interface{} {
-	toolName := h.getToolName(method, path, op)
-	description := GenerateToolDescription(method, path, op)
-
-	// Build parameter schema
-	parameters := []map[string]interface{}{}
-	for _, param := range op.Parameters {
-		paramInfo := map[string]interface{}{
-			"name":        param.Name,
-			"in":          param.In,
-			"required":    param.Required,
-			"description": param.Description,
+// filterTools keeps the tools with the given tag and method (both matched
+// case-insensitively) whose name or description contains q. Empty criteria
+// match every tool.
+func filterTools(tools []ToolInfo, tag, method, q string) []ToolInfo {
+	q = strings.ToLower(q)
+	filtered := []ToolInfo{}
+	for _, tool := range tools {
+		if method != "" && !strings.EqualFold(tool.Method, method) {
+			continue
 		}
-
-		if param.Type != "" {
-			paramInfo["type"] = param.Type
+		if tag != "" && !hasTag(tool.Tags, tag) {
+			continue
 		}
-		if param.Format != "" {
-			paramInfo["format"] = param.Format
+		if q != "" && !strings.Contains(strings.ToLower(tool.Name), q) && !strings.Contains(strings.ToLower(tool.Description), q) {
+			continue
 		}
-
-		parameters = append(parameters, paramInfo)
+		filtered = append(filtered, tool)
 	}
+	return filtered
+}
 
-	return map[string]interface{}{
-		"name":        toolName,
-		"description": description,
-		"method":      method,
-		"path":        path,
-		"parameters":  parameters,
-		"operationId": op.ID,
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
 	}
+	return false
 }
 
 // RunHTTP runs the server with HTTP transport
 func (s *Server) RunHTTP(ctx context.Context, port int) error {
+	if err := s.startSpecWatch(ctx); err != nil {
+		return err
+	}
 	httpServer := NewHTTPServer(s, port, "", "")
 	return httpServer.Start(ctx)
 }
@@ -217,3 +307,17 @@ func (c *Config) WithHTTPTransport(port int, host, path string) *Config {
 	}
 	return c
 }
+
+// WithTransportStdioAndHTTP configures the server to serve MCP over stdio
+// and HTTP at the same time, e.g. a local client on stdio with the HTTP
+// endpoints exposed for monitoring. Both share the same registered tools.
+func (c *Config) WithTransportStdioAndHTTP(port int, host, path string) *Config {
+	c.Transport = &StdioAndHTTPTransport{
+		HTTP: &HTTPTransport{
+			Port: port,
+			Host: host,
+			Path: path,
+		},
+	}
+	return c
+}
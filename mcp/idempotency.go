@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// DefaultIdempotencyHeader is the header WithIdempotency uses when given no
+// name
+const DefaultIdempotencyHeader = "Idempotency-Key"
+
+// newIdempotencyKey returns a random (version 4) UUID
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// setIdempotencyKey gives a POST or PATCH request a fresh idempotency key
+// in header, unless the call already supplied one. The key is set on the
+// request itself, so every attempt at sending it carries the same key;
+// the only such resends are followed 307 and 308 redirects.
+func setIdempotencyKey(req *http.Request, header string) error {
+	if header == "" || (req.Method != http.MethodPost && req.Method != http.MethodPatch) || req.Header.Get(header) != "" {
+		return nil
+	}
+	key, err := newIdempotencyKey()
+	if err != nil {
+		return fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	req.Header.Set(header, key)
+	return nil
+}
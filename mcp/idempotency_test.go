@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdempotency_SameKeyAcrossResends(t *testing.T) {
+	var keys []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		// Have the first attempt at each call resent elsewhere, as a
+		// load balancer might
+		if r.URL.Path == "/pets" && r.Method == http.MethodPost {
+			http.Redirect(w, r, "/pets-primary", http.StatusTemporaryRedirect)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithIdempotency(""))
+
+	callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if len(keys) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(keys))
+	}
+	if keys[0] == "" || !uuidPattern.MatchString(keys[0]) {
+		t.Errorf("idempotency key %q is not a UUID", keys[0])
+	}
+	if keys[0] != keys[1] || keys[2] != keys[3] {
+		t.Errorf("a resent call changed its key: %v", keys)
+	}
+	if keys[0] == keys[2] {
+		t.Errorf("two calls shared the key %q", keys[0])
+	}
+
+	// Only POST and PATCH carry a key
+	keys = nil
+	callTool(t, server, "updatepet", map[string]any{"petId": "1", "body": map[string]any{"name": "Rex"}})
+	if len(keys) != 1 || keys[0] != "" {
+		t.Errorf("PUT sent idempotency key %v", keys)
+	}
+}
+
+func TestIdempotency_KeyCoversEveryBudgetedAttempt(t *testing.T) {
+	var keys []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		http.Redirect(w, r, r.URL.Path, http.StatusPermanentRedirect)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithIdempotency("").
+		WithUpstreamAttemptBudget(4))
+
+	_, _, err := server.CallTool(context.Background(), "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if !errors.Is(err, ErrAttemptBudgetExhausted) {
+		t.Fatalf("expected ErrAttemptBudgetExhausted, got %v", err)
+	}
+	if len(keys) != 4 {
+		t.Fatalf("got %d attempts, want 4", len(keys))
+	}
+	for _, key := range keys {
+		if key == "" || key != keys[0] {
+			t.Fatalf("attempts of one call used different keys: %v", keys)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/url"
+	"sort"
+	"strings"
 
 	"github.com/go-openapi/spec"
 )
@@ -12,6 +15,9 @@ import (
 type Server struct {
 	config *Config
 	mcp    *SwaggerMCPServer
+
+	// baseURLInferred records that APIBaseURL was derived from the spec
+	baseURLInferred bool
 }
 
 // New creates a new MCP server with the given configuration
@@ -24,27 +30,64 @@ func New(config *Config) (*Server, error) {
 	if err := validateConfig(config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	if config.Filter != nil {
+		filter, err := config.Filter.compiled()
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		config.Filter = filter
+	}
 	
 	// Parse swagger spec if not already parsed
 	if config.SwaggerSpec == nil && len(config.SwaggerData) > 0 {
-		swagger, err := ParseSwaggerSpec(config.SwaggerData)
+		swagger, err := ParseSwaggerSpecWithOptions(config.SwaggerData, config.parseOptions())
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse swagger spec: %w", err)
 		}
 		config.SwaggerSpec = swagger
 	}
 	
+	if err := validateSpec(config.SwaggerSpec, config.Filter); err != nil {
+		return nil, fmt.Errorf("invalid swagger spec: %w", err)
+	}
+	filter, err := limitTools(config, config.SwaggerSpec)
+	if err != nil {
+		return nil, err
+	}
+	
 	// Determine base URL if not set
+	baseURLInferred := false
 	if config.APIBaseURL == "" && config.SwaggerSpec != nil {
-		config.APIBaseURL = inferBaseURL(config.SwaggerSpec)
+		config.APIBaseURL = specBaseURL(config.SwaggerSpec, config.ServerVariableArgs)
+		baseURLInferred = config.APIBaseURL != ""
+	}
+	if config.APIBaseURL != "" {
+		if err := checkBaseURL(config, config.APIBaseURL, config.SwaggerSpec); err != nil {
+			return nil, err
+		}
+	}
+	
+	var replayer *callReplayer
+	if config.ReplayPath != "" {
+		var err error
+		if replayer, err = loadReplayer(config.ReplayPath); err != nil {
+			return nil, err
+		}
 	}
 	
-	// Create the underlying MCP server with filtering support
-	mcpServer := NewSwaggerMCPServerWithFilter(config.APIBaseURL, config.SwaggerSpec, config.APIKey, config.Filter)
+	// Create the underlying MCP server with filtering and execution options
+	mcpServer := newSwaggerMCPServer(config, filter)
+	mcpServer.apiExecutor.replayer = replayer
+	if config.ToolManifestPath != "" {
+		if err := mcpServer.writeToolManifest(config.ToolManifestPath); err != nil {
+			return nil, err
+		}
+	}
 	
 	return &Server{
-		config: config,
-		mcp:    mcpServer,
+		config:          config,
+		mcp:             mcpServer,
+		baseURLInferred: baseURLInferred,
 	}, nil
 }
 
@@ -57,6 +100,7 @@ func NewFromSwaggerFile(filePath, apiBaseURL, apiKey string) (*Server, error) {
 	
 	config := DefaultConfig().
 		WithSwaggerData(data).
+		WithSpecLocation(filePath).
 		WithAPIConfig(apiBaseURL, apiKey)
 	
 	return New(config)
@@ -71,6 +115,7 @@ func NewFromSwaggerURL(url, apiBaseURL, apiKey string) (*Server, error) {
 	
 	config := DefaultConfig().
 		WithSwaggerData(data).
+		WithSpecLocation(url).
 		WithAPIConfig(apiBaseURL, apiKey)
 	
 	return New(config)
@@ -103,6 +148,13 @@ func (s *Server) Run(ctx context.Context) error {
 		// Use HTTP transport
 		return s.RunHTTP(ctx, httpTransport.Port)
 	}
+	if both, ok := s.config.Transport.(*StdioAndHTTPTransport); ok {
+		return s.runStdioAndHTTP(ctx, both)
+	}
+	
+	if err := s.startSpecWatch(ctx); err != nil {
+		return err
+	}
 	
 	// Connect using the configured transport (stdio)
 	session, err := s.config.Transport.Connect(ctx, s.mcp.server)
@@ -115,6 +167,51 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
+// runStdioAndHTTP serves stdio and HTTP concurrently until ctx is done or
+// either side stops, then shuts the other down
+func (s *Server) runStdioAndHTTP(ctx context.Context, transport *StdioAndHTTPTransport) error {
+	if err := s.startSpecWatch(ctx); err != nil {
+		return err
+	}
+	
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	httpTransport := transport.HTTP
+	if httpTransport == nil {
+		httpTransport = &HTTPTransport{}
+	}
+	httpServer := NewHTTPServer(s, httpTransport.Port, httpTransport.Host, httpTransport.Path)
+	httpDone := make(chan error, 1)
+	go func() {
+		httpDone <- httpServer.Start(ctx)
+	}()
+	
+	session, err := transport.Connect(ctx, s.mcp.server)
+	if err != nil {
+		cancel()
+		<-httpDone
+		return fmt.Errorf("failed to connect MCP server: %w", err)
+	}
+	stdioDone := make(chan struct{})
+	go func() {
+		_ = session.Wait()
+		close(stdioDone)
+	}()
+	
+	select {
+	case err := <-httpDone:
+		_ = session.Close()
+		return err
+	case <-stdioDone:
+		cancel()
+		return <-httpDone
+	case <-ctx.Done():
+		_ = session.Close()
+		return <-httpDone
+	}
+}
+
 // RunStdio runs the server with stdio transport (for CLI usage)
 func (s *Server) RunStdio(ctx context.Context) error {
 	// Temporarily override transport
@@ -143,12 +240,14 @@ func (s *Server) GenerateSkills(outputDir string) error {
 	return s.mcp.GenerateSkills(outputDir)
 }
 
-// ListTools returns a list of available tools
+// ListTools returns the sorted names of the available tools
 func (s *Server) ListTools() []string {
-	tools := []string{}
-	// This would need to be implemented by extending the underlying MCP server
-	// to expose its tools list
-	return tools
+	tools := s.ListToolsDetailed()
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
 }
 
 // validateConfig validates the server configuration
@@ -173,17 +272,222 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("transport cannot be nil")
 	}
 	
+	if config.ProxyURL != "" {
+		if err := validateProxyURL(config.ProxyURL); err != nil {
+			return err
+		}
+	}
+	
+	if config.OAuth2 != nil {
+		if err := config.OAuth2.validate(); err != nil {
+			return err
+		}
+	}
+	
 	return nil
 }
 
-// inferBaseURL attempts to determine the base URL from swagger spec
+// validateSpec checks that swagger yields at least one tool after filtering,
+// and warns about operations whose tool names will be generated
+func validateSpec(swagger *spec.Swagger, filter *APIFilter) error {
+	if swagger == nil || swagger.Paths == nil || len(swagger.Paths.Paths) == 0 {
+		return fmt.Errorf("spec defines no paths")
+	}
+	
+	total, included := 0, 0
+	for path, pathItem := range swagger.Paths.Paths {
+		for method, op := range map[string]*spec.Operation{
+			"GET":     pathItem.Get,
+			"POST":    pathItem.Post,
+			"PUT":     pathItem.Put,
+			"DELETE":  pathItem.Delete,
+			"PATCH":   pathItem.Patch,
+			"HEAD":    pathItem.Head,
+			"OPTIONS": pathItem.Options,
+		} {
+			if op == nil {
+				continue
+			}
+			total++
+			if !exposesOperation(filter, method, path, op) {
+				continue
+			}
+			included++
+			if err := checkOperationRefs(swagger, op); err != nil {
+				return fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			if op.ID == "" {
+				log.Printf("Warning: %s %s has no operationId; using generated tool name %q", method, path, GenerateToolName(method, path, op))
+			}
+		}
+	}
+	
+	switch {
+	case total == 0:
+		return fmt.Errorf("spec defines %d paths but no GET, POST, PUT, DELETE, PATCH, HEAD or OPTIONS operations", len(swagger.Paths.Paths))
+	case included == 0:
+		return fmt.Errorf("all %d operations are excluded by the API filter; no tools would be registered", total)
+	}
+	return nil
+}
+
+// checkOperationRefs reports a parameter or body schema $ref of op that
+// does not resolve against swagger. Parsed specs have their refs expanded
+// already; this catches specs supplied unexpanded through WithSwaggerSpec.
+func checkOperationRefs(swagger *spec.Swagger, op *spec.Operation) error {
+	for i, param := range op.Parameters {
+		if param.Ref.String() != "" {
+			if _, err := spec.ResolveParameter(swagger, param.Ref); err != nil {
+				return fmt.Errorf("parameter %d has unresolved $ref %q", i, param.Ref.String())
+			}
+			continue
+		}
+		if param.Schema != nil && param.Schema.Ref.String() != "" {
+			if _, err := spec.ResolveRef(swagger, &param.Schema.Ref); err != nil {
+				return fmt.Errorf("parameter %q has unresolved schema $ref %q", param.Name, param.Schema.Ref.String())
+			}
+		}
+	}
+	return nil
+}
+
+// limitTools enforces Config.MaxTools on the tools swagger yields after
+// filtering, counted as registered, so a grouped resource tool counts once.
+// It fails, or with TruncateTools returns a copy of the filter restricted to
+// the operations of the first MaxTools tools by name. Config.Filter itself
+// is never changed.
+func limitTools(config *Config, swagger *spec.Swagger) (*APIFilter, error) {
+	if config.MaxTools <= 0 {
+		return config.Filter, nil
+	}
+	
+	tools := toolOperations(swagger, config.Filter, config.ResourceGrouping)
+	if len(tools) <= config.MaxTools {
+		return config.Filter, nil
+	}
+	if !config.TruncateTools {
+		return nil, fmt.Errorf("spec yields %d tools, more than the limit of %d; exclude paths, tags, methods or operations (or include only the ones you need) to reduce the set", len(tools), config.MaxTools)
+	}
+	
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Printf("Warning: spec yields %d tools, more than the limit of %d; keeping the first %d by name", len(tools), config.MaxTools, config.MaxTools)
+	
+	limited := &APIFilter{}
+	if config.Filter != nil {
+		*limited = *config.Filter
+	}
+	limited.limitedToolNames = make(map[string]bool)
+	for _, name := range names[:config.MaxTools] {
+		for _, operation := range tools[name] {
+			limited.limitedToolNames[operation] = true
+		}
+	}
+	return limited, nil
+}
+
+// toolOperations maps the name of every tool swagger registers under filter
+// to the tool names of the operations it calls: the operation's own name,
+// or with grouping the names of all operations of the resource
+func toolOperations(swagger *spec.Swagger, filter *APIFilter, grouping bool) map[string][]string {
+	groups := make(map[string][]string)
+	for path, pathItem := range swagger.Paths.Paths {
+		for method, op := range map[string]*spec.Operation{
+			"GET":     pathItem.Get,
+			"POST":    pathItem.Post,
+			"PUT":     pathItem.Put,
+			"DELETE":  pathItem.Delete,
+			"PATCH":   pathItem.Patch,
+			"HEAD":    pathItem.Head,
+			"OPTIONS": pathItem.Options,
+		} {
+			if !exposesOperation(filter, method, path, op) {
+				continue
+			}
+			name := GenerateToolName(method, path, op)
+			if grouping {
+				base := resourceBase(path)
+				groups[base] = append(groups[base], name)
+				continue
+			}
+			groups[name] = append(groups[name], name)
+		}
+	}
+	if !grouping {
+		return groups
+	}
+	
+	// As registerResourceTools: a resource with one operation keeps the
+	// operation's own tool
+	tools := make(map[string][]string, len(groups))
+	for base, operations := range groups {
+		if len(operations) == 1 {
+			tools[operations[0]] = operations
+			continue
+		}
+		tools[resourceToolName(base)] = operations
+	}
+	return tools
+}
+
+// apiBaseURL returns the API base URL, which a spec reload may change
+func (s *Server) apiBaseURL() string {
+	s.mcp.mu.RLock()
+	defer s.mcp.mu.RUnlock()
+	return s.config.APIBaseURL
+}
+
+// specBaseURL infers the API base URL from swagger. With keepTemplate a
+// templated OpenAPI 3 server URL keeps its variables for the executor to
+// fill in per call.
+func specBaseURL(swagger *spec.Swagger, keepTemplate bool) string {
+	if template, _ := serverTemplate(swagger); template != "" && keepTemplate {
+		return strings.TrimRight(template, "/")
+	}
+	return inferBaseURL(swagger)
+}
+
+// checkBaseURL checks an API base URL, with swagger's server variables set
+// to their defaults, against the scheme and host restrictions of config
+func checkBaseURL(config *Config, apiBaseURL string, swagger *spec.Swagger) error {
+	_, variables := serverTemplate(swagger)
+	defaultBase, err := expandServerURL(apiBaseURL, variables, nil)
+	if err != nil {
+		return fmt.Errorf("invalid API base URL %q: %w", apiBaseURL, err)
+	}
+	base, err := url.Parse(defaultBase)
+	if err != nil {
+		return fmt.Errorf("invalid API base URL %q: %w", apiBaseURL, err)
+	}
+	if !config.AllowInsecureHTTP {
+		if err := checkSecureScheme(base); err != nil {
+			return fmt.Errorf("invalid API base URL: %w", err)
+		}
+	}
+	if err := checkAllowedHost(config.AllowedHosts, base); err != nil {
+		return fmt.Errorf("invalid API base URL: %w", err)
+	}
+	return nil
+}
+
+// inferBaseURL attempts to determine the base URL from swagger spec. A
+// templated OpenAPI 3 server URL has its variables set to their defaults.
 func inferBaseURL(swagger *spec.Swagger) string {
+	if template, variables := serverTemplate(swagger); template != "" {
+		if base, err := expandServerURL(template, variables, nil); err == nil {
+			return strings.TrimRight(base, "/")
+		}
+	}
 	if swagger.Host != "" {
 		scheme := "https"
 		if len(swagger.Schemes) > 0 {
 			scheme = swagger.Schemes[0]
 		}
-		return fmt.Sprintf("%s://%s%s", scheme, swagger.Host, swagger.BasePath)
+		base := joinURL(fmt.Sprintf("%s://%s", scheme, swagger.Host), swagger.BasePath)
+		return strings.TrimRight(base, "/")
 	}
 	return ""
 }
\ No newline at end of file
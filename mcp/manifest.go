@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolManifest lists the tools a server registers, as written to
+// Config.ToolManifestPath
+type ToolManifest struct {
+	Server  string              `json:"server"`
+	Version string              `json:"version"`
+	Tools   []ToolManifestEntry `json:"tools"`
+}
+
+// ToolManifestEntry is the definition of one registered tool
+type ToolManifestEntry struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Method      string               `json:"method"`
+	Path        string               `json:"path"`
+	InputSchema interface{}          `json:"inputSchema"`
+	Annotations *mcp.ToolAnnotations `json:"annotations,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+}
+
+// toolManifest builds the manifest from the registered tool definitions,
+// sorted by name
+func (s *SwaggerMCPServer) toolManifest() ToolManifest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	manifest := ToolManifest{Tools: []ToolManifestEntry{}}
+	if s.config != nil {
+		manifest.Server = s.config.Name
+		manifest.Version = s.config.Version
+	}
+	for _, registered := range s.tools {
+		manifest.Tools = append(manifest.Tools, ToolManifestEntry{
+			Name:        registered.tool.Name,
+			Description: registered.tool.Description,
+			Method:      registered.method,
+			Path:        registered.path,
+			InputSchema: registered.tool.InputSchema,
+			Annotations: registered.tool.Annotations,
+			Tags:        toolTags(registered.tool),
+		})
+	}
+	sort.Slice(manifest.Tools, func(i, j int) bool { return manifest.Tools[i].Name < manifest.Tools[j].Name })
+	return manifest
+}
+
+// toolTags returns the operation tags recorded in a tool's _meta
+func toolTags(tool *mcp.Tool) []string {
+	tags, _ := tool.Meta[toolMetaTags].([]string)
+	return tags
+}
+
+// writeToolManifest writes the tool manifest to path, replacing the file
+// atomically so readers never see a partial manifest
+func (s *SwaggerMCPServer) writeToolManifest(path string) error {
+	data, err := json.MarshalIndent(s.toolManifest(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize tool manifest: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tool-manifest-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write tool manifest: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write tool manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write tool manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write tool manifest: %w", err)
+	}
+	return nil
+}
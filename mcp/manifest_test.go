@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestToolManifest_WrittenOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tools.json")
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig("http://localhost:1", "").
+		WithServerInfo("pets", "1.2.3", "").
+		WithToolManifestPath(path))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("manifest not written: %v", err)
+	}
+	var manifest ToolManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("invalid manifest JSON: %v", err)
+	}
+	if manifest.Server != "pets" || manifest.Version != "1.2.3" {
+		t.Errorf("server info = %q %q", manifest.Server, manifest.Version)
+	}
+
+	entries := map[string]ToolManifestEntry{}
+	for _, entry := range manifest.Tools {
+		entries[entry.Name] = entry
+	}
+	listPets, ok := entries["listpets"]
+	if !ok || listPets.Method != "GET" || listPets.Path != "/pets" {
+		t.Fatalf("unexpected listpets entry %+v", listPets)
+	}
+	if listPets.Annotations == nil || !listPets.Annotations.ReadOnlyHint {
+		t.Errorf("expected read-only annotations, got %+v", listPets.Annotations)
+	}
+
+	// Entries match what clients receive from tools/list
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := connectTestClient(t, server).ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(result.Tools) != len(manifest.Tools) {
+		t.Fatalf("manifest has %d tools, server %d", len(manifest.Tools), len(result.Tools))
+	}
+	for _, tool := range result.Tools {
+		entry, ok := entries[tool.Name]
+		if !ok {
+			t.Errorf("tool %q missing from the manifest", tool.Name)
+			continue
+		}
+		if entry.Description != tool.Description {
+			t.Errorf("%s: description %q, live %q", tool.Name, entry.Description, tool.Description)
+		}
+		manifestSchema, _ := json.Marshal(entry.InputSchema)
+		liveSchema, _ := json.Marshal(tool.InputSchema)
+		if string(manifestSchema) != string(liveSchema) {
+			t.Errorf("%s: input schema %s, live %s", tool.Name, manifestSchema, liveSchema)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsCollector receives one observation per upstream API call made by
+// a tool. Implement it to feed an existing metrics system; if the collector
+// also implements http.Handler, the HTTP transport serves it at /metrics.
+type MetricsCollector interface {
+	RecordToolCall(toolName string, statusCode int, duration time.Duration, err error)
+}
+
+// durationBuckets are the histogram upper bounds (in seconds) for
+// tool_call_duration_seconds, matching the Prometheus client defaults
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics is a dependency-free MetricsCollector that exposes its counters
+// and histograms in the Prometheus text format
+type Metrics struct {
+	mu             sync.Mutex
+	calls          map[[2]string]uint64 // {tool, status} -> count
+	durations      map[string]*histogram
+	upstreamErrors uint64
+}
+
+type histogram struct {
+	buckets []uint64 // cumulative counts per durationBuckets entry
+	sum     float64
+	count   uint64
+}
+
+// NewMetrics creates an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{
+		calls:     make(map[[2]string]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// RecordToolCall implements MetricsCollector. Transport failures are
+// labelled with status "error" and, like 5xx responses, count as upstream
+// errors.
+func (m *Metrics) RecordToolCall(toolName string, statusCode int, duration time.Duration, err error) {
+	status := strconv.Itoa(statusCode)
+	if err != nil {
+		status = "error"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls[[2]string{toolName, status}]++
+	if err != nil || statusCode >= 500 {
+		m.upstreamErrors++
+	}
+
+	h, ok := m.durations[toolName]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(durationBuckets))}
+		m.durations[toolName] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(m.String()))
+}
+
+// String renders the metrics in the Prometheus text exposition format
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+
+	sb.WriteString("# HELP tool_calls_total Total number of tool calls by tool and upstream status.\n")
+	sb.WriteString("# TYPE tool_calls_total counter\n")
+	keys := make([][2]string, 0, len(m.calls))
+	for key := range m.calls {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, key := range keys {
+		fmt.Fprintf(&sb, "tool_calls_total{tool=%q,status=%q} %d\n", key[0], key[1], m.calls[key])
+	}
+
+	sb.WriteString("# HELP tool_call_duration_seconds Duration of upstream API calls made by tools.\n")
+	sb.WriteString("# TYPE tool_call_duration_seconds histogram\n")
+	tools := make([]string, 0, len(m.durations))
+	for tool := range m.durations {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	for _, tool := range tools {
+		h := m.durations[tool]
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&sb, "tool_call_duration_seconds_bucket{tool=%q,le=%q} %d\n", tool, strconv.FormatFloat(bound, 'g', -1, 64), h.buckets[i])
+		}
+		fmt.Fprintf(&sb, "tool_call_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", tool, h.count)
+		fmt.Fprintf(&sb, "tool_call_duration_seconds_sum{tool=%q} %g\n", tool, h.sum)
+		fmt.Fprintf(&sb, "tool_call_duration_seconds_count{tool=%q} %d\n", tool, h.count)
+	}
+
+	sb.WriteString("# HELP upstream_errors_total Total number of failed or 5xx upstream API calls.\n")
+	sb.WriteString("# TYPE upstream_errors_total counter\n")
+	fmt.Fprintf(&sb, "upstream_errors_total %d\n", m.upstreamErrors)
+
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics_ScrapeAfterCalls(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithMetrics(NewMetrics()))
+
+	callTool(t, server, "listpets", nil)
+	callTool(t, server, "listpets", nil)
+	callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+
+	endpoint, cancel := startHTTPServer(t, server)
+	defer cancel()
+
+	resp, err := http.Get(endpoint + "/metrics")
+	if err != nil {
+		t.Fatalf("failed to scrape /metrics: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/metrics status = %d", resp.StatusCode)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	body := string(data)
+
+	for _, want := range []string{
+		`tool_calls_total{tool="listpets",status="200"} 2`,
+		`tool_calls_total{tool="deletepet",status="500"} 1`,
+		`tool_call_duration_seconds_count{tool="listpets"} 2`,
+		`tool_call_duration_seconds_bucket{tool="deletepet",le="+Inf"} 1`,
+		`upstream_errors_total 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetrics_EndpointDisabledByDefault(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().WithAPIConfig("http://localhost:1", ""))
+
+	endpoint, cancel := startHTTPServer(t, server)
+	defer cancel()
+
+	resp, err := http.Get(endpoint + "/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("/metrics without a collector: status = %d, want 404", resp.StatusCode)
+	}
+}
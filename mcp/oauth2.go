@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// OAuth2ClientCredentials configures bearer tokens obtained with the OAuth2
+// client-credentials grant
+type OAuth2ClientCredentials struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string // May instead come from the SecretProvider as SecretOAuthClientSecret
+	Scopes       []string
+}
+
+// validate checks that the grant can be attempted
+func (c *OAuth2ClientCredentials) validate() error {
+	if c.TokenURL == "" || c.ClientID == "" {
+		return fmt.Errorf("OAuth2 client credentials require a token URL and client ID")
+	}
+	return nil
+}
+
+// clientCredentialsSource fetches a new token on every call, reading the
+// client secret from the secret provider each time so rotations apply
+type clientCredentialsSource struct {
+	config  clientcredentials.Config
+	secrets SecretProvider
+	client  *http.Client
+}
+
+// Token implements oauth2.TokenSource
+func (s *clientCredentialsSource) Token() (*oauth2.Token, error) {
+	secret, err := lookupSecret(s.secrets, SecretOAuthClientSecret)
+	if err != nil {
+		return nil, err
+	}
+	config := s.config
+	config.ClientSecret = secret
+
+	// The token endpoint is reached through the same client (proxy, TLS)
+	// as the API
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, s.client)
+	token, err := config.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain OAuth2 token: %w", err)
+	}
+	return token, nil
+}
+
+// newOAuth2TokenSource returns a token source that caches the current token
+// and refreshes it shortly before it expires. It is safe for concurrent use,
+// so all tool calls share one token.
+func newOAuth2TokenSource(creds *OAuth2ClientCredentials, secrets SecretProvider, client *http.Client) oauth2.TokenSource {
+	source := &clientCredentialsSource{
+		config: clientcredentials.Config{
+			ClientID: creds.ClientID,
+			TokenURL: creds.TokenURL,
+			Scopes:   creds.Scopes,
+		},
+		secrets: ChainSecrets{secrets, StaticSecrets{SecretOAuthClientSecret: creds.ClientSecret}},
+		client:  client,
+	}
+	return oauth2.ReuseTokenSource(nil, source)
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOAuth2ClientCredentials_FetchCacheRefresh(t *testing.T) {
+	var fetches int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("bad token request: %v", err)
+		}
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("scope") != "pets:read pets:write" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "s3cret" {
+			t.Errorf("unexpected client credentials %q:%q", id, secret)
+		}
+		n := atomic.AddInt32(&fetches, 1)
+		// Valid for about a second once the refresh margin is taken off
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 11}`, n)
+	}))
+	defer tokenServer.Close()
+
+	var mu sync.Mutex
+	var authorizations []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithOAuth2ClientCredentials(tokenServer.URL, "client", "s3cret", []string{"pets:read", "pets:write"}))
+
+	// Concurrent calls share a single token
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := server.CallTool(t.Context(), "listpets", nil); err != nil {
+				t.Errorf("CallTool failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Fatalf("expected one token fetch, got %d", n)
+	}
+	for _, authorization := range authorizations {
+		if authorization != "Bearer token-1" {
+			t.Errorf("expected the cached token, got %q", authorization)
+		}
+	}
+
+	// After expiry the token is refreshed
+	time.Sleep(1500 * time.Millisecond)
+	if _, _, err := server.CallTool(t.Context(), "listpets", nil); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Fatalf("expected the token to be refreshed, got %d fetches", n)
+	}
+	if last := authorizations[len(authorizations)-1]; last != "Bearer token-2" {
+		t.Errorf("expected the refreshed token, got %q", last)
+	}
+}
+
+func TestOAuth2ClientCredentials_TokenErrorFailsCall(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": "invalid_client"}`, http.StatusUnauthorized)
+	}))
+	defer tokenServer.Close()
+
+	var hits int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithSecretProvider(StaticSecrets{SecretOAuthClientSecret: "wrong"}).
+		WithOAuth2ClientCredentials(tokenServer.URL, "client", "", nil))
+	if _, _, err := server.CallTool(t.Context(), "listpets", nil); err == nil {
+		t.Fatal("expected the token error to fail the call")
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Error("the API was called without a token")
+	}
+}
@@ -1,8 +1,11 @@
 package mcp
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi2conv"
@@ -23,14 +26,20 @@ func isOpenAPI3(jsonData []byte) bool {
 // convertOpenAPI3ToSwagger2 converts an OpenAPI 3.x JSON document to a
 // Swagger 2.0 JSON document so the rest of the pipeline (go-openapi/spec)
 // can consume it. OpenAPI 3.1 documents are first normalized to 3.0.
-func convertOpenAPI3ToSwagger2(jsonData []byte) ([]byte, error) {
+func convertOpenAPI3ToSwagger2(jsonData []byte, refs *refLoader) ([]byte, error) {
 	jsonData, err := normalizeOpenAPI31(jsonData)
 	if err != nil {
 		return nil, err
 	}
 
 	loader := openapi3.NewLoader()
-	doc, err := loader.LoadFromData(jsonData)
+	loader.ReadFromURIFunc = refs.readURI
+	var doc *openapi3.T
+	if base := refs.baseURL(); base != nil {
+		doc, err = loader.LoadFromDataWithPath(jsonData, base)
+	} else {
+		doc, err = loader.LoadFromData(jsonData)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse OpenAPI 3 spec: %w", err)
 	}
@@ -45,20 +54,175 @@ func convertOpenAPI3ToSwagger2(jsonData []byte) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert OpenAPI 3 to Swagger 2.0: %w", err)
 	}
+	setProducesFromResponses(doc, v2)
+	setQueryParameterStyles(doc, v2)
+	setServerVariables(doc, v2)
 
 	out, err := json.Marshal(v2)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize converted spec: %w", err)
 	}
-	return out, nil
+	return restoreSchemaVariants(out)
+}
+
+// setProducesFromResponses fills each converted operation's produces with
+// the media types of its 3.0 responses, which FromV3 drops. Success
+// responses are listed first so their types lead the Accept header.
+func setProducesFromResponses(doc *openapi3.T, v2 *openapi2.T) {
+	if doc.Paths == nil {
+		return
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		v2Item := v2.Paths[path]
+		if v2Item == nil {
+			continue
+		}
+		v2Ops := v2Item.Operations()
+		for method, op := range pathItem.Operations() {
+			v2Op := v2Ops[method]
+			if v2Op == nil || len(v2Op.Produces) > 0 || op.Responses == nil {
+				continue
+			}
+
+			responses := op.Responses.Map()
+			codes := make([]string, 0, len(responses))
+			for code := range responses {
+				codes = append(codes, code)
+			}
+			sort.Slice(codes, func(i, j int) bool {
+				iSuccess, jSuccess := strings.HasPrefix(codes[i], "2"), strings.HasPrefix(codes[j], "2")
+				if iSuccess != jSuccess {
+					return iSuccess
+				}
+				return codes[i] < codes[j]
+			})
+
+			seen := map[string]bool{}
+			for _, code := range codes {
+				response := responses[code]
+				if response == nil || response.Value == nil {
+					continue
+				}
+				mediaTypes := make([]string, 0, len(response.Value.Content))
+				for mediaType := range response.Value.Content {
+					mediaTypes = append(mediaTypes, mediaType)
+				}
+				sort.Strings(mediaTypes)
+				for _, mediaType := range mediaTypes {
+					if !seen[mediaType] {
+						seen[mediaType] = true
+						v2Op.Produces = append(v2Op.Produces, mediaType)
+					}
+				}
+			}
+		}
+	}
+}
+
+// setServerVariables records the first server's URL template and variables,
+// which FromV3 drops (it cannot even parse a templated host), as x-server-url
+// and x-server-variables extensions
+func setServerVariables(doc *openapi3.T, v2 *openapi2.T) {
+	if len(doc.Servers) == 0 || doc.Servers[0] == nil || len(doc.Servers[0].Variables) == 0 {
+		return
+	}
+	server := doc.Servers[0]
+	variables := make(map[string]ServerVariable, len(server.Variables))
+	for name, variable := range server.Variables {
+		if variable == nil {
+			continue
+		}
+		variables[name] = ServerVariable{
+			Default:     variable.Default,
+			Enum:        variable.Enum,
+			Description: variable.Description,
+		}
+	}
+	if v2.Extensions == nil {
+		v2.Extensions = make(map[string]any)
+	}
+	v2.Extensions[extServerURL] = server.URL
+	v2.Extensions[extServerVariables] = variables
+}
+
+// setQueryParameterStyles carries the style and explode settings of 3.0
+// query parameters, which FromV3 drops, over to the converted parameters:
+// arrays get the equivalent collectionFormat, other parameters x-style and
+// x-explode extensions
+func setQueryParameterStyles(doc *openapi3.T, v2 *openapi2.T) {
+	if doc.Paths == nil {
+		return
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		v2Item := v2.Paths[path]
+		if v2Item == nil {
+			continue
+		}
+		copyQueryParameterStyles(pathItem.Parameters, v2Item.Parameters)
+		v2Ops := v2Item.Operations()
+		for method, op := range pathItem.Operations() {
+			if v2Op := v2Ops[method]; v2Op != nil {
+				copyQueryParameterStyles(op.Parameters, v2Op.Parameters)
+			}
+		}
+	}
+}
+
+// copyQueryParameterStyles copies the style settings of params to the
+// converted query parameters of the same name
+func copyQueryParameterStyles(params openapi3.Parameters, v2Params openapi2.Parameters) {
+	for _, ref := range params {
+		if ref == nil || ref.Value == nil || ref.Value.In != openapi3.ParameterInQuery {
+			continue
+		}
+		param := ref.Value
+		style := param.Style
+		if style == "" {
+			style = openapi3.SerializationForm
+		}
+		explode := style == openapi3.SerializationForm
+		if param.Explode != nil {
+			explode = *param.Explode
+		}
+
+		for _, v2Param := range v2Params {
+			if v2Param == nil || v2Param.In != "query" || v2Param.Name != param.Name {
+				continue
+			}
+			if v2Param.Type != nil && v2Param.Type.Is("array") {
+				switch {
+				case style == openapi3.SerializationSpaceDelimited:
+					v2Param.CollectionFormat = "ssv"
+				case style == openapi3.SerializationPipeDelimited:
+					v2Param.CollectionFormat = "pipes"
+				case explode:
+					v2Param.CollectionFormat = "multi"
+				default:
+					v2Param.CollectionFormat = "csv"
+				}
+				continue
+			}
+			if v2Param.Extensions == nil {
+				v2Param.Extensions = make(map[string]any)
+			}
+			v2Param.Extensions["x-style"] = style
+			v2Param.Extensions["x-explode"] = explode
+		}
+	}
 }
 
 // normalizeOpenAPI31 rewrites OpenAPI 3.1-only constructs into their 3.0
 // equivalents so kin-openapi can load the document:
 //   - "openapi": "3.1.x"            -> "3.0.3"
 //   - "type": ["T", "null"]         -> "type": "T", "nullable": true
-//   - schema-level "examples": [x]  -> "example": x
-//   - "const": v                    -> "enum": [v]
+//   - schema-level "examples": [x]  -> "example": x, "x-examples": [x]
+//   - "const": v                    -> "enum": [v], "x-const": v
+//   - "webhooks"                    -> removed (not exposed as tools)
+//
+// The x- extensions survive the conversion to Swagger 2.0 and are turned
+// back into JSON Schema keywords when tool input schemas are built. In
+// every 3.x document, oneOf, anyOf and discriminator are also moved into
+// extensions, which FromV3 would otherwise drop (see markSchemaVariants).
 func normalizeOpenAPI31(jsonData []byte) ([]byte, error) {
 	var doc map[string]interface{}
 	if err := json.Unmarshal(jsonData, &doc); err != nil {
@@ -67,15 +231,71 @@ func normalizeOpenAPI31(jsonData []byte) ([]byte, error) {
 
 	version, _ := doc["openapi"].(string)
 	if len(version) < 3 || version[:3] != "3.1" {
-		return jsonData, nil // already 3.0.x
+		// Already 3.0.x, but nullable parameters and schema variants still
+		// need carrying over
+		nullable := carryParameterNullable(doc)
+		if !markSchemaVariants(doc) && !nullable {
+			return jsonData, nil
+		}
+		return json.Marshal(doc)
 	}
 
 	doc["openapi"] = "3.0.3"
+	delete(doc, "webhooks")
 	normalizeSchemaNode(doc)
+	markSchemaVariants(doc)
 
 	return json.Marshal(doc)
 }
 
+// carryParameterExtensions copies a non-body parameter's schema extensions
+// (and "nullable" as x-nullable) onto the parameter itself: flattening it
+// into a Swagger 2.0 parameter drops its schema's extensions. It reports
+// whether node is such a parameter with a nullable schema.
+func carryParameterExtensions(node map[string]interface{}) bool {
+	if _, isParam := node["in"].(string); !isParam {
+		return false
+	}
+	schema, ok := node["schema"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, key := range []string{"x-const", "x-examples"} {
+		if value, ok := schema[key]; ok {
+			node[key] = value
+		}
+	}
+	if schema["nullable"] == true {
+		node["x-nullable"] = true
+		return true
+	}
+	return false
+}
+
+// carryParameterNullable marks the nullable parameters of an OpenAPI 3.0
+// document with x-nullable, reporting whether there were any
+func carryParameterNullable(node interface{}) bool {
+	found := false
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if carryParameterExtensions(v) {
+			found = true
+		}
+		for _, child := range v {
+			if carryParameterNullable(child) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if carryParameterNullable(child) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
 // normalizeSchemaNode walks the document tree and rewrites 3.1 schema
 // keywords in place. It is intentionally permissive: it applies the schema
 // rewrites wherever the shapes match, which is safe for the keywords below.
@@ -104,6 +324,7 @@ func normalizeSchemaNode(node interface{}) {
 		// "const": x -> "enum": [x]
 		if c, ok := v["const"]; ok {
 			v["enum"] = []interface{}{c}
+			v["x-const"] = c
 			delete(v, "const")
 		}
 
@@ -113,15 +334,183 @@ func normalizeSchemaNode(node interface{}) {
 			if _, hasExample := v["example"]; !hasExample {
 				v["example"] = examples[0]
 			}
+			v["x-examples"] = examples
 			delete(v, "examples")
 		}
 
 		for _, child := range v {
 			normalizeSchemaNode(child)
 		}
+
+		carryParameterExtensions(v)
 	case []interface{}:
 		for _, child := range v {
 			normalizeSchemaNode(child)
 		}
 	}
 }
+
+// schemaVariantKeys maps the schema keywords FromV3 drops to the
+// extensions that carry them through the conversion
+var schemaVariantKeys = map[string]string{
+	"oneOf":         "x-oneOf",
+	"anyOf":         "x-anyOf",
+	"discriminator": "x-discriminator",
+}
+
+// markSchemaVariants moves the oneOf, anyOf and discriminator keywords of
+// every schema in an OpenAPI 3 document into extensions, reporting whether
+// there were any. Swagger 2.0 has no place for them, so FromV3 would leave
+// a polymorphic body as an empty object.
+func markSchemaVariants(node interface{}) bool {
+	found := false
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, extension := range schemaVariantKeys {
+			value, ok := v[key]
+			if !ok {
+				continue
+			}
+			if !isSchemaVariantKeyword(key, value) {
+				continue
+			}
+			v[extension] = value
+			delete(v, key)
+			found = true
+		}
+		for _, child := range v {
+			if markSchemaVariants(child) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if markSchemaVariants(child) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+// isSchemaVariantKeyword reports whether value has the shape of the schema
+// keyword key rather than, say, a property that happens to share its name
+func isSchemaVariantKeyword(key string, value interface{}) bool {
+	if key == "discriminator" {
+		discriminator, _ := value.(map[string]interface{})
+		_, ok := discriminator["propertyName"].(string)
+		return ok
+	}
+	_, ok := value.([]interface{})
+	return ok
+}
+
+// restoreSchemaVariants turns the extensions markSchemaVariants added back
+// into keywords of a converted Swagger 2.0 document: oneOf and anyOf, with
+// their component $refs pointing at definitions, and the discriminator's
+// property name in the Swagger 2.0 string form.
+func restoreSchemaVariants(data []byte) ([]byte, error) {
+	marked := false
+	for _, extension := range schemaVariantKeys {
+		if bytes.Contains(data, []byte(`"`+extension+`"`)) {
+			marked = true
+		}
+	}
+	if !marked {
+		return data, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse converted spec: %w", err)
+	}
+	restoreSchemaNode(doc)
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize converted spec: %w", err)
+	}
+	return out, nil
+}
+
+// restoreSchemaNode rewrites the variant extensions of node and everything
+// below it
+func restoreSchemaNode(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		discriminator, _ := v["x-discriminator"].(map[string]interface{})
+		for _, key := range []string{"oneOf", "anyOf"} {
+			if variants, ok := v[schemaVariantKeys[key]]; ok {
+				pinDiscriminator(variants, discriminator)
+				convertComponentRefs(variants)
+				v[key] = variants
+				delete(v, schemaVariantKeys[key])
+			}
+		}
+		if discriminator != nil {
+			if name, _ := discriminator["propertyName"].(string); name != "" {
+				v["discriminator"] = name
+			}
+			delete(v, "x-discriminator")
+		}
+		for _, child := range v {
+			restoreSchemaNode(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			restoreSchemaNode(child)
+		}
+	}
+}
+
+// pinDiscriminator restricts the discriminator property of each $ref
+// variant to the value that selects it: its key in the discriminator
+// mapping, else the name of the component schema. Without the pin, variants
+// that differ only in that value all match, which fails oneOf validation.
+func pinDiscriminator(variants interface{}, discriminator map[string]interface{}) {
+	name, _ := discriminator["propertyName"].(string)
+	list, _ := variants.([]interface{})
+	if name == "" {
+		return
+	}
+	mapping, _ := discriminator["mapping"].(map[string]interface{})
+
+	for i, variant := range list {
+		variant, _ := variant.(map[string]interface{})
+		ref, _ := variant["$ref"].(string)
+		if ref == "" {
+			continue
+		}
+		schemaName := ref[strings.LastIndex(ref, "/")+1:]
+		value := schemaName
+		for key, target := range mapping {
+			if target == ref || target == schemaName {
+				value = key
+				break
+			}
+		}
+		pin := map[string]interface{}{
+			"properties": map[string]interface{}{
+				name: map[string]interface{}{"type": "string", "enum": []interface{}{value}},
+			},
+		}
+		list[i] = map[string]interface{}{"allOf": []interface{}{pin, variant}}
+	}
+}
+
+// convertComponentRefs points the OpenAPI 3 component $refs below node at
+// their Swagger 2.0 equivalents, which FromV3 does only outside extensions
+func convertComponentRefs(node interface{}) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			v["$ref"] = openapi2conv.FromV3Ref(ref)
+		}
+		for _, child := range v {
+			convertComponentRefs(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			convertComponentRefs(child)
+		}
+	}
+}
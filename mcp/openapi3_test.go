@@ -1,7 +1,11 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -229,3 +233,353 @@ func TestParseSwaggerSpec_NestedBodySchema(t *testing.T) {
 		t.Errorf("array items schema lost: %v", body)
 	}
 }
+
+// TestParseSwaggerSpec_AllOfBodySchema verifies that a body composed with
+// allOf exposes the merged properties and required fields of its parts.
+func TestParseSwaggerSpec_AllOfBodySchema(t *testing.T) {
+	specData := `{
+	  "swagger": "2.0",
+	  "info": {"title": "Composed", "version": "1.0"},
+	  "paths": {
+	    "/profiles": {
+	      "post": {
+	        "operationId": "create_profile",
+	        "parameters": [
+	          {"name": "body", "in": "body", "required": true,
+	           "schema": {
+	             "allOf": [
+	               {"$ref": "#/definitions/Profile"},
+	               {"type": "object", "properties": {"delay": {"type": "integer"}}},
+	               {"allOf": [{"properties": {"keep_masked": {"type": "boolean"}}}]}
+	             ]
+	           }}
+	        ],
+	        "responses": {"201": {"description": "Created"}}
+	      }
+	    }
+	  },
+	  "definitions": {
+	    "Profile": {
+	      "type": "object",
+	      "required": ["id"],
+	      "properties": {
+	        "id": {"type": "string"},
+	        "node": {"type": "string"}
+	      }
+	    }
+	  }
+	}`
+	schema := bodySchemaOf(t, specData)
+	requireBodyFields(t, schema)
+
+	body := schema["properties"].(map[string]interface{})["body"].(map[string]interface{})
+	if _, ok := body["allOf"]; ok {
+		t.Errorf("allOf left in merged body schema: %v", body)
+	}
+	if body["type"] != "object" {
+		t.Errorf("merged body type = %v, want object", body["type"])
+	}
+}
+
+// TestParseSwaggerSpec_OpenAPI3OneOfBody verifies that an OpenAPI 3 body
+// with oneOf variants and a discriminator keeps each variant schema
+// instead of collapsing to an empty object.
+func TestParseSwaggerSpec_OpenAPI3OneOfBody(t *testing.T) {
+	specData := `{
+	  "openapi": "3.0.3",
+	  "info": {"title": "Polymorphic", "version": "1.0"},
+	  "paths": {
+	    "/profiles": {
+	      "post": {
+	        "operationId": "create_profile",
+	        "requestBody": {
+	          "required": true,
+	          "content": {
+	            "application/json": {
+	              "schema": {
+	                "oneOf": [
+	                  {"$ref": "#/components/schemas/Cat"},
+	                  {"$ref": "#/components/schemas/Dog"}
+	                ],
+	                "discriminator": {
+	                  "propertyName": "petType",
+	                  "mapping": {"dog": "#/components/schemas/Dog"}
+	                }
+	              }
+	            }
+	          }
+	        },
+	        "responses": {"201": {"description": "Created"}}
+	      }
+	    }
+	  },
+	  "components": {
+	    "schemas": {
+	      "Cat": {
+	        "type": "object",
+	        "required": ["petType"],
+	        "properties": {
+	          "petType": {"type": "string"},
+	          "indoor": {"type": "boolean"}
+	        }
+	      },
+	      "Dog": {
+	        "type": "object",
+	        "required": ["petType"],
+	        "properties": {
+	          "petType": {"type": "string"},
+	          "bark": {"anyOf": [{"type": "string"}, {"type": "integer"}]}
+	        }
+	      }
+	    }
+	  }
+	}`
+	schema := bodySchemaOf(t, specData)
+
+	props, _ := schema["properties"].(map[string]interface{})
+	body, _ := props["body"].(map[string]interface{})
+	variants, _ := body["oneOf"].([]interface{})
+	if len(variants) != 2 {
+		t.Fatalf("body oneOf = %v, want the Cat and Dog variants", body)
+	}
+	if body["discriminator"] != "petType" {
+		t.Errorf("body discriminator = %v, want petType", body["discriminator"])
+	}
+
+	cat, _ := variants[0].(map[string]interface{})
+	catProps, _ := cat["properties"].(map[string]interface{})
+	if _, ok := catProps["indoor"]; !ok {
+		t.Errorf("Cat variant lost its properties: %v", cat)
+	}
+	// Each variant only accepts the discriminator value that selects it
+	petType, _ := catProps["petType"].(map[string]interface{})
+	if enum, _ := petType["enum"].([]interface{}); len(enum) != 1 || enum[0] != "Cat" {
+		t.Errorf("Cat variant petType = %v, want enum [Cat]", petType)
+	}
+	dog, _ := variants[1].(map[string]interface{})
+	dogProps, _ := dog["properties"].(map[string]interface{})
+	bark, _ := dogProps["bark"].(map[string]interface{})
+	if alternatives, _ := bark["anyOf"].([]interface{}); len(alternatives) != 2 {
+		t.Errorf("Dog variant lost the anyOf of bark: %v", dog)
+	}
+
+	// A call with one of the variants goes through to the API
+	var received map[string]interface{}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(specData)).WithAPIConfig(backend.URL, ""))
+	result := callTool(t, server, "create_profile", map[string]any{
+		"body": map[string]any{"petType": "dog", "bark": "woof"},
+	})
+	if result.IsError {
+		t.Fatalf("call failed: %s", resultText(t, result))
+	}
+	if received["bark"] != "woof" {
+		t.Errorf("API received %v, want the Dog body", received)
+	}
+}
+
+// TestParseSwaggerSpec_OpenAPI30Nullable verifies that OpenAPI 3.0
+// "nullable: true" lets parameters and nested body properties take null.
+func TestParseSwaggerSpec_OpenAPI30Nullable(t *testing.T) {
+	specData := `{
+	  "openapi": "3.0.3",
+	  "info": {"title": "profiles", "version": "1.0"},
+	  "paths": {
+	    "/profiles": {
+	      "post": {
+	        "operationId": "create_profile",
+	        "parameters": [
+	          {"name": "label", "in": "query", "schema": {"type": "string", "nullable": true}},
+	          {"name": "owner", "in": "query", "schema": {"type": "string"}}
+	        ],
+	        "requestBody": {
+	          "content": {
+	            "application/json": {
+	              "schema": {
+	                "type": "object",
+	                "properties": {
+	                  "node": {"type": "string", "nullable": true},
+	                  "address": {
+	                    "type": "object",
+	                    "properties": {"zip": {"type": "integer", "nullable": true}}
+	                  }
+	                }
+	              }
+	            }
+	          }
+	        },
+	        "responses": {"201": {"description": "Created"}}
+	      }
+	    }
+	  }
+	}`
+	schema := bodySchemaOf(t, specData)
+	props, _ := schema["properties"].(map[string]interface{})
+
+	label, _ := props["label"].(map[string]interface{})
+	assertTypes(t, "label", label["type"], "string", "null")
+	if owner, _ := props["owner"].(map[string]interface{}); owner["type"] != "string" {
+		t.Errorf("owner is not nullable, got type %v", owner["type"])
+	}
+
+	body, _ := props["body"].(map[string]interface{})
+	bodyProps, _ := body["properties"].(map[string]interface{})
+	node, _ := bodyProps["node"].(map[string]interface{})
+	assertTypes(t, "node", node["type"], "string", "null")
+
+	address, _ := bodyProps["address"].(map[string]interface{})
+	addressProps, _ := address["properties"].(map[string]interface{})
+	zip, _ := addressProps["zip"].(map[string]interface{})
+	assertTypes(t, "address.zip", zip["type"], "integer", "null")
+
+	// The argument validator accepts null where allowed
+	if err := validateArguments(schema, map[string]interface{}{"label": nil, "body": map[string]interface{}{"node": nil}}, argumentChecks{}); err != nil {
+		t.Errorf("null rejected for nullable fields: %v", err)
+	}
+}
+
+// TestParseSwaggerSpec_OpenAPI31JSONSchema verifies that JSON Schema 2020-12
+// keywords survive conversion: nullable and multi-typed fields keep an array
+// "type", and "const"/"examples" are preserved. Webhooks are ignored.
+func TestParseSwaggerSpec_OpenAPI31JSONSchema(t *testing.T) {
+	specData := `{
+	  "openapi": "3.1.0",
+	  "info": {"title": "profiles", "version": "1.0"},
+	  "paths": {
+	    "/profiles": {
+	      "post": {
+	        "operationId": "create_profile",
+	        "parameters": [
+	          {"name": "label", "in": "query", "schema": {"type": ["string", "null"]}}
+	        ],
+	        "requestBody": {
+	          "content": {
+	            "application/json": {
+	              "schema": {
+	                "type": "object",
+	                "properties": {
+	                  "node": {"type": ["string", "null"]},
+	                  "kind": {"type": "string", "const": "drbd"},
+	                  "delay": {"type": "integer", "examples": [5, 10]},
+	                  "size": {"type": ["string", "integer"]}
+	                }
+	              }
+	            }
+	          }
+	        },
+	        "responses": {"201": {"description": "Created"}}
+	      }
+	    }
+	  },
+	  "webhooks": {
+	    "profileCreated": {
+	      "post": {
+	        "requestBody": {"content": {"application/json": {"schema": {"type": "object"}}}},
+	        "responses": {"200": {"description": "OK"}}
+	      }
+	    }
+	  }
+	}`
+	schema := bodySchemaOf(t, specData)
+	props, _ := schema["properties"].(map[string]interface{})
+
+	label, _ := props["label"].(map[string]interface{})
+	assertTypes(t, "label", label["type"], "string", "null")
+
+	body, _ := props["body"].(map[string]interface{})
+	bodyProps, _ := body["properties"].(map[string]interface{})
+
+	node, _ := bodyProps["node"].(map[string]interface{})
+	assertTypes(t, "node", node["type"], "string", "null")
+
+	size, _ := bodyProps["size"].(map[string]interface{})
+	assertTypes(t, "size", size["type"], "string", "integer")
+
+	kind, _ := bodyProps["kind"].(map[string]interface{})
+	if kind["const"] != "drbd" {
+		t.Errorf("kind lost its const: %v", kind)
+	}
+
+	delay, _ := bodyProps["delay"].(map[string]interface{})
+	examples, _ := delay["examples"].([]interface{})
+	if len(examples) != 2 || examples[0] != float64(5) || examples[1] != float64(10) {
+		t.Errorf("delay lost its examples: %v", delay)
+	}
+
+	for name, prop := range bodyProps {
+		for key := range prop.(map[string]interface{}) {
+			if strings.HasPrefix(key, "x-") {
+				t.Errorf("%s: vendor extension %q leaked into the tool schema", name, key)
+			}
+		}
+	}
+}
+
+// assertTypes checks that a JSON schema "type" is an array of exactly want.
+func assertTypes(t *testing.T, field string, got interface{}, want ...string) {
+	t.Helper()
+
+	types, ok := got.([]interface{})
+	if !ok || len(types) != len(want) {
+		t.Errorf("%s: type = %v, want %v", field, got, want)
+		return
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("%s: type = %v, want %v", field, got, want)
+			return
+		}
+	}
+}
+
+func TestServerVariables_SubstitutedFromArgs(t *testing.T) {
+	var gotPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	spec := `{
+  "openapi": "3.0.0",
+  "info": {"title": "Regional API", "version": "1.0.0"},
+  "servers": [{
+    "url": "http://{host}/{region}/v1",
+    "variables": {
+      "host": {"default": "` + strings.TrimPrefix(backend.URL, "http://") + `"},
+      "region": {"default": "us", "enum": ["us", "eu"], "description": "Data region"}
+    }
+  }],
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+	// Without the option the defaults are used
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)))
+	if _, _, err := server.CallTool(context.Background(), "listpets", nil); err != nil || gotPath != "/us/v1/pets" {
+		t.Errorf("default server URL: path %q, err %v", gotPath, err)
+	}
+
+	server = newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithServerVariableArgs(true))
+	properties := server.mcp.toolInputSchema("listpets")["properties"].(map[string]interface{})
+	if region, _ := properties["_server_region"].(map[string]interface{}); region["default"] != "us" || region["description"] != "Data region" {
+		t.Errorf("_server_region property = %v", properties["_server_region"])
+	}
+
+	result := callTool(t, server, "listpets", map[string]any{"_server_region": "eu"})
+	if result.IsError || gotPath != "/eu/v1/pets" {
+		t.Errorf("_server_region=eu: path %q, result %s", gotPath, resultText(t, result))
+	}
+	if _, _, err := server.CallTool(context.Background(), "listpets", nil); err != nil || gotPath != "/us/v1/pets" {
+		t.Errorf("omitted variable: path %q, err %v", gotPath, err)
+	}
+	if _, _, err := server.CallTool(context.Background(), "listpets", map[string]interface{}{"_server_region": "ap"}); err == nil {
+		t.Error("expected a value outside the enum to be rejected")
+	}
+}
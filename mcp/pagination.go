@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// PaginationParams names an operation's own pagination parameters that the
+// uniform limit/offset/page tool arguments map to. Empty fields are left
+// unmapped.
+type PaginationParams struct {
+	Limit  string `json:"limit,omitempty"`
+	Offset string `json:"offset,omitempty"`
+	Page   string `json:"page,omitempty"`
+}
+
+// Common spellings of pagination parameters, checked in order
+var (
+	limitParamNames  = []string{"limit", "maxResults", "max_results", "pageSize", "page_size", "per_page", "perPage", "size", "count", "$top", "top", "first", "take"}
+	offsetParamNames = []string{"offset", "skip", "$skip", "start", "startIndex", "start_index", "from"}
+	pageParamNames   = []string{"page", "pageNumber", "page_number", "pageNo", "pageIndex"}
+)
+
+// paginationParams returns the pagination mapping for an operation, using
+// an explicit override for the tool if one is configured
+func (e *APIExecutor) paginationParams(method, path string, op *spec.Operation) PaginationParams {
+	if override, ok := e.PaginationOverrides[GenerateToolName(method, path, op)]; ok {
+		return override
+	}
+	return detectPaginationParams(op)
+}
+
+// detectPaginationParams heuristically finds an operation's pagination
+// query parameters by name
+func detectPaginationParams(op *spec.Operation) PaginationParams {
+	var query []string
+	for _, param := range op.Parameters {
+		if param.In == "query" {
+			query = append(query, param.Name)
+		}
+	}
+
+	return PaginationParams{
+		Limit:  findParamName(query, limitParamNames),
+		Offset: findParamName(query, offsetParamNames),
+		Page:   findParamName(query, pageParamNames),
+	}
+}
+
+// findParamName returns the first declared parameter matching one of the
+// candidate names (case-insensitive)
+func findParamName(declared, candidates []string) string {
+	for _, candidate := range candidates {
+		for _, name := range declared {
+			if strings.EqualFold(name, candidate) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// aliases returns the uniform argument name for each mapped parameter
+func (p PaginationParams) aliases() map[string]string {
+	aliases := map[string]string{}
+	if p.Limit != "" {
+		aliases["limit"] = p.Limit
+	}
+	if p.Offset != "" {
+		aliases["offset"] = p.Offset
+	}
+	if p.Page != "" {
+		aliases["page"] = p.Page
+	}
+	return aliases
+}
+
+// applyPaginationAliases renames uniform pagination arguments to the
+// operation's parameter names. Values already given under the real
+// parameter name win.
+func applyPaginationAliases(args map[string]interface{}, params PaginationParams) {
+	for alias, name := range params.aliases() {
+		if alias == name {
+			continue
+		}
+		value, ok := args[alias]
+		if !ok {
+			continue
+		}
+		delete(args, alias)
+		if _, exists := args[name]; !exists {
+			args[name] = value
+		}
+	}
+}
+
+// addPaginationAliasProperties exposes the uniform pagination arguments in a
+// tool input schema for the parameters the operation supports
+func addPaginationAliasProperties(schema map[string]interface{}, params PaginationParams) {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	descriptions := map[string]string{
+		"limit":  "Maximum number of results to return",
+		"offset": "Number of results to skip",
+		"page":   "Page number to return",
+	}
+	for alias, name := range params.aliases() {
+		if _, exists := properties[alias]; exists {
+			continue
+		}
+		properties[alias] = map[string]interface{}{
+			"type":        "number",
+			"description": descriptions[alias] + " (alias for " + name + ")",
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const paginationTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Search API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {
+        "operationId": "listItems",
+        "parameters": [
+          {"name": "maxResults", "in": "query", "type": "integer"},
+          {"name": "startIndex", "in": "query", "type": "integer"}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/events": {
+      "get": {
+        "operationId": "listEvents",
+        "parameters": [{"name": "n", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+func TestPaginationAliases_MapsLimitToMaxResults(t *testing.T) {
+	var query map[string][]string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(paginationTestSwagger)).
+		WithAPIConfig(backend.URL, "").
+		WithPaginationAliases(nil))
+
+	callTool(t, server, "listitems", map[string]any{"limit": 5, "offset": 10})
+
+	if got := query["maxResults"]; len(got) != 1 || got[0] != "5" {
+		t.Errorf("maxResults = %v, want [5]", got)
+	}
+	if got := query["startIndex"]; len(got) != 1 || got[0] != "10" {
+		t.Errorf("startIndex = %v, want [10]", got)
+	}
+	if _, ok := query["limit"]; ok {
+		t.Error("alias argument limit should not be forwarded")
+	}
+}
+
+func TestPaginationAliases_ExplicitOverride(t *testing.T) {
+	var query map[string][]string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(paginationTestSwagger)).
+		WithAPIConfig(backend.URL, "").
+		WithPaginationAliases(map[string]PaginationParams{"listevents": {Limit: "n"}}))
+
+	callTool(t, server, "listevents", map[string]any{"limit": 3})
+
+	if got := query["n"]; len(got) != 1 || got[0] != "3" {
+		t.Errorf("n = %v, want [3]", got)
+	}
+}
+
+func TestPaginationAliases_SchemaExposesAliases(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(paginationTestSwagger)).
+		WithPaginationAliases(nil))
+
+	s := server.GetMCPServer()
+	op := s.swagger.Paths.Paths["/items"].Get
+	schema := s.buildParametersSchema(op.Parameters)
+	addPaginationAliasProperties(schema, s.apiExecutor.paginationParams("GET", "/items", op))
+
+	props := schema["properties"].(map[string]interface{})
+	for _, alias := range []string{"limit", "offset"} {
+		if _, ok := props[alias]; !ok {
+			t.Errorf("expected alias property %q in schema", alias)
+		}
+	}
+	if _, ok := props["page"]; ok {
+		t.Error("page alias should not be exposed when the operation has no page parameter")
+	}
+}
+
+const autoPaginateTestSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Items API", "version": "1.0.0"},
+  "paths": {
+    "/items": {
+      "get": {
+        "operationId": "listItems",
+        "parameters": [{"name": "cursor", "in": "query", "type": "string"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/logs": {
+      "get": {
+        "operationId": "listLogs",
+        "parameters": [{"name": "page", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+
+// pagedBackend serves three pages of items: /items links pages with a
+// next_cursor field, /logs with a Link header.
+func pagedBackend(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var hits int32
+	pages := map[string]string{"": "1", "c2": "2", "c3": "3"}
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/items":
+			page := pages[r.URL.Query().Get("cursor")]
+			next := map[string]string{"1": `"c2"`, "2": `"c3"`, "3": "null"}[page]
+			fmt.Fprintf(w, `{"data": [{"id": "%s-a"}, {"id": "%s-b"}], "meta": {"next_cursor": %s}}`, page, page, next)
+		case "/logs":
+			page := r.URL.Query().Get("page")
+			if page == "" {
+				page = "1"
+			}
+			if page != "3" {
+				n, _ := strconv.Atoi(page)
+				w.Header().Set("Link", fmt.Sprintf(`<%s/logs?page=%d>; rel="next", <%s/logs?page=3>; rel="last"`, "http://"+r.Host, n+1, "http://"+r.Host))
+			}
+			fmt.Fprintf(w, `[{"line": %s}]`, page)
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend, &hits
+}
+
+func TestAutoPagination_FollowsCursor(t *testing.T) {
+	backend, hits := pagedBackend(t)
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(autoPaginateTestSwagger)).
+		WithAPIConfig(backend.URL, "").
+		WithAutoPagination(map[string]PageFollow{
+			"listitems": {NextPath: "meta.next_cursor", NextParam: "cursor", ItemsPath: "data"},
+		}))
+
+	var items []map[string]string
+	if err := json.Unmarshal([]byte(resultText(t, callTool(t, server, "listitems", map[string]any{"allPages": true}))), &items); err != nil {
+		t.Fatalf("combined result is not a JSON array: %v", err)
+	}
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item["id"])
+	}
+	if strings.Join(ids, ",") != "1-a,1-b,2-a,2-b,3-a,3-b" {
+		t.Errorf("combined items = %v", ids)
+	}
+	if got := atomic.LoadInt32(hits); got != 3 {
+		t.Errorf("expected 3 upstream calls, got %d", got)
+	}
+
+	// Without allPages only the first page is fetched
+	got := resultText(t, callTool(t, server, "listitems", nil))
+	if !strings.Contains(got, "next_cursor") || strings.Contains(got, "2-a") {
+		t.Errorf("expected the first page only, got %s", got)
+	}
+}
+
+func TestAutoPagination_FollowsLinkHeaderUpToMaxPages(t *testing.T) {
+	backend, hits := pagedBackend(t)
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(autoPaginateTestSwagger)).
+		WithAPIConfig(backend.URL, "").
+		WithAutoPagination(map[string]PageFollow{"listlogs": {}, "listitems": {MaxPages: 2, NextPath: "meta.next_cursor", NextParam: "cursor", ItemsPath: "data"}}))
+
+	got := resultText(t, callTool(t, server, "listlogs", map[string]any{"allPages": true}))
+	var lines []map[string]int
+	if err := json.Unmarshal([]byte(got), &lines); err != nil || len(lines) != 3 || lines[2]["line"] != 3 {
+		t.Errorf("expected 3 combined log lines, got %s (%v)", got, err)
+	}
+
+	atomic.StoreInt32(hits, 0)
+	got = resultText(t, callTool(t, server, "listitems", map[string]any{"allPages": true}))
+	if strings.Contains(got, "3-a") || atomic.LoadInt32(hits) != 2 {
+		t.Errorf("expected MaxPages to stop after 2 pages, got %d calls: %s", atomic.LoadInt32(hits), got)
+	}
+}
@@ -0,0 +1,130 @@
+package mcp
+
+import (
+	"fmt"
+	neturl "net/url"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// BooleanQueryFormat selects how boolean query arguments are written
+type BooleanQueryFormat string
+
+const (
+	// BooleanTrueFalse writes true and false (the default)
+	BooleanTrueFalse BooleanQueryFormat = "true-false"
+	// BooleanOneZero writes 1 and 0
+	BooleanOneZero BooleanQueryFormat = "1-0"
+	// BooleanYesNo writes yes and no
+	BooleanYesNo BooleanQueryFormat = "yes-no"
+)
+
+// queryString writes a scalar query value, booleans in format f
+func (f BooleanQueryFormat) queryString(value interface{}) string {
+	b, ok := value.(bool)
+	if !ok {
+		return fmt.Sprintf("%v", value)
+	}
+	switch {
+	case f == BooleanOneZero && b:
+		return "1"
+	case f == BooleanOneZero:
+		return "0"
+	case f == BooleanYesNo && b:
+		return "yes"
+	case f == BooleanYesNo:
+		return "no"
+	}
+	return fmt.Sprintf("%v", b)
+}
+
+// addQueryValue adds a query argument to values, serialized according to
+// its declared parameter (nil when the operation doesn't declare it):
+//   - arrays follow collectionFormat: csv (default), ssv, tsv, pipes or
+//     multi for one key per item
+//   - objects follow the x-style and x-explode extensions carried over from
+//     OpenAPI 3: deepObject gives name[key]=value, exploded form (the
+//     default) gives key=value, and unexploded form gives name=key,value
+//   - booleans, on their own or inside arrays and objects, are written in
+//     the parameter's x-boolean-format, else in booleans
+func addQueryValue(values neturl.Values, name string, value interface{}, param *spec.Parameter, booleans BooleanQueryFormat) {
+	if param != nil {
+		if format, ok := param.Extensions.GetString("x-boolean-format"); ok && format != "" {
+			booleans = BooleanQueryFormat(format)
+		}
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = booleans.queryString(item)
+		}
+		collectionFormat := ""
+		if param != nil {
+			collectionFormat = param.CollectionFormat
+		}
+		switch collectionFormat {
+		case "multi":
+			for _, item := range items {
+				values.Add(name, item)
+			}
+		case "ssv":
+			values.Add(name, strings.Join(items, " "))
+		case "tsv":
+			values.Add(name, strings.Join(items, "\t"))
+		case "pipes":
+			values.Add(name, strings.Join(items, "|"))
+		default:
+			values.Add(name, strings.Join(items, ","))
+		}
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		style, explode := queryObjectStyle(param)
+		switch {
+		case style == "deepObject":
+			for _, key := range keys {
+				values.Add(name+"["+key+"]", booleans.queryString(v[key]))
+			}
+		case explode:
+			for _, key := range keys {
+				values.Add(key, booleans.queryString(v[key]))
+			}
+		default:
+			pairs := make([]string, 0, 2*len(keys))
+			for _, key := range keys {
+				pairs = append(pairs, key, booleans.queryString(v[key]))
+			}
+			values.Add(name, strings.Join(pairs, ","))
+		}
+
+	default:
+		values.Set(name, booleans.queryString(value))
+	}
+}
+
+// queryObjectStyle returns the style and explode settings of an object
+// query parameter, defaulting to exploded form as OpenAPI 3 does
+func queryObjectStyle(param *spec.Parameter) (string, bool) {
+	style, explode := "form", true
+	if param == nil {
+		return style, explode
+	}
+	if s, ok := param.Extensions.GetString("x-style"); ok && s != "" {
+		style = s
+	}
+	if e, ok := param.Extensions.GetBool("x-explode"); ok {
+		explode = e
+	} else if style != "form" {
+		explode = false
+	}
+	return style, explode
+}
@@ -0,0 +1,156 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNoRecording is returned in replay mode for a tool call that matches no
+// recorded call
+var ErrNoRecording = errors.New("no recorded response")
+
+// CallRecord is one line of a recording file: an upstream call made by a
+// tool and the response it got. Credentials are redacted from the URL and
+// the per-call API key argument is left out.
+type CallRecord struct {
+	Tool         string                 `json:"tool"`
+	Method       string                 `json:"method"`
+	URL          string                 `json:"url"`
+	Args         map[string]interface{} `json:"args"`
+	Status       int                    `json:"status"`
+	ResponseBody string                 `json:"responseBody"`
+}
+
+// callRecorder appends CallRecords to a JSON Lines file
+type callRecorder struct {
+	path string
+	mu   sync.Mutex
+}
+
+// record appends a record to the file. Failures are logged rather than
+// failing the call that was recorded.
+func (r *callRecorder) record(record CallRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Printf("Warning: failed to encode call record: %v", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		log.Printf("Warning: failed to open recording file: %v", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("Warning: failed to write call record: %v", err)
+	}
+}
+
+// callReplayer serves recorded responses keyed by tool and normalized
+// arguments
+type callReplayer struct {
+	records map[string]CallRecord
+}
+
+// loadReplayer reads a recording file. When a call was recorded more than
+// once, the last recording wins.
+func loadReplayer(path string) (*callReplayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	replayer := &callReplayer{records: make(map[string]CallRecord)}
+	decoder := json.NewDecoder(f)
+	for n := 1; ; n++ {
+		var record CallRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid record %d in %s: %w", n, path, err)
+		}
+		replayer.records[replayKey(record.Tool, record.Args)] = record
+	}
+	return replayer, nil
+}
+
+// lookup returns the recorded call matching a tool call
+func (r *callReplayer) lookup(tool string, args map[string]interface{}) (CallRecord, bool) {
+	record, ok := r.records[replayKey(tool, args)]
+	return record, ok
+}
+
+// replayKey identifies a call by its tool and arguments. Encoding the
+// arguments as JSON sorts object keys, so argument order does not matter.
+func replayKey(tool string, args map[string]interface{}) string {
+	if len(args) == 0 {
+		return tool + " {}"
+	}
+	data, err := json.Marshal(args)
+	if err != nil {
+		return tool
+	}
+	return tool + " " + string(data)
+}
+
+// recordedArgs copies a call's arguments for recording and matching,
+// dropping the per-call API key
+func recordedArgs(args map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil
+	}
+	delete(copied, PerCallAuthArg)
+	return copied
+}
+
+// sensitiveQueryParams lists query parameters whose values are masked in
+// recorded URLs
+var sensitiveQueryParams = map[string]bool{
+	"api_key":       true,
+	"apikey":        true,
+	"key":           true,
+	"token":         true,
+	"access_token":  true,
+	"client_secret": true,
+	"secret":        true,
+	"password":      true,
+	"signature":     true,
+	"sig":           true,
+}
+
+// redactURL renders a request URL with its user info and credential-like
+// query values masked
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User("[REDACTED]")
+	}
+	query := redacted.Query()
+	masked := false
+	for name := range query {
+		if sensitiveQueryParams[strings.ToLower(name)] {
+			query.Set(name, "[REDACTED]")
+			masked = true
+		}
+	}
+	if masked {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
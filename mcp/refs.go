@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-openapi/spec"
+)
+
+// refLoader reads the documents external $refs point to, enforcing the
+// reference policy of ParseOptions
+type refLoader struct {
+	location     string // absolute file path or http(s) URL of the root spec; "" if unknown
+	remote       bool   // location is an http(s) URL
+	allowedHosts map[string]bool
+}
+
+func newRefLoader(opts ParseOptions) *refLoader {
+	r := &refLoader{allowedHosts: make(map[string]bool)}
+	for _, host := range opts.AllowedRefHosts {
+		r.allowedHosts[strings.ToLower(host)] = true
+	}
+
+	if u, err := url.Parse(opts.Location); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		r.location = opts.Location
+		r.remote = true
+		r.allowedHosts[strings.ToLower(u.Hostname())] = true
+	} else if opts.Location != "" {
+		if abs, err := filepath.Abs(opts.Location); err == nil {
+			r.location = abs
+		}
+	}
+	return r
+}
+
+// expandOptions configures go-openapi $ref expansion to load documents
+// through the loader
+func (r *refLoader) expandOptions() *spec.ExpandOptions {
+	return &spec.ExpandOptions{RelativeBase: r.location, PathLoader: r.loadJSON}
+}
+
+// baseURL returns the root spec location for kin-openapi, or nil if unknown
+func (r *refLoader) baseURL() *url.URL {
+	switch {
+	case r.location == "":
+		return nil
+	case r.remote:
+		u, _ := url.Parse(r.location)
+		return u
+	default:
+		return &url.URL{Path: filepath.ToSlash(r.location)}
+	}
+}
+
+// readURI implements openapi3.ReadFromURIFunc
+func (r *refLoader) readURI(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+	return r.read(location.String())
+}
+
+// loadJSON loads a referenced document as JSON, converting YAML
+func (r *refLoader) loadJSON(ref string) (json.RawMessage, error) {
+	data, err := r.read(ref)
+	if err != nil {
+		return nil, err
+	}
+	return yamlToJSON(data)
+}
+
+// read fetches a referenced document. Remote documents must live on an
+// allowed host; local files may only be referenced from local specs.
+func (r *refLoader) read(ref string) ([]byte, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid $ref location %q: %w", ref, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if !r.allowedHosts[strings.ToLower(u.Hostname())] {
+			return nil, fmt.Errorf("$ref to host %q is not allowed; add it to the allowed reference hosts", u.Hostname())
+		}
+		u.Fragment = ""
+		return FetchSwaggerFromURL(u.String())
+	case "", "file":
+		if r.remote {
+			return nil, fmt.Errorf("$ref to local file %q is not allowed in a remote spec", ref)
+		}
+		data, err := os.ReadFile(filepath.FromSlash(u.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read $ref %q: %w", ref, err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported $ref scheme %q", u.Scheme)
+	}
+}
+
+// unresolvedLocalRef finds the first local $ref ("#/...") in a JSON
+// document that points at nothing, returning it with the location of the
+// object holding it, e.g. paths./pets.post.parameters[0].schema. It
+// returns empty strings when every local $ref resolves.
+func unresolvedLocalRef(data []byte) (ref, location string) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return "", ""
+	}
+
+	var walk func(node interface{}, at string) bool
+	walk = func(node interface{}, at string) bool {
+		switch node := node.(type) {
+		case map[string]interface{}:
+			if target, ok := node["$ref"].(string); ok && strings.HasPrefix(target, "#") {
+				if !resolvesLocally(root, strings.TrimPrefix(target, "#")) {
+					ref, location = target, at
+					return true
+				}
+			}
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				child := key
+				if at != "" {
+					child = at + "." + key
+				}
+				if walk(node[key], child) {
+					return true
+				}
+			}
+		case []interface{}:
+			for i, item := range node {
+				if walk(item, fmt.Sprintf("%s[%d]", at, i)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	walk(root, "")
+	return ref, location
+}
+
+// resolvesLocally reports whether a JSON pointer such as
+// /definitions/Pet names a value in root
+func resolvesLocally(root interface{}, pointer string) bool {
+	if pointer == "" {
+		return true
+	}
+	node := root
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(token); err == nil {
+			token = unescaped
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := node.(type) {
+		case map[string]interface{}:
+			next, ok := current[token]
+			if !ok {
+				return false
+			}
+			node = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(current) {
+				return false
+			}
+			node = current[index]
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const petModelYAML = `Pet:
+  type: object
+  required: [name]
+  properties:
+    name:
+      type: string
+    tag:
+      type: string
+`
+
+// writeSpecFiles writes files into a temp dir and returns its path.
+func writeSpecFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// assertPetSchema checks the createPet body schema was inlined from the model.
+func assertPetSchema(t *testing.T, server *Server) {
+	t.Helper()
+
+	op := server.config.SwaggerSpec.Paths.Paths["/pets"].Post
+	if op == nil || len(op.Parameters) == 0 || op.Parameters[0].Schema == nil {
+		t.Fatalf("expected a body parameter with a schema, got %+v", op)
+	}
+	schema := op.Parameters[0].Schema
+	if schema.Ref.String() != "" {
+		t.Fatalf("expected the $ref to be inlined, still got %q", schema.Ref.String())
+	}
+	if _, ok := schema.Properties["name"]; !ok {
+		t.Errorf("expected the Pet properties, got %+v", schema.Properties)
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "name" {
+		t.Errorf("expected required [name], got %v", schema.Required)
+	}
+}
+
+func TestExternalRefs_SiblingFile(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"models/pet.yaml": petModelYAML,
+		"swagger.yaml": `swagger: "2.0"
+info: {title: Pet API, version: "1.0"}
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      parameters:
+        - name: body
+          in: body
+          schema:
+            $ref: "./models/pet.yaml#/Pet"
+      responses:
+        "201": {description: Created}
+`,
+	})
+
+	server, err := NewFromSwaggerFile(filepath.Join(dir, "swagger.yaml"), "https://api.example.com", "")
+	if err != nil {
+		t.Fatalf("NewFromSwaggerFile failed: %v", err)
+	}
+	assertPetSchema(t, server)
+}
+
+func TestExternalRefs_OpenAPI3SiblingFile(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{
+		"models/pet.yaml": petModelYAML,
+		"openapi.yaml": `openapi: 3.0.3
+info: {title: Pet API, version: "1.0"}
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "./models/pet.yaml#/Pet"
+      responses:
+        "201": {description: Created}
+`,
+	})
+
+	server, err := NewFromSwaggerFile(filepath.Join(dir, "openapi.yaml"), "https://api.example.com", "")
+	if err != nil {
+		t.Fatalf("NewFromSwaggerFile failed: %v", err)
+	}
+	assertPetSchema(t, server)
+}
+
+func TestExternalRefs_RemoteHostAllowList(t *testing.T) {
+	models := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(petModelYAML))
+	}))
+	defer models.Close()
+
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "parameters": [{"name": "body", "in": "body", "schema": {"$ref": "` + models.URL + `/pet.yaml#/Pet"}}],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+
+	_, err := New(DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAPIConfig("https://api.example.com", ""))
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Fatalf("expected a disallowed host error, got %v", err)
+	}
+
+	u, _ := url.Parse(models.URL)
+	server, err := New(DefaultConfig().
+		WithSwaggerData([]byte(spec)).
+		WithAllowedRefHosts(u.Hostname()).
+		WithAPIConfig("https://api.example.com", ""))
+	if err != nil {
+		t.Fatalf("New with allowed ref host failed: %v", err)
+	}
+	assertPetSchema(t, server)
+}
+
+func TestExternalRefs_RemoteSpecCannotReadLocalFiles(t *testing.T) {
+	dir := writeSpecFiles(t, map[string]string{"secret.yaml": petModelYAML})
+
+	refs := newRefLoader(ParseOptions{Location: "https://specs.example.com/swagger.yaml"})
+	if _, err := refs.read(filepath.Join(dir, "secret.yaml")); err == nil {
+		t.Fatal("expected reading a local file from a remote spec to fail")
+	}
+	if _, err := refs.read("file://" + filepath.ToSlash(filepath.Join(dir, "secret.yaml"))); err == nil {
+		t.Fatal("expected reading a file:// ref from a remote spec to fail")
+	}
+}
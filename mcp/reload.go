@@ -0,0 +1,152 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-openapi/spec"
+)
+
+// specReloadDelay coalesces the burst of events editors emit when saving
+const specReloadDelay = 100 * time.Millisecond
+
+// ReloadSpec replaces the served spec with data and re-registers its tools.
+// The new spec is parsed before anything changes, so an invalid spec leaves
+// the current tools in place. Calls already in flight finish against the
+// operation they started with; connected clients are notified that the tool
+// list changed. A base URL inferred from the spec, and the server variables,
+// are taken from the new spec.
+func (s *Server) ReloadSpec(data []byte) error {
+	swagger, err := ParseSwaggerSpecWithOptions(data, s.config.parseOptions())
+	if err != nil {
+		return fmt.Errorf("failed to parse swagger spec: %w", err)
+	}
+	if err := validateSpec(swagger, s.config.Filter); err != nil {
+		return fmt.Errorf("invalid swagger spec: %w", err)
+	}
+	filter, err := limitTools(s.config, swagger)
+	if err != nil {
+		return err
+	}
+
+	// A base URL inferred from the spec follows the spec's servers, as do
+	// the server variables
+	apiBaseURL := s.apiBaseURL()
+	if s.baseURLInferred {
+		if inferred := specBaseURL(swagger, s.config.ServerVariableArgs); inferred != "" {
+			apiBaseURL = inferred
+		}
+	}
+	if apiBaseURL != "" {
+		if err := checkBaseURL(s.config, apiBaseURL, swagger); err != nil {
+			return err
+		}
+	}
+	var variables map[string]ServerVariable
+	if s.config.ServerVariableArgs {
+		_, variables = serverTemplate(swagger)
+	}
+
+	s.mcp.reload(data, swagger, filter, apiBaseURL, variables)
+	if s.config.ToolManifestPath != "" {
+		return s.mcp.writeToolManifest(s.config.ToolManifestPath)
+	}
+	return nil
+}
+
+// reload swaps in the spec parsed from data along with the API endpoint
+// derived from it, registers the tools of swagger that filter selects and
+// removes tools no longer registered. Tools sharing a name are replaced in
+// place.
+func (s *SwaggerMCPServer) reload(data []byte, swagger *spec.Swagger, filter *APIFilter, apiBaseURL string, variables map[string]ServerVariable) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.tools
+	s.swagger = swagger
+	s.filter = filter
+	s.config.SwaggerData = data
+	s.config.SwaggerSpec = swagger
+	s.config.APIBaseURL = apiBaseURL
+	s.apiBaseURL = apiBaseURL
+	s.apiExecutor.setEndpoint(apiBaseURL, variables)
+	s.registerTools()
+
+	var removed []string
+	for name := range previous {
+		if _, ok := s.tools[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		s.server.RemoveTools(removed...)
+	}
+}
+
+// startSpecWatch reloads the spec from Config.SpecWatchPath on every change
+// until ctx is done. It is a no-op when no watch path is configured.
+func (s *Server) startSpecWatch(ctx context.Context) error {
+	path := s.config.SpecWatchPath
+	if path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create spec watcher: %w", err)
+	}
+	// Watch the directory: editors often replace the file rather than
+	// write to it, which drops a watch on the file itself
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	go func() {
+		defer func() { _ = watcher.Close() }()
+
+		target := filepath.Clean(path)
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == target && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					pending = time.After(specReloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Spec watcher error: %v", err)
+			case <-pending:
+				pending = nil
+				s.reloadSpecFile(path)
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadSpecFile reloads the spec from path, logging failures
+func (s *Server) reloadSpecFile(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Failed to read spec %s: %v", path, err)
+		return
+	}
+	if err := s.ReloadSpec(data); err != nil {
+		log.Printf("Failed to reload spec %s: %v", path, err)
+		return
+	}
+	log.Printf("Reloaded spec from %s", path)
+}
@@ -0,0 +1,224 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const reloadedSwagger = `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.1.0"},
+  "paths": {
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [{"name": "limit", "in": "query", "type": "integer"}],
+        "responses": {"200": {"description": "OK"}}
+      }
+    },
+    "/owners": {
+      "get": {"operationId": "listOwners", "responses": {"200": {"description": "OK"}}}
+    }
+  }
+}`
+
+// toolNames lists the tools a client session sees.
+func toolNames(t *testing.T, session *sdk.ClientSession) map[string]bool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	names := map[string]bool{}
+	for _, tool := range result.Tools {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+func TestReloadSpec_AddsAndRemovesTools(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/pets" {
+			started <- struct{}{}
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	session := connectTestClient(t, server)
+
+	before := toolNames(t, session)
+	if !before["listpets"] || !before["createpet"] || before["listowners"] {
+		t.Fatalf("unexpected tools before reload: %v", before)
+	}
+
+	// Start a call that is still in flight during the reload
+	done := make(chan *sdk.CallToolResult)
+	go func() {
+		result, err := session.CallTool(context.Background(), &sdk.CallToolParams{Name: "listpets"})
+		if err != nil {
+			t.Errorf("in-flight call failed: %v", err)
+		}
+		done <- result
+	}()
+	<-started
+
+	if err := server.ReloadSpec([]byte(reloadedSwagger)); err != nil {
+		t.Fatalf("ReloadSpec failed: %v", err)
+	}
+	close(release)
+
+	if got := resultText(t, <-done); !strings.Contains(got, `"path":"/pets"`) {
+		t.Errorf("in-flight call returned %q", got)
+	}
+
+	after := toolNames(t, session)
+	if !after["listpets"] || !after["listowners"] || after["createpet"] {
+		t.Errorf("unexpected tools after reload: %v", after)
+	}
+	if got := resultText(t, callTool(t, server, "listowners", nil)); !strings.Contains(got, `"path":"/owners"`) {
+		t.Errorf("new tool returned %q", got)
+	}
+}
+
+func TestReloadSpec_InvalidSpecKeepsTools(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().WithAPIConfig("http://localhost:1", ""))
+
+	if err := server.ReloadSpec([]byte("{not json")); err == nil {
+		t.Fatal("expected an error for an invalid spec")
+	}
+	if names := toolNames(t, connectTestClient(t, server)); !names["createpet"] {
+		t.Errorf("tools changed after failed reload: %v", names)
+	}
+}
+
+func TestSpecWatch_ReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "swagger.json")
+	if err := os.WriteFile(path, []byte(executorTestSwagger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig("http://localhost:1", "").
+		WithSpecWatch(path))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := server.startSpecWatch(ctx); err != nil {
+		t.Fatalf("startSpecWatch failed: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(reloadedSwagger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	session := connectTestClient(t, server)
+	deadline := time.Now().Add(5 * time.Second)
+	for !toolNames(t, session)["listowners"] {
+		if time.Now().After(deadline) {
+			t.Fatal("spec change was not picked up")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestReloadSpec_ConcurrentReadersAndInferredBaseURL(t *testing.T) {
+	backend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"backend": "` + name + `"}`))
+		}))
+	}
+	first, second := backend("first"), backend("second")
+	defer first.Close()
+	defer second.Close()
+	specFor := func(backend *httptest.Server) []byte {
+		return []byte(`{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "host": "` + strings.TrimPrefix(backend.URL, "http://") + `",
+  "schemes": ["http"],
+  "paths": {"/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}}}
+}`)
+	}
+
+	// No base URL configured: it is inferred from the spec's host
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData(specFor(first)).
+		WithAllowInsecureHTTP(true))
+	endpoint, cancel := startHTTPServer(t, server)
+	defer cancel()
+
+	// Read the spec, the config and call a tool while reloading (run with
+	// -race to check the swap)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, path := range []string{"/openapi.json", "/config"} {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				resp, err := http.Get(endpoint + path)
+				if err != nil {
+					t.Errorf("GET %s failed: %v", path, err)
+					return
+				}
+				_, _ = io.Copy(io.Discard, resp.Body)
+				_ = resp.Body.Close()
+			}
+		}(path)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_, _, _ = server.CallTool(context.Background(), "listpets", nil)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		next := first
+		if i%2 == 1 {
+			next = second
+		}
+		if err := server.ReloadSpec(specFor(next)); err != nil {
+			t.Fatalf("ReloadSpec failed: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	// The last spec names the second backend
+	if got := resultText(t, callTool(t, server, "listpets", nil)); !strings.Contains(got, "second") {
+		t.Errorf("call after reload went to %q, want the second backend", got)
+	}
+	if got := server.ResolvedConfig().APIBaseURL; got != second.URL {
+		t.Errorf("resolved base URL = %q, want %q", got, second.URL)
+	}
+}
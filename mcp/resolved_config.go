@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"net/url"
+	"sort"
+)
+
+// ResolvedConfig is the effective configuration of a running server,
+// including values inferred at startup. It never contains secrets.
+type ResolvedConfig struct {
+	Name            string         `json:"name"`
+	Version         string         `json:"version"`
+	APIBaseURL      string         `json:"apiBaseUrl"`
+	BaseURLInferred bool           `json:"baseUrlInferred"` // Derived from the spec's host/basePath
+	ToolCount       int            `json:"toolCount"`
+	Transport       TransportInfo  `json:"transport"`
+	Auth            []string       `json:"auth"` // Active credential types, e.g. "api_key", "basic"
+	Filter          *FilterSummary `json:"filter,omitempty"`
+	ProxyURL        string         `json:"proxyUrl,omitempty"` // Credentials redacted
+	DryRun          bool           `json:"dryRun"`
+}
+
+// TransportInfo describes how the server is reached
+type TransportInfo struct {
+	Type string `json:"type"` // "stdio" or "http"
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+	Path string `json:"path,omitempty"`
+}
+
+// FilterSummary lists the active API filter rules
+type FilterSummary struct {
+	ExcludePaths            []string `json:"excludePaths,omitempty"`
+	ExcludePathPatterns     []string `json:"excludePathPatterns,omitempty"`
+	ExcludePathRegexps      []string `json:"excludePathRegexps,omitempty"`
+	ExcludeOperationIDs     []string `json:"excludeOperationIds,omitempty"`
+	ExcludeMethods          []string `json:"excludeMethods,omitempty"`
+	ExcludeTags             []string `json:"excludeTags,omitempty"`
+	ExcludeDeprecated       bool     `json:"excludeDeprecated,omitempty"`
+	IncludeOnlyPaths        []string `json:"includeOnlyPaths,omitempty"`
+	IncludeOnlyOperationIDs []string `json:"includeOnlyOperationIds,omitempty"`
+}
+
+// ResolvedConfig returns the effective configuration, for debugging
+// deployments. Secrets are reported only by type.
+func (s *Server) ResolvedConfig() ResolvedConfig {
+	config := s.config
+	resolved := ResolvedConfig{
+		Name:            config.Name,
+		Version:         config.Version,
+		APIBaseURL:      s.apiBaseURL(),
+		BaseURLInferred: s.baseURLInferred,
+		ToolCount:       len(s.ListToolsDetailed()),
+		Transport:       TransportInfo{Type: "stdio"},
+		Auth:            s.authTypes(),
+		Filter:          summarizeFilter(config.Filter),
+		DryRun:          config.DryRun,
+	}
+
+	if t, ok := config.Transport.(*HTTPTransport); ok {
+		resolved.Transport = TransportInfo{Type: "http", Host: t.Host, Port: t.Port, Path: t.Path}
+	}
+	if config.ProxyURL != "" {
+		if proxy, err := url.Parse(config.ProxyURL); err == nil {
+			resolved.ProxyURL = proxy.Redacted()
+		}
+	}
+	return resolved
+}
+
+// authTypes names the kinds of credentials sent upstream
+func (s *Server) authTypes() []string {
+	config := s.config
+	auth := []string{}
+	if config.APIKey != "" {
+		auth = append(auth, "api_key")
+	}
+	if config.BasicAuthUsername != "" {
+		auth = append(auth, "basic")
+	}
+	if config.OAuth2 != nil {
+		auth = append(auth, "oauth2")
+	}
+	if len(config.Cookies) > 0 || config.CookieParams {
+		auth = append(auth, "cookies")
+	}
+	if config.SecretProvider != nil {
+		auth = append(auth, "secret_provider")
+	}
+	sort.Strings(auth)
+	return auth
+}
+
+// summarizeFilter copies the rules of filter, or returns nil without one
+func summarizeFilter(filter *APIFilter) *FilterSummary {
+	if filter == nil {
+		return nil
+	}
+	return &FilterSummary{
+		ExcludePaths:            filter.ExcludePaths,
+		ExcludePathPatterns:     filter.ExcludePathPatterns,
+		ExcludePathRegexps:      filter.ExcludePathRegexps,
+		ExcludeOperationIDs:     filter.ExcludeOperationIDs,
+		ExcludeMethods:          filter.ExcludeMethods,
+		ExcludeTags:             filter.ExcludeTags,
+		ExcludeDeprecated:       filter.ExcludeDeprecated,
+		IncludeOnlyPaths:        filter.IncludeOnlyPaths,
+		IncludeOnlyOperationIDs: filter.IncludeOnlyOperationIDs,
+	}
+}
@@ -0,0 +1,293 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ResourceActionArg is the argument of a grouped resource tool that selects
+// which operation to call
+const ResourceActionArg = "action"
+
+// resourceAction is one operation of a grouped resource tool
+type resourceAction struct {
+	toolName string // The operation's own tool name
+	method   string
+	path     string
+	op       *spec.Operation
+	schema   map[string]interface{} // The operation's own input schema
+}
+
+// resourceOperation is an operation awaiting grouping
+type resourceOperation struct {
+	method string
+	path   string
+	op     *spec.Operation
+}
+
+// resourceBase returns the resource a path operates on by dropping trailing
+// path parameters, so /pets and /pets/{petId} share the base /pets while
+// /users/{id}/orders is a resource of its own
+func resourceBase(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for len(segments) > 0 && strings.HasPrefix(segments[len(segments)-1], "{") {
+		segments = segments[:len(segments)-1]
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+// resourceToolName names the grouped tool for a resource base, e.g.
+// manage_pets for /pets and manage_users_orders for /users/{id}/orders
+func resourceToolName(base string) string {
+	var words []string
+	for _, segment := range strings.Split(strings.Trim(base, "/"), "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") {
+			continue
+		}
+		words = append(words, strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+				return r
+			}
+			return '_'
+		}, strings.ToLower(segment)))
+	}
+	if len(words) == 0 {
+		words = []string{"root"}
+	}
+	return "manage_" + strings.Join(words, "_")
+}
+
+// crudAction names an operation by what it does to its resource
+func crudAction(method, path, base string) string {
+	switch method {
+	case "GET":
+		if path == base {
+			return "list"
+		}
+		return "get"
+	case "POST":
+		return "create"
+	case "PUT", "PATCH":
+		return "update"
+	case "DELETE":
+		return "delete"
+	}
+	return strings.ToLower(method)
+}
+
+// registerResourceTools registers a grouped tool for every resource with
+// more than one operation, and a plain tool for the rest. The caller must
+// hold s.mu.
+func (s *SwaggerMCPServer) registerResourceTools() {
+	groups := make(map[string][]resourceOperation)
+	for path, pathItem := range s.swagger.Paths.Paths {
+		for _, operation := range []resourceOperation{
+			{"GET", path, pathItem.Get},
+			{"POST", path, pathItem.Post},
+			{"PUT", path, pathItem.Put},
+			{"DELETE", path, pathItem.Delete},
+			{"PATCH", path, pathItem.Patch},
+			{"HEAD", path, pathItem.Head},
+			{"OPTIONS", path, pathItem.Options},
+		} {
+			if !exposesOperation(s.filter, operation.method, path, operation.op) {
+				continue
+			}
+			base := resourceBase(path)
+			groups[base] = append(groups[base], operation)
+		}
+	}
+
+	for base, operations := range groups {
+		if len(operations) == 1 {
+			s.registerOperation(operations[0].method, operations[0].path, operations[0].op)
+			continue
+		}
+		s.registerResourceTool(base, operations)
+	}
+}
+
+// registerResourceTool registers the grouped tool for a resource's
+// operations
+func (s *SwaggerMCPServer) registerResourceTool(base string, operations []resourceOperation) {
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].path != operations[j].path {
+			return operations[i].path < operations[j].path
+		}
+		return operations[i].method < operations[j].method
+	})
+
+	// Name actions by CRUD verb, falling back to the operations' own tool
+	// names where two would share a verb
+	verbs := make(map[string]int)
+	for _, operation := range operations {
+		verbs[crudAction(operation.method, operation.path, base)]++
+	}
+	actions := make(map[string]resourceAction)
+	var names []string
+	for _, operation := range operations {
+		toolName := GenerateToolName(operation.method, operation.path, operation.op)
+		name := crudAction(operation.method, operation.path, base)
+		if verbs[name] > 1 {
+			name = toolName
+		}
+		actions[name] = resourceAction{
+			toolName: toolName,
+			method:   operation.method,
+			path:     operation.path,
+			op:       operation.op,
+			schema:   s.operationInputSchema(toolName, operation.method, operation.path, operation.op),
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	toolName := resourceToolName(base)
+	tool := &mcp.Tool{
+		Name:        toolName,
+		Description: resourceDescription(base, names, actions),
+		InputSchema: resourceInputSchema(names, actions),
+		Annotations: resourceAnnotations(actions),
+	}
+	mcp.AddTool(s.server, tool, s.createResourceHandler(toolName))
+	s.tools[toolName] = registeredTool{tool: tool, path: base, actions: actions}
+}
+
+// resourceDescription lists a grouped tool's actions and what they call
+func resourceDescription(base string, names []string, actions map[string]resourceAction) string {
+	lines := []string{fmt.Sprintf("Manage %s. Set %s to one of:", base, ResourceActionArg)}
+	for _, name := range names {
+		action := actions[name]
+		line := fmt.Sprintf("- %s: %s %s", name, action.method, action.path)
+		if summary := GenerateToolDescription(action.method, action.path, action.op); summary != fmt.Sprintf("%s %s", action.method, action.path) {
+			line += " - " + summary
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resourceInputSchema merges the actions' arguments into one schema with a
+// required action selector. Arguments are only required by the action that
+// needs them, which the handler checks once the action is known. Defaults
+// are left out so one action's defaults are never sent with another.
+func resourceInputSchema(names []string, actions map[string]resourceAction) map[string]interface{} {
+	enum := make([]interface{}, len(names))
+	for i, name := range names {
+		enum[i] = name
+	}
+	properties := map[string]interface{}{
+		ResourceActionArg: map[string]interface{}{
+			"type":        "string",
+			"enum":        enum,
+			"description": "Operation to perform",
+		},
+	}
+	for _, name := range names {
+		actionProperties, _ := actions[name].schema["properties"].(map[string]interface{})
+		for argName, property := range actionProperties {
+			property, _ := property.(map[string]interface{})
+			merged := make(map[string]interface{}, len(property))
+			for key, value := range property {
+				if key != "default" {
+					merged[key] = value
+				}
+			}
+			existing, seen := properties[argName]
+			if seen && !reflect.DeepEqual(existing, merged) {
+				// Actions disagree on this argument; leave it unconstrained
+				// here and check it per action
+				merged = map[string]interface{}{"description": "Depends on the action"}
+			}
+			properties[argName] = merged
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{ResourceActionArg},
+	}
+}
+
+// resourceAnnotations combines the actions' behaviour hints: read-only if
+// every action is, destructive if any action is
+func resourceAnnotations(actions map[string]resourceAction) *mcp.ToolAnnotations {
+	readOnly, destructive := true, false
+	for _, action := range actions {
+		annotations := toolAnnotations(action.method)
+		if !annotations.ReadOnlyHint {
+			readOnly = false
+		}
+		if annotations.DestructiveHint != nil && *annotations.DestructiveHint {
+			destructive = true
+		}
+	}
+	if readOnly {
+		return &mcp.ToolAnnotations{ReadOnlyHint: true}
+	}
+	return &mcp.ToolAnnotations{DestructiveHint: &destructive}
+}
+
+// createResourceHandler returns the handler of a grouped resource tool
+func (s *SwaggerMCPServer) createResourceHandler(toolName string) mcp.ToolHandlerFor[map[string]interface{}, APIResponse] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, args map[string]interface{}) (*mcp.CallToolResult, APIResponse, error) {
+		if noCache, _ := req.Params.Meta["noCache"].(bool); noCache {
+			ctx = SkipCache(ctx)
+		}
+
+		method, result, err := s.callResourceTool(ctx, toolName, args)
+		if err != nil {
+			return nil, APIResponse{}, err
+		}
+		return s.buildToolResult(method, result)
+	}
+}
+
+// isResourceTool reports whether toolName is a grouped resource tool
+func (s *SwaggerMCPServer) isResourceTool(toolName string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tools[toolName].actions != nil
+}
+
+// callResourceTool dispatches a grouped tool call to the operation its
+// action selects, returning the operation's method with the result.
+// Arguments the selected operation does not take are dropped, or rejected
+// with StrictParams.
+func (s *SwaggerMCPServer) callResourceTool(ctx context.Context, toolName string, args map[string]interface{}) (string, *APIResult, error) {
+	s.mu.RLock()
+	actions := s.tools[toolName].actions
+	s.mu.RUnlock()
+
+	name, _ := args[ResourceActionArg].(string)
+	action, ok := actions[name]
+	if !ok {
+		names := make([]string, 0, len(actions))
+		for name := range actions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", nil, &ArgumentError{Problems: []string{fmt.Sprintf("%s must be one of: %s", ResourceActionArg, strings.Join(names, ", "))}}
+	}
+
+	checks := s.argumentChecks()
+	properties, _ := action.schema["properties"].(map[string]interface{})
+	actionArgs := make(map[string]interface{}, len(args))
+	for argName, value := range args {
+		if _, ok := properties[argName]; ok || (checks.strict && argName != ResourceActionArg) {
+			actionArgs[argName] = value
+		}
+	}
+	if err := validateArguments(action.schema, actionArgs, checks); err != nil {
+		return "", nil, err
+	}
+
+	result, err := s.executeTool(ctx, action.toolName, action.method, action.path, action.op, actionArgs)
+	return action.method, result, err
+}
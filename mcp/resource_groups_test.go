@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResourceGrouping_DispatchesByAction(t *testing.T) {
+	var requests []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+string(body)))
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithResourceGrouping(true))
+
+	if names := server.mcp.toolManifest().Tools; len(names) != 1 || names[0].Name != "manage_pets" {
+		t.Fatalf("registered tools = %+v, want only manage_pets", names)
+	}
+	schema := server.mcp.toolInputSchema("manage_pets")
+	action, _ := schema["properties"].(map[string]interface{})[ResourceActionArg].(map[string]interface{})
+	if enum, _ := action["enum"].([]interface{}); len(enum) != 4 || enum[0] != "create" || enum[1] != "delete" || enum[2] != "list" || enum[3] != "update" {
+		t.Errorf("action enum = %v, want [create delete list update]", action["enum"])
+	}
+
+	calls := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"action": "list", "limit": 2}, "GET /pets?limit=2"},
+		{map[string]any{"action": "create", "body": map[string]any{"name": "Rex"}}, `POST /pets {"name":"Rex"}`},
+		{map[string]any{"action": "update", "petId": "7", "body": map[string]any{"name": "Max"}}, `PUT /pets/7 {"name":"Max"}`},
+		{map[string]any{"action": "delete", "petId": "7", "limit": 2}, "DELETE /pets/7"},
+	}
+	for _, call := range calls {
+		requests = nil
+		if result := callTool(t, server, "manage_pets", call.args); result.IsError {
+			t.Errorf("%v: unexpected error %s", call.args, resultText(t, result))
+			continue
+		}
+		if len(requests) != 1 || requests[0] != call.want {
+			t.Errorf("%v: sent %v, want %q", call.args, requests, call.want)
+		}
+	}
+
+	// Each action still requires its own arguments
+	requests = nil
+	result := callTool(t, server, "manage_pets", map[string]any{"action": "delete"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "petId is required") || len(requests) != 0 {
+		t.Errorf("delete without petId: %v, sent %v", result.Content, requests)
+	}
+	result = callTool(t, server, "manage_pets", map[string]any{"action": "rename"})
+	if !result.IsError || !strings.Contains(resultText(t, result), "action must be one of") {
+		t.Errorf("unknown action: %v", result.Content)
+	}
+
+	// CallTool dispatches the same way
+	requests = nil
+	if _, status, err := server.CallTool(context.Background(), "manage_pets", map[string]interface{}{"action": "delete", "petId": "9"}); err != nil || status != http.StatusOK {
+		t.Fatalf("CallTool = %d, %v", status, err)
+	}
+	if len(requests) != 1 || requests[0] != "DELETE /pets/9" {
+		t.Errorf("CallTool sent %v", requests)
+	}
+}
@@ -0,0 +1,106 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ResponseEnvelope is the tool content when response headers are included:
+// the selected headers alongside the formatted body, which is embedded as
+// JSON when it is JSON and as a string otherwise
+type ResponseEnvelope struct {
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body"`
+}
+
+// ResultEnvelope is the tool content of a successful call when results are
+// enveloped: the same shape whether the body is an array, an object, text
+// or empty. Data holds the formatted body, embedded as JSON when it is
+// JSON, as a string otherwise and null when the response has no body.
+type ResultEnvelope struct {
+	Status      int               `json:"status"`
+	ContentType string            `json:"contentType,omitempty"`
+	Data        interface{}       `json:"data"`
+	Headers     map[string]string `json:"headers,omitempty"` // The headers selected by IncludeResponseHeaders
+}
+
+// selectResponseHeaders picks the named headers from header, or all of them
+// for "*". Repeated values are joined with ", ".
+func selectResponseHeaders(header http.Header, names []string) map[string]string {
+	selected := make(map[string]string)
+	for _, name := range names {
+		if name == "*" {
+			for key, values := range header {
+				selected[key] = strings.Join(values, ", ")
+			}
+			continue
+		}
+		if values := header.Values(name); len(values) > 0 {
+			selected[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+		}
+	}
+	return selected
+}
+
+// headResponseContent describes a HEAD response by its status and all of
+// its headers
+func headResponseContent(statusCode int, header http.Header) string {
+	content, _ := json.Marshal(struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers"`
+	}{statusCode, selectResponseHeaders(header, []string{"*"})})
+	return string(content)
+}
+
+// envelopeResponse wraps formatted content and the selected headers in a
+// ResponseEnvelope, indented when pretty is set
+func envelopeResponse(content string, header http.Header, names []string, pretty bool) string {
+	envelope := ResponseEnvelope{
+		Headers: selectResponseHeaders(header, names),
+		Body:    embedContent(content),
+	}
+	return marshalEnvelope(envelope, content, pretty)
+}
+
+// envelopeResult wraps the formatted content of a successful response in a
+// ResultEnvelope, with the headers named by names if any. content is ""
+// when the response has no body.
+func envelopeResult(statusCode int, content string, header http.Header, names []string, pretty bool) string {
+	envelope := ResultEnvelope{
+		Status:      statusCode,
+		ContentType: header.Get("Content-Type"),
+	}
+	if content != "" {
+		envelope.Data = embedContent(content)
+	}
+	if len(names) > 0 {
+		envelope.Headers = selectResponseHeaders(header, names)
+	}
+	return marshalEnvelope(envelope, content, pretty)
+}
+
+// embedContent embeds formatted content in an envelope as JSON when it is
+// JSON and as a string otherwise
+func embedContent(content string) interface{} {
+	if json.Valid([]byte(content)) {
+		return json.RawMessage(content)
+	}
+	return content
+}
+
+// marshalEnvelope serializes an envelope, indented when pretty is set,
+// falling back to the bare content if it cannot
+func marshalEnvelope(envelope interface{}, content string, pretty bool) string {
+	var data []byte
+	var err error
+	if pretty {
+		data, err = json.MarshalIndent(envelope, "", "  ")
+	} else {
+		data, err = json.Marshal(envelope)
+	}
+	if err != nil {
+		return content
+	}
+	return string(data)
+}
@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+
+	"github.com/go-openapi/spec"
+)
+
+// successResponse picks the documented response whose schema describes a
+// tool's output: the preferred status code if it documents a schema, else
+// the lowest 2xx with a schema, else the default response. It returns
+// code 0 for the default response and a nil schema when none applies.
+func successResponse(op *spec.Operation, preferred int) (int, *spec.Schema) {
+	if op == nil || op.Responses == nil {
+		return 0, nil
+	}
+
+	responses := op.Responses.StatusCodeResponses
+	if response, ok := responses[preferred]; ok && preferred > 0 && response.Schema != nil {
+		return preferred, response.Schema
+	}
+
+	codes := make([]int, 0, len(responses))
+	for code := range responses {
+		if code >= 200 && code < 300 && responses[code].Schema != nil {
+			codes = append(codes, code)
+		}
+	}
+	if len(codes) > 0 {
+		sort.Ints(codes)
+		response := responses[codes[0]]
+		return codes[0], response.Schema
+	}
+
+	if def := op.Responses.Default; def != nil && def.Schema != nil {
+		return 0, def.Schema
+	}
+	return 0, nil
+}
+
+// validateResponse checks a 2xx JSON body against the schema op documents
+// for status, returning the mismatches found as path-qualified problems.
+// Bodies that are empty, not JSON or undocumented are not checked.
+func validateResponse(op *spec.Operation, status int, body []byte) []string {
+	if op == nil || op.Responses == nil || status < 200 || status >= 300 {
+		return nil
+	}
+	response, ok := op.Responses.StatusCodeResponses[status]
+	if !ok || response.Schema == nil || len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil
+	}
+	schema := schemaToMap(response.Schema)
+	if schema == nil {
+		return nil
+	}
+	applySchemaExtensions(schema)
+
+	var problems []string
+	validateValue(schema, value, "response", argumentChecks{}, &problems)
+	return problems
+}
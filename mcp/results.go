@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// errorHeaders are the response headers surfaced in structured API errors
+// because they tell the caller how to recover
+var errorHeaders = []string{"Retry-After", "WWW-Authenticate", "X-RateLimit-Reset", "X-Request-Id", "Location"}
+
+// APIError is the structured tool result returned for 4xx/5xx responses
+type APIError struct {
+	Status     int               `json:"status"`
+	StatusText string            `json:"statusText"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       interface{}       `json:"body,omitempty"`
+}
+
+// formatAPIError renders a failed API result as an APIError JSON object.
+// JSON error bodies are embedded as structured values.
+func formatAPIError(result *APIResult) string {
+	apiErr := APIError{
+		Status:     result.StatusCode,
+		StatusText: http.StatusText(result.StatusCode),
+	}
+
+	for _, name := range errorHeaders {
+		if value := result.Header.Get(name); value != "" {
+			if apiErr.Headers == nil {
+				apiErr.Headers = map[string]string{}
+			}
+			apiErr.Headers[name] = value
+		}
+	}
+
+	if len(result.Body) > 0 {
+		var body interface{}
+		if err := unmarshalJSONNumbers(result.Body, &body); err == nil {
+			apiErr.Body = body
+		} else {
+			apiErr.Body = string(result.Body)
+		}
+	} else if result.Content != "" {
+		apiErr.Body = result.Content
+	}
+
+	data, _ := json.MarshalIndent(apiErr, "", "  ")
+	return string(data)
+}
+
+// summaryFields are the identifying fields copied from a write response
+// into its summary
+var summaryFields = []string{"id", "uuid", "name", "status", "state"}
+
+// isWriteMethod reports whether method creates, changes or deletes resources
+func isWriteMethod(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// summarizeWriteResponse condenses a write operation's response to the
+// HTTP status plus the identifying fields of the returned resource, e.g.
+// {"status":201,"statusText":"Created","id":42,"name":"Rex"}
+func summarizeWriteResponse(statusCode int, body []byte) string {
+	summary := map[string]interface{}{
+		"status":     statusCode,
+		"statusText": http.StatusText(statusCode),
+	}
+
+	var resource map[string]interface{}
+	if err := unmarshalJSONNumbers(body, &resource); err == nil {
+		for _, field := range summaryFields {
+			value, ok := resource[field]
+			if !ok {
+				continue
+			}
+			if field == "status" {
+				// Keep the HTTP status; report the resource's own status separately
+				summary["resourceStatus"] = value
+				continue
+			}
+			summary[field] = value
+		}
+	}
+
+	data, _ := json.Marshal(summary)
+	return string(data)
+}
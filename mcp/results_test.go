@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteSummaries_CreateResponse(t *testing.T) {
+	const created = `{"id": 42, "name": "Rex", "status": "available", "tags": ["a", "b"], "owner": {"id": 7}}`
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(created))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithWriteSummaries(true))
+
+	result := callTool(t, server, "createpet", map[string]any{"body": map[string]any{"name": "Rex"}})
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", resultText(t, result))
+	}
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal([]byte(resultText(t, result)), &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v", err)
+	}
+	want := map[string]interface{}{
+		"status":         float64(201),
+		"statusText":     "Created",
+		"id":             float64(42),
+		"name":           "Rex",
+		"resourceStatus": "available",
+	}
+	if len(summary) != len(want) {
+		t.Errorf("summary = %v, want %v", summary, want)
+	}
+	for key, value := range want {
+		if summary[key] != value {
+			t.Errorf("summary[%s] = %v, want %v", key, summary[key], value)
+		}
+	}
+
+	full, _ := result.Meta["fullResponse"].(string)
+	if !strings.Contains(full, `"tags"`) || !strings.Contains(full, `"owner"`) {
+		t.Errorf("full response should be kept in metadata, got %q", full)
+	}
+}
+
+func TestWriteSummaries_ReadsUnaffected(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "Rex"}]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithWriteSummaries(true))
+
+	text := resultText(t, callTool(t, server, "listpets", nil))
+	if !strings.Contains(text, `"name":"Rex"`) {
+		t.Errorf("GET results should not be summarized, got %s", text)
+	}
+}
+
+func TestAPIError_StructuredValidationError(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Internal", "hidden")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"errors": [{"field": "name", "message": "is required"}]}`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+
+	result := callTool(t, server, "createpet", map[string]any{"body": map[string]any{}})
+	if !result.IsError {
+		t.Fatal("expected IsError for a 422 response")
+	}
+
+	var apiErr APIError
+	if err := json.Unmarshal([]byte(resultText(t, result)), &apiErr); err != nil {
+		t.Fatalf("error result is not structured JSON: %v\n%s", err, resultText(t, result))
+	}
+	if apiErr.Status != 422 || apiErr.StatusText != "Unprocessable Entity" {
+		t.Errorf("status = %d %q, want 422 Unprocessable Entity", apiErr.Status, apiErr.StatusText)
+	}
+	if apiErr.Headers["Retry-After"] != "30" {
+		t.Errorf("Retry-After header missing: %v", apiErr.Headers)
+	}
+	if _, ok := apiErr.Headers["X-Internal"]; ok {
+		t.Error("unrelated headers should not be surfaced")
+	}
+
+	body, ok := apiErr.Body.(map[string]interface{})
+	if !ok {
+		t.Fatalf("JSON error body should be structured, got %#v", apiErr.Body)
+	}
+	errs, _ := body["errors"].([]interface{})
+	if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "name" {
+		t.Errorf("unexpected error body: %#v", body)
+	}
+}
+
+func TestAPIError_TextBody(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream exploded", http.StatusBadGateway)
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	result := callTool(t, server, "listpets", nil)
+
+	var apiErr APIError
+	if err := json.Unmarshal([]byte(resultText(t, result)), &apiErr); err != nil {
+		t.Fatalf("error result is not structured JSON: %v", err)
+	}
+	if apiErr.Status != 502 || apiErr.Body != "upstream exploded\n" {
+		t.Errorf("unexpected error: %+v", apiErr)
+	}
+}
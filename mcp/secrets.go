@@ -0,0 +1,188 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Secret names requested from a SecretProvider
+const (
+	SecretAPIKey            = "api_key"
+	SecretBasicAuthPassword = "basic_auth_password"
+	SecretOAuthClientSecret = "oauth_client_secret"
+)
+
+// ErrSecretNotFound is returned by providers that do not hold a secret
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider supplies credentials by name. It is consulted on every
+// upstream call, so rotated secrets take effect without a restart.
+// Providers return ErrSecretNotFound for secrets they do not manage.
+type SecretProvider interface {
+	Get(name string) (string, error)
+}
+
+// StaticSecrets serves secrets from a fixed map. Empty values count as not
+// found. The API key and password set directly on Config are served this way.
+type StaticSecrets map[string]string
+
+// Get implements SecretProvider
+func (s StaticSecrets) Get(name string) (string, error) {
+	if value := s[name]; value != "" {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// EnvSecrets reads secrets from environment variables named Prefix plus the
+// upper-cased secret name, e.g. MCP_API_KEY for "api_key" with the default
+// "MCP_" prefix.
+type EnvSecrets struct {
+	Prefix string
+}
+
+// Get implements SecretProvider
+func (s EnvSecrets) Get(name string) (string, error) {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "MCP_"
+	}
+	if value := os.Getenv(prefix + strings.ToUpper(name)); value != "" {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+// FileSecrets reads each secret from a file named after it in Dir (as with
+// Docker or Kubernetes secret mounts). Surrounding whitespace is trimmed.
+type FileSecrets struct {
+	Dir string
+}
+
+// Get implements SecretProvider
+func (s FileSecrets) Get(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// ChainSecrets asks each provider in turn and returns the first secret found
+type ChainSecrets []SecretProvider
+
+// Get implements SecretProvider
+func (c ChainSecrets) Get(name string) (string, error) {
+	for _, provider := range c {
+		if provider == nil {
+			continue
+		}
+		value, err := provider.Get(name)
+		if err == nil {
+			return value, nil
+		}
+		if !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+	}
+	return "", ErrSecretNotFound
+}
+
+// lookupSecret fetches an optional secret, treating "not found" as empty
+func lookupSecret(provider SecretProvider, name string) (string, error) {
+	if provider == nil {
+		return "", nil
+	}
+	value, err := provider.Get(name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// Credential is the authentication for the requests of one operation, as
+// returned by a credential function. Set one of APIKey, Bearer or
+// Username/Password.
+type Credential struct {
+	APIKey   string // Sent like Config.APIKey, in the headers AuthHeaderMode selects
+	Bearer   string // Sent as Authorization: Bearer
+	Username string // Basic authentication
+	Password string
+}
+
+// apply sets the credential's headers on req, sending an API key in the
+// headers mode selects
+func (c Credential) apply(req *http.Request, mode AuthHeaderMode) {
+	if c.APIKey != "" {
+		mode.setAPIKey(req, c.APIKey)
+	}
+	if c.Bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Bearer)
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// AuthHeaderMode selects the headers an API key is sent in. Any value other
+// than the constants below names a custom header that carries the key.
+type AuthHeaderMode string
+
+const (
+	// AuthHeaderBoth sends X-API-Key and Authorization: Bearer (the default)
+	AuthHeaderBoth AuthHeaderMode = "both"
+	// AuthHeaderAPIKey sends only X-API-Key
+	AuthHeaderAPIKey AuthHeaderMode = "x-api-key"
+	// AuthHeaderBearer sends only Authorization: Bearer
+	AuthHeaderBearer AuthHeaderMode = "bearer"
+)
+
+// standard returns the standard mode m names, matched case-insensitively
+// so "Bearer" or "X-API-Key" is not taken for a custom header, or m itself
+func (m AuthHeaderMode) standard() AuthHeaderMode {
+	for _, mode := range []AuthHeaderMode{AuthHeaderBoth, AuthHeaderAPIKey, AuthHeaderBearer} {
+		if strings.EqualFold(string(m), string(mode)) {
+			return mode
+		}
+	}
+	return m
+}
+
+// customHeader returns the custom header named by the mode, or "" for the
+// standard modes
+func (m AuthHeaderMode) customHeader() string {
+	switch m.standard() {
+	case "", AuthHeaderBoth, AuthHeaderAPIKey, AuthHeaderBearer:
+		return ""
+	}
+	return string(m)
+}
+
+// setAPIKey sets the headers carrying apiKey on req
+func (m AuthHeaderMode) setAPIKey(req *http.Request, apiKey string) {
+	switch m.standard() {
+	case "", AuthHeaderBoth:
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	case AuthHeaderAPIKey:
+		req.Header.Set("X-API-Key", apiKey)
+	case AuthHeaderBearer:
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	default:
+		req.Header.Set(m.customHeader(), apiKey)
+	}
+}
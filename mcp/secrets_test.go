@@ -0,0 +1,208 @@
+package mcp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/spec"
+)
+
+// countingSecrets serves fixed secrets and counts lookups.
+type countingSecrets struct {
+	values map[string]string
+	calls  int
+}
+
+func (s *countingSecrets) Get(name string) (string, error) {
+	s.calls++
+	if value, ok := s.values[name]; ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func TestSecretProvider_SuppliesCredentials(t *testing.T) {
+	var apiKey, authorization string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiKey = r.Header.Get("X-API-Key")
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	secrets := &countingSecrets{values: map[string]string{SecretAPIKey: "from-provider"}}
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "from-config").
+		WithSecretProvider(secrets))
+
+	callTool(t, server, "listpets", map[string]any{})
+	if apiKey != "from-provider" || authorization != "Bearer from-provider" {
+		t.Errorf("expected provider API key, got X-API-Key=%q Authorization=%q", apiKey, authorization)
+	}
+
+	// Secrets are resolved per call, so a rotated value is picked up
+	secrets.values[SecretAPIKey] = "rotated"
+	callTool(t, server, "listpets", map[string]any{})
+	if apiKey != "rotated" {
+		t.Errorf("expected rotated API key, got %q", apiKey)
+	}
+
+	// Falls back to the config value when the provider has no key
+	delete(secrets.values, SecretAPIKey)
+	callTool(t, server, "listpets", map[string]any{})
+	if apiKey != "from-config" {
+		t.Errorf("expected config API key fallback, got %q", apiKey)
+	}
+}
+
+func TestCredentialFunc_KeysByPathPrefix(t *testing.T) {
+	keys := make(map[string]string)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys[r.URL.Path] = r.Header.Get("X-API-Key")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	swagger := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}},
+    "/admin/users": {"get": {"operationId": "listUsers", "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+	server := newTestServer(t, DefaultConfig().
+		WithSwaggerData([]byte(swagger)).
+		WithAPIConfig(backend.URL, "default-key").
+		WithCredentialFunc(func(method, path string, op *spec.Operation) (Credential, bool) {
+			if strings.HasPrefix(path, "/admin/") {
+				return Credential{APIKey: "admin-key"}, true
+			}
+			return Credential{}, false
+		}))
+
+	callTool(t, server, "listusers", map[string]any{})
+	callTool(t, server, "listpets", map[string]any{})
+	if keys["/admin/users"] != "admin-key" {
+		t.Errorf("/admin/users sent key %q, want admin-key", keys["/admin/users"])
+	}
+	if keys["/pets"] != "default-key" {
+		t.Errorf("/pets sent key %q, want default-key", keys["/pets"])
+	}
+}
+
+func TestSecretProvider_BasicAuthPassword(t *testing.T) {
+	var user, pass string
+	var ok bool
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok = r.BasicAuth()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithBasicAuth("alice", "").
+		WithSecretProvider(StaticSecrets{SecretBasicAuthPassword: "s3cret"}))
+
+	callTool(t, server, "listpets", map[string]any{})
+	if !ok || user != "alice" || pass != "s3cret" {
+		t.Errorf("expected basic auth alice:s3cret, got %q:%q (ok=%v)", user, pass, ok)
+	}
+}
+
+func TestSecretProvider_ErrorFailsCall(t *testing.T) {
+	var hits int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+	}))
+	defer backend.Close()
+
+	failing := ChainSecrets{FileSecrets{Dir: t.TempDir()}, failingSecrets{}}
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithSecretProvider(failing))
+
+	result := callTool(t, server, "listpets", map[string]any{})
+	if !result.IsError {
+		t.Errorf("expected an error result, got %q", resultText(t, result))
+	}
+	if hits != 0 {
+		t.Errorf("expected no upstream call, got %d", hits)
+	}
+}
+
+type failingSecrets struct{}
+
+func (failingSecrets) Get(string) (string, error) { return "", errors.New("vault unavailable") }
+
+func TestFileAndEnvSecrets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, SecretAPIKey), []byte("file-key\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_BASIC_AUTH_PASSWORD", "env-pass")
+
+	chain := ChainSecrets{EnvSecrets{Prefix: "APP_"}, FileSecrets{Dir: dir}}
+
+	if value, err := chain.Get(SecretAPIKey); err != nil || value != "file-key" {
+		t.Errorf("expected file-key, got %q (%v)", value, err)
+	}
+	if value, err := chain.Get(SecretBasicAuthPassword); err != nil || value != "env-pass" {
+		t.Errorf("expected env-pass, got %q (%v)", value, err)
+	}
+	if _, err := chain.Get(SecretOAuthClientSecret); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("expected ErrSecretNotFound, got %v", err)
+	}
+}
+
+func TestAuthHeaderMode_SendsOnlySelectedHeaders(t *testing.T) {
+	var header http.Header
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		mode AuthHeaderMode
+		want map[string]string
+	}{
+		{"", map[string]string{"X-Api-Key": "secret", "Authorization": "Bearer secret"}},
+		{AuthHeaderBoth, map[string]string{"X-Api-Key": "secret", "Authorization": "Bearer secret"}},
+		{AuthHeaderAPIKey, map[string]string{"X-Api-Key": "secret"}},
+		{AuthHeaderBearer, map[string]string{"Authorization": "Bearer secret"}},
+		{"Bearer", map[string]string{"Authorization": "Bearer secret"}},
+		{"X-API-Key", map[string]string{"X-Api-Key": "secret"}},
+		{"Both", map[string]string{"X-Api-Key": "secret", "Authorization": "Bearer secret"}},
+		{"X-Auth-Token", map[string]string{"X-Auth-Token": "secret"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			server := newTestServer(t, DefaultConfig().
+				WithAPIConfig(backend.URL, "secret").
+				WithAuthHeaderMode(tt.mode))
+
+			callTool(t, server, "listpets", map[string]any{})
+			for _, name := range []string{"X-Api-Key", "Authorization", "X-Auth-Token"} {
+				if got := header.Get(name); got != tt.want[name] {
+					t.Errorf("%s = %q, want %q", name, got, tt.want[name])
+				}
+			}
+		})
+	}
+
+	// A dry run masks the custom header like the standard ones
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "secret").
+		WithAuthHeaderMode("X-Auth-Token").
+		WithDryRun(true))
+	if text := resultText(t, callTool(t, server, "listpets", map[string]any{})); strings.Contains(text, "secret") {
+		t.Errorf("dry-run preview leaks the custom auth header: %s", text)
+	}
+}
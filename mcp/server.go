@@ -7,9 +7,11 @@ import (
     "log"
     "net/http"
     "strings"
+    "sync"
 
     "github.com/go-openapi/spec"
     "github.com/modelcontextprotocol/go-sdk/mcp"
+    "go.opentelemetry.io/otel/trace"
 )
 
 // Skill represents an Agent Skill metadata
@@ -32,6 +34,24 @@ type SwaggerMCPServer struct {
     apiKey      string
     filter      *APIFilter
     apiExecutor *APIExecutor
+    config      *Config
+
+    // mu guards swagger, filter and tools, which change when the spec is
+    // reloaded
+    mu    sync.RWMutex
+    tools map[string]registeredTool
+}
+
+// registeredTool is a tool definition as registered with the MCP server,
+// along with the operation it calls
+type registeredTool struct {
+    tool   *mcp.Tool
+    method string
+    path   string
+
+    // actions holds the operations of a grouped resource tool, keyed by
+    // the value of its action argument
+    actions map[string]resourceAction
 }
 
 // NewSwaggerMCPServer creates a new MCP server from Swagger spec
@@ -41,10 +61,21 @@ func NewSwaggerMCPServer(apiBaseURL string, swaggerSpec *spec.Swagger, apiKey st
 
 // NewSwaggerMCPServerWithFilter creates a new MCP server from Swagger spec with filtering
 func NewSwaggerMCPServerWithFilter(apiBaseURL string, swaggerSpec *spec.Swagger, apiKey string, filter *APIFilter) *SwaggerMCPServer {
+    config := DefaultConfig().
+        WithSwaggerSpec(swaggerSpec).
+        WithAPIConfig(apiBaseURL, apiKey).
+        WithAPIFilter(filter)
+
+    return newSwaggerMCPServer(config, config.Filter)
+}
+
+// newSwaggerMCPServer creates the MCP server and registers the tools filter
+// selects, using all execution options carried by config
+func newSwaggerMCPServer(config *Config, filter *APIFilter) *SwaggerMCPServer {
     // Create MCP server with Implementation
     implementation := &mcp.Implementation{
-        Name:    "swagger-mcp-server",
-        Version: "v1.0.0",
+        Name:    config.Name,
+        Version: config.Version,
     }
 
     server := mcp.NewServer(implementation, nil)
@@ -52,13 +83,17 @@ func NewSwaggerMCPServerWithFilter(apiBaseURL string, swaggerSpec *spec.Swagger,
     // Create converter
     converter := &SwaggerMCPServer{
         server:      server,
-        apiBaseURL:  apiBaseURL,
-        swagger:     swaggerSpec,
-        apiKey:      apiKey,
+        apiBaseURL:  config.APIBaseURL,
+        swagger:     config.SwaggerSpec,
+        apiKey:      config.APIKey,
         filter:      filter,
-        apiExecutor: NewAPIExecutor(apiBaseURL, apiKey),
+        apiExecutor: newAPIExecutorFromConfig(config),
+        config:      config,
     }
 
+    // Report invalid arguments by field path before the SDK validates them
+    server.AddReceivingMiddleware(converter.validateToolCalls)
+
     // Register tools from Swagger
     converter.RegisterTools()
 
@@ -141,8 +176,12 @@ func (s *SwaggerMCPServer) GetSkillsMetadataJSON() (string, error) {
 // groupOperationsByTag groups operations by their tags
 func (s *SwaggerMCPServer) groupOperationsByTag() map[string][]Operation {
     groups := make(map[string][]Operation)
+    swagger := s.currentSpec()
+    if swagger == nil || swagger.Paths == nil {
+        return groups
+    }
 
-    for path, pathItem := range s.swagger.Paths.Paths {
+    for path, pathItem := range swagger.Paths.Paths {
         operations := []struct {
             method string
             op     *spec.Operation
@@ -152,6 +191,8 @@ func (s *SwaggerMCPServer) groupOperationsByTag() map[string][]Operation {
             {"PUT", pathItem.Put},
             {"DELETE", pathItem.Delete},
             {"PATCH", pathItem.Patch},
+            {"HEAD", pathItem.Head},
+            {"OPTIONS", pathItem.Options},
         }
 
         for _, op := range operations {
@@ -205,17 +246,50 @@ func (s *SwaggerMCPServer) generateSkillDescription(tag string, operations []Ope
 
 // GenerateSkills generates Agent Skills files to the specified directory
 func (s *SwaggerMCPServer) GenerateSkills(outputDir string) error {
-    generator := NewSkillsGenerator(s.swagger, s.apiBaseURL, outputDir)
+    generator := NewSkillsGenerator(s.currentSpec(), s.apiBaseURL, outputDir)
     return generator.Generate()
 }
 
 // RegisterTools creates MCP tools from Swagger endpoints
 func (s *SwaggerMCPServer) RegisterTools() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.registerTools()
+}
+
+// registerTools registers a tool per operation in s.swagger, recording the
+// tool definitions. The caller must hold s.mu.
+func (s *SwaggerMCPServer) registerTools() {
+    s.tools = make(map[string]registeredTool)
+    if s.swagger == nil || s.swagger.Paths == nil {
+        log.Printf("Warning: spec defines no paths; no tools registered")
+        return
+    }
+    if s.config != nil && s.config.ResourceGrouping {
+        s.registerResourceTools()
+        return
+    }
     for path, pathItem := range s.swagger.Paths.Paths {
         s.registerPathTools(path, pathItem)
     }
 }
 
+// currentSpec returns the spec the tools are currently generated from
+func (s *SwaggerMCPServer) currentSpec() *spec.Swagger {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return s.swagger
+}
+
+// exposedSpec returns the spec the tools are currently generated from and
+// the filter selecting the operations registered as tools, MaxTools
+// truncation included
+func (s *SwaggerMCPServer) exposedSpec() (*spec.Swagger, *APIFilter) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return s.swagger, s.filter
+}
+
 func (s *SwaggerMCPServer) registerPathTools(path string, pathItem spec.PathItem) {
     // Register GET endpoints
     if pathItem.Get != nil {
@@ -241,11 +315,22 @@ func (s *SwaggerMCPServer) registerPathTools(path string, pathItem spec.PathItem
     if pathItem.Patch != nil {
         s.registerOperation("PATCH", path, pathItem.Patch)
     }
+
+    // Register HEAD endpoints (existence checks; the tool returns the
+    // status and headers)
+    if pathItem.Head != nil {
+        s.registerOperation("HEAD", path, pathItem.Head)
+    }
+
+    // Register OPTIONS endpoints
+    if pathItem.Options != nil {
+        s.registerOperation("OPTIONS", path, pathItem.Options)
+    }
 }
 
 func (s *SwaggerMCPServer) registerOperation(method, path string, op *spec.Operation) {
     // Check if this operation should be excluded
-    if s.filter != nil && s.filter.ShouldExcludeOperation(method, path, op) {
+    if !exposesOperation(s.filter, method, path, op) {
         return // Skip this operation
     }
 
@@ -253,30 +338,123 @@ func (s *SwaggerMCPServer) registerOperation(method, path string, op *spec.Opera
     toolName := GenerateToolName(method, path, op)
 
     // Build description using shared utility
-    description := GenerateToolDescription(method, path, op)
+    description := s.config.toolDescription(method, path, op)
+
+    inputSchema := s.operationInputSchema(toolName, method, path, op)
 
     // Create tool with basic info (input schema will be auto-generated)
     tool := &mcp.Tool{
         Name:        toolName,
         Description: description,
-        InputSchema: s.buildParametersSchema(op.Parameters), // Keep manual schema for now
+        InputSchema: inputSchema, // Keep manual schema for now
+        Annotations: toolAnnotations(method),
+        Meta:        toolMeta(op),
     }
 
     // Register the tool using the new generic AddTool function
     // This provides automatic type validation and schema generation
     mcp.AddTool(s.server, tool, s.createTypedHandler(method, path, op))
+    if s.tools != nil {
+        s.tools[toolName] = registeredTool{tool: tool, method: method, path: path}
+    }
+}
+
+// operationInputSchema builds the input schema of an operation's tool,
+// including the arguments added by the execution options
+func (s *SwaggerMCPServer) operationInputSchema(toolName, method, path string, op *spec.Operation) map[string]interface{} {
+    inputSchema := s.buildParametersSchema(op.Parameters)
+    if len(s.apiExecutor.RequestTransformArgs) > 0 {
+        dropRequired(inputSchema, s.apiExecutor.RequestTransformArgs)
+    }
+    if s.apiExecutor.ParamCoercion {
+        allowStringScalars(inputSchema)
+    }
+    if s.apiExecutor.PaginationAliases {
+        addPaginationAliasProperties(inputSchema, s.apiExecutor.paginationParams(method, path, op))
+    }
+    if follow, ok := s.apiExecutor.PageFollow[toolName]; ok {
+        addAllPagesProperty(inputSchema, follow)
+    }
+    if _, variables := s.apiExecutor.endpoint(); len(variables) > 0 {
+        addServerVariableProperties(inputSchema, variables)
+    }
+    return inputSchema
 }
 
-func (s *SwaggerMCPServer) buildParametersSchema(params []spec.Parameter) interface{} {
+// dropRequired removes names from schema's required arguments, for the
+// arguments a request transform supplies
+func dropRequired(schema map[string]interface{}, names []string) {
+    var required []string
+    for _, name := range schemaRequired(schema["required"]) {
+        if !containsString(names, name) {
+            required = append(required, name)
+        }
+    }
+    if len(required) > 0 {
+        schema["required"] = required
+    } else {
+        delete(schema, "required")
+    }
+}
+
+// toolMetaTags is the tool _meta key listing the operation's tags, which
+// clients can use to group tools by category
+const toolMetaTags = "tags"
+
+// toolMeta returns the _meta attached to an operation's tool, or nil when
+// there is nothing to attach
+func toolMeta(op *spec.Operation) mcp.Meta {
+    if len(op.Tags) == 0 {
+        return nil
+    }
+    tags := make([]string, len(op.Tags))
+    copy(tags, op.Tags)
+    return mcp.Meta{toolMetaTags: tags}
+}
+
+// toolAnnotations derives MCP behaviour hints from the HTTP method so clients
+// can tell safe reads from mutating calls
+func toolAnnotations(method string) *mcp.ToolAnnotations {
+    destructive := true
+    switch strings.ToUpper(method) {
+    case "GET", "HEAD", "OPTIONS":
+        return &mcp.ToolAnnotations{ReadOnlyHint: true}
+    case "PUT", "DELETE":
+        return &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: true}
+    case "PATCH":
+        return &mcp.ToolAnnotations{DestructiveHint: &destructive}
+    default:
+        // POST creates new resources: additive rather than destructive
+        additive := false
+        return &mcp.ToolAnnotations{DestructiveHint: &additive}
+    }
+}
+
+// isSecurityHeader reports whether name is the header of an apiKey security
+// scheme declared by the spec
+func (s *SwaggerMCPServer) isSecurityHeader(name string) bool {
+    if s.swagger == nil {
+        return false
+    }
+    for _, scheme := range s.swagger.SecurityDefinitions {
+        if scheme != nil && scheme.Type == "apiKey" && scheme.In == "header" && strings.EqualFold(scheme.Name, name) {
+            return true
+        }
+    }
+    return false
+}
+
+func (s *SwaggerMCPServer) buildParametersSchema(params []spec.Parameter) map[string]interface{} {
     properties := make(map[string]interface{})
     required := []string{}
 
     for _, param := range params {
-        // Skip header and cookie params
-        if param.In == "header" && !strings.EqualFold(param.Name, "content-type") {
+        // Skip auth headers, which the server sets from its own credentials,
+        // and cookie params unless they are passed per call
+        if param.In == "header" && (isAuthHeader(param.Name) || s.isSecurityHeader(param.Name)) {
             continue
         }
-        if param.In == "cookie" {
+        if param.In == "cookie" && (s.config == nil || !s.config.CookieParams) {
             continue
         }
 
@@ -291,11 +469,16 @@ func (s *SwaggerMCPServer) buildParametersSchema(params []spec.Parameter) interf
             // arrays, required lists and descriptions all survive.
             if full := schemaToMap(param.Schema); full != nil {
                 paramSchema = full
+                mergeAllOf(paramSchema)
             } else {
                 paramSchema["type"] = "object"
             }
             if _, ok := paramSchema["type"]; !ok {
-                paramSchema["type"] = "object"
+                if _, isArray := paramSchema["items"]; isArray {
+                    paramSchema["type"] = "array"
+                } else {
+                    paramSchema["type"] = "object"
+                }
             }
         }
 
@@ -303,6 +486,24 @@ func (s *SwaggerMCPServer) buildParametersSchema(params []spec.Parameter) interf
             paramSchema["description"] = param.Description
         }
 
+        // Carry JSON Schema keywords recorded as extensions (nullable,
+        // const, examples) over from non-body parameters
+        if param.Schema == nil {
+            for _, key := range schemaExtensionKeys {
+                if value, ok := param.Extensions[key]; ok {
+                    paramSchema[key] = value
+                }
+            }
+            if example := parameterExample(param); example != nil {
+                if _, ok := paramSchema["x-examples"]; !ok {
+                    paramSchema["examples"] = []interface{}{example}
+                }
+            }
+        } else {
+            liftSchemaExamples(paramSchema)
+        }
+        applySchemaExtensions(paramSchema)
+
         // Add format if specified
         if param.Format != "" {
             paramSchema["format"] = param.Format
@@ -331,6 +532,13 @@ func (s *SwaggerMCPServer) buildParametersSchema(params []spec.Parameter) interf
         }
     }
 
+    if s.config != nil && s.config.PerCallAuth {
+        properties[PerCallAuthArg] = map[string]interface{}{
+            "type":        "string",
+            "description": "API key to authenticate this call with, in place of the server's credentials",
+        }
+    }
+
     schema := map[string]interface{}{
         "type":       "object",
         "properties": properties,
@@ -358,39 +566,108 @@ type APIResponse struct {
 
 // Create a typed handler function that works with the generic AddTool
 func (s *SwaggerMCPServer) createTypedHandler(method, path string, op *spec.Operation) mcp.ToolHandlerFor[map[string]interface{}, APIResponse] {
+    toolName := GenerateToolName(method, path, op)
+
     return func(ctx context.Context, req *mcp.CallToolRequest, args map[string]interface{}) (*mcp.CallToolResult, APIResponse, error) {
-        // Use the shared API executor
-        content, statusCode, err := s.apiExecutor.BuildAndExecuteRequest(ctx, method, path, args)
+        if noCache, _ := req.Params.Meta["noCache"].(bool); noCache {
+            ctx = SkipCache(ctx)
+        }
+
+        result, err := s.executeTool(ctx, toolName, method, path, op, args)
         if err != nil {
             return nil, APIResponse{}, err
         }
 
-        // Create response
-        apiResponse := APIResponse{
-            Content: content,
-            Status:  statusCode,
+        return s.buildToolResult(method, result)
+    }
+}
+
+// executeTool runs a tool's upstream call(s) through the shared API executor
+// inside a tool span, following next-page links if the call asks for it
+func (s *SwaggerMCPServer) executeTool(ctx context.Context, toolName, method, path string, op *spec.Operation, args map[string]interface{}) (result *APIResult, err error) {
+    ctx, span := s.apiExecutor.tracer().Start(ctx, "tools/call "+toolName,
+        trace.WithAttributes(attrToolName.String(toolName), attrHTTPMethod.String(method), attrHTTPRoute.String(path)))
+    defer func() {
+        statusCode := 0
+        if result != nil {
+            statusCode = result.StatusCode
         }
+        endSpan(span, statusCode, err)
+    }()
+
+    schema, checks := s.toolInputSchema(toolName), s.argumentChecks()
+    if transform := s.apiExecutor.RequestTransform; transform != nil {
+        // StrictParams applies to the arguments the client sent; the
+        // transform may add arguments the spec does not declare
+        if err := validateArguments(schema, args, checks); err != nil {
+            return nil, err
+        }
+        checks.strict = false
 
-        // Check status code and create appropriate MCP result
-        if statusCode >= 400 {
-            return &mcp.CallToolResult{
-                Content: []mcp.Content{
-                    &mcp.TextContent{
-                        Text: fmt.Sprintf("API error %d: %s", statusCode, content),
-                    },
-                },
-                IsError: true,
-            }, apiResponse, nil
+        if args == nil {
+            args = make(map[string]interface{})
         }
+        if err := transform(toolName, method, path, args); err != nil {
+            return nil, fmt.Errorf("request transform failed: %w", err)
+        }
+    }
 
+    if err := validateArguments(schema, args, checks); err != nil {
+        return nil, err
+    }
+
+    allPages, _ := args[allPagesArg].(bool)
+    delete(args, allPagesArg)
+    if follow, ok := s.apiExecutor.PageFollow[toolName]; ok && allPages {
+        return s.apiExecutor.ExecuteAllPages(ctx, method, path, op, args, follow)
+    }
+    return s.apiExecutor.ExecuteOperation(ctx, method, path, op, args)
+}
+
+// buildToolResult converts an API result into the MCP tool result and the
+// structured APIResponse output
+func (s *SwaggerMCPServer) buildToolResult(method string, result *APIResult) (*mcp.CallToolResult, APIResponse, error) {
+    content := result.Content
+    statusCode := result.StatusCode
+
+    // Create response
+    apiResponse := APIResponse{
+        Content: content,
+        Status:  statusCode,
+    }
+
+    // Check status code and create appropriate MCP result
+    if statusCode >= 400 && (s.config == nil || s.config.isErrorStatus(statusCode)) {
+        return &mcp.CallToolResult{
+            Content: []mcp.Content{
+                &mcp.TextContent{
+                    Text: formatAPIError(result),
+                },
+            },
+            IsError: true,
+        }, apiResponse, nil
+    }
+
+    // Replace echoed resources from write operations with a short summary,
+    // keeping the full body in the result metadata
+    if s.config != nil && s.config.SummarizeWrites && isWriteMethod(method) && statusCode > 0 && statusCode < 400 {
         return &mcp.CallToolResult{
+            Meta: mcp.Meta{"fullResponse": content},
             Content: []mcp.Content{
                 &mcp.TextContent{
-                    Text: content,
+                    Text: summarizeWriteResponse(statusCode, result.Body),
                 },
             },
         }, apiResponse, nil
     }
+
+    return &mcp.CallToolResult{
+        Content: []mcp.Content{
+            &mcp.TextContent{
+                Text: content,
+            },
+        },
+    }, apiResponse, nil
 }
 
 // schemaToMap serializes a spec.Schema into a generic JSON-schema map.
@@ -407,6 +684,176 @@ func schemaToMap(schema *spec.Schema) map[string]interface{} {
     return m
 }
 
+// schemaExtensionKeys are the vendor extensions that stand in for JSON
+// Schema keywords Swagger 2.0 cannot express
+var schemaExtensionKeys = []string{"x-nullable", "x-const", "x-examples"}
+
+// applySchemaExtensions rewrites the extensions in schemaExtensionKeys into
+// JSON Schema keywords throughout schema: "x-nullable" adds "null" to
+// "type", "x-const" becomes "const" and "x-examples" becomes "examples"
+func applySchemaExtensions(schema map[string]interface{}) {
+    if nullable, _ := schema["x-nullable"].(bool); nullable {
+        switch t := schema["type"].(type) {
+        case string:
+            schema["type"] = []interface{}{t, "null"}
+        case []interface{}:
+            hasNull := false
+            for _, v := range t {
+                if v == "null" {
+                    hasNull = true
+                }
+            }
+            if !hasNull {
+                schema["type"] = append(t, "null")
+            }
+        }
+    }
+    if c, ok := schema["x-const"]; ok {
+        schema["const"] = c
+    }
+    if examples, ok := schema["x-examples"]; ok {
+        schema["examples"] = examples
+    }
+    for _, key := range schemaExtensionKeys {
+        delete(schema, key)
+    }
+
+    for _, child := range schema {
+        switch v := child.(type) {
+        case map[string]interface{}:
+            applySchemaExtensions(v)
+        case []interface{}:
+            for _, item := range v {
+                if m, ok := item.(map[string]interface{}); ok {
+                    applySchemaExtensions(m)
+                }
+            }
+        }
+    }
+}
+
+// parameterExample returns a parameter's example value: the schema example
+// of a body parameter, else the "example" or "x-example" of a simple one
+func parameterExample(param spec.Parameter) interface{} {
+    if param.Schema != nil {
+        return param.Schema.Example
+    }
+    if param.Example != nil {
+        return param.Example
+    }
+    return param.Extensions["x-example"]
+}
+
+// liftSchemaExamples turns the OpenAPI "example" keyword into the JSON
+// Schema "examples" keyword throughout a serialized schema, following only
+// subschema positions so properties named "example" are left alone
+func liftSchemaExamples(schema map[string]interface{}) {
+    if example, ok := schema["example"]; ok {
+        if _, hasExamples := schema["examples"]; !hasExamples {
+            if _, hasXExamples := schema["x-examples"]; !hasXExamples {
+                schema["examples"] = []interface{}{example}
+            }
+        }
+        delete(schema, "example")
+    }
+
+    if properties, ok := schema["properties"].(map[string]interface{}); ok {
+        for _, property := range properties {
+            if m, ok := property.(map[string]interface{}); ok {
+                liftSchemaExamples(m)
+            }
+        }
+    }
+    for _, key := range []string{"items", "additionalProperties"} {
+        if m, ok := schema[key].(map[string]interface{}); ok {
+            liftSchemaExamples(m)
+        }
+    }
+    for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+        if list, ok := schema[key].([]interface{}); ok {
+            for _, item := range list {
+                if m, ok := item.(map[string]interface{}); ok {
+                    liftSchemaExamples(m)
+                }
+            }
+        }
+    }
+}
+
+// mergeAllOf folds the allOf subschemas of a serialized schema, and of the
+// schemas nested in it, into their parent: properties and required lists
+// are combined and other keywords are taken from the first subschema that
+// sets them, unless the parent sets them itself. oneOf and anyOf stay as
+// they are, with their variants merged in turn.
+func mergeAllOf(schema map[string]interface{}) {
+    if properties, ok := schema["properties"].(map[string]interface{}); ok {
+        for _, property := range properties {
+            if m, ok := property.(map[string]interface{}); ok {
+                mergeAllOf(m)
+            }
+        }
+    }
+    for _, key := range []string{"items", "additionalProperties"} {
+        if m, ok := schema[key].(map[string]interface{}); ok {
+            mergeAllOf(m)
+        }
+    }
+    for _, key := range []string{"allOf", "anyOf", "oneOf"} {
+        if list, ok := schema[key].([]interface{}); ok {
+            for _, item := range list {
+                if m, ok := item.(map[string]interface{}); ok {
+                    mergeAllOf(m)
+                }
+            }
+        }
+    }
+
+    subschemas, ok := schema["allOf"].([]interface{})
+    if !ok {
+        return
+    }
+    delete(schema, "allOf")
+
+    properties, _ := schema["properties"].(map[string]interface{})
+    required := schemaRequired(schema["required"])
+    for _, item := range subschemas {
+        sub, ok := item.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        for key, value := range sub {
+            switch key {
+            case "properties":
+                subProperties, _ := value.(map[string]interface{})
+                for name, property := range subProperties {
+                    if properties == nil {
+                        properties = make(map[string]interface{})
+                    }
+                    if _, exists := properties[name]; !exists {
+                        properties[name] = property
+                    }
+                }
+            case "required":
+                for _, name := range schemaRequired(value) {
+                    if !containsString(required, name) {
+                        required = append(required, name)
+                    }
+                }
+            default:
+                if _, exists := schema[key]; !exists {
+                    schema[key] = value
+                }
+            }
+        }
+    }
+    if properties != nil {
+        schema["properties"] = properties
+    }
+    if len(required) > 0 {
+        schema["required"] = required
+    }
+}
+
 func getJSONType(swaggerType string) string {
     switch swaggerType {
     case "integer":
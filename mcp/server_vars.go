@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// Extensions recording an OpenAPI 3 spec's first server URL template and
+// its variables, which the Swagger 2.0 conversion drops
+const (
+	extServerURL       = "x-server-url"
+	extServerVariables = "x-server-variables"
+)
+
+// ServerVariableArgPrefix prefixes the tool arguments that fill in server
+// URL variables, e.g. _server_region for {region}
+const ServerVariableArgPrefix = "_server_"
+
+// ServerVariable is a variable of a templated server URL such as
+// https://{region}.api.example.com
+type ServerVariable struct {
+	Default     string   `json:"default"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// serverTemplate returns the server URL template and variables recorded in
+// swagger, if any
+func serverTemplate(swagger *spec.Swagger) (string, map[string]ServerVariable) {
+	if swagger == nil {
+		return "", nil
+	}
+	template, _ := swagger.Extensions.GetString(extServerURL)
+	if template == "" {
+		return "", nil
+	}
+	var variables map[string]ServerVariable
+	if raw, ok := swagger.Extensions[extServerVariables]; ok {
+		if data, err := json.Marshal(raw); err == nil {
+			_ = json.Unmarshal(data, &variables)
+		}
+	}
+	return template, variables
+}
+
+// expandServerURL substitutes variables into a server URL template, taking
+// each value from values when present and from the variable's default
+// otherwise. A value outside a variable's enum is an error.
+func expandServerURL(template string, variables map[string]ServerVariable, values map[string]string) (string, error) {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	expanded := template
+	for _, name := range names {
+		variable := variables[name]
+		value, ok := values[name]
+		if !ok {
+			value = variable.Default
+		}
+		if len(variable.Enum) > 0 && !containsString(variable.Enum, value) {
+			return "", fmt.Errorf("invalid value %q for server variable %s: expected one of %s", value, name, strings.Join(variable.Enum, ", "))
+		}
+		expanded = strings.ReplaceAll(expanded, "{"+name+"}", value)
+	}
+	return expanded, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// takeServerVariableArgs removes the server variable arguments from args,
+// returning their values keyed by variable name
+func takeServerVariableArgs(args map[string]interface{}, variables map[string]ServerVariable) map[string]string {
+	values := make(map[string]string)
+	for name := range variables {
+		key := ServerVariableArgPrefix + name
+		if value, ok := args[key]; ok {
+			values[name] = fmt.Sprintf("%v", value)
+			delete(args, key)
+		}
+	}
+	return values
+}
+
+// addServerVariableProperties exposes server variables as optional tool
+// arguments with their defaults
+func addServerVariableProperties(schema map[string]interface{}, variables map[string]ServerVariable) {
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	for name, variable := range variables {
+		property := map[string]interface{}{
+			"type":    "string",
+			"default": variable.Default,
+		}
+		description := fmt.Sprintf("Value of {%s} in the API server URL", name)
+		if variable.Description != "" {
+			description = variable.Description
+		}
+		property["description"] = description
+		if len(variable.Enum) > 0 {
+			enum := make([]interface{}, len(variable.Enum))
+			for i, value := range variable.Enum {
+				enum[i] = value
+			}
+			property["enum"] = enum
+		}
+		properties[ServerVariableArgPrefix+name] = property
+	}
+}
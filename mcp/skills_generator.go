@@ -63,6 +63,9 @@ type Operation struct {
 // groupByTag groups operations by their tags
 func (sg *SkillsGenerator) groupByTag() map[string][]Operation {
 	groups := make(map[string][]Operation)
+	if sg.swagger == nil || sg.swagger.Paths == nil {
+		return groups
+	}
 
 	for path, pathItem := range sg.swagger.Paths.Paths {
 		operations := []struct {
@@ -74,6 +77,8 @@ func (sg *SkillsGenerator) groupByTag() map[string][]Operation {
 			{"PUT", pathItem.Put},
 			{"DELETE", pathItem.Delete},
 			{"PATCH", pathItem.Patch},
+			{"HEAD", pathItem.Head},
+			{"OPTIONS", pathItem.Options},
 		}
 
 		for _, op := range operations {
@@ -0,0 +1,233 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-openapi/spec"
+)
+
+// ToolInfo describes a tool generated from a spec operation, as served by
+// the HTTP /tools endpoint
+type ToolInfo struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Summary      string          `json:"summary,omitempty"` // The operation's own summary, if any
+	Method       string          `json:"method"`
+	Path         string          `json:"path"`
+	Parameters   []ParameterInfo `json:"parameters"`
+	OperationID  string          `json:"operationId"`
+	Tags         []string        `json:"tags,omitempty"`
+	Deprecated   bool            `json:"deprecated"`
+	Sunset       string          `json:"sunset,omitempty"`       // x-sunset date, if any
+	ExternalDocs string          `json:"externalDocs,omitempty"` // URL of the operation's external docs
+
+	// Schema of the success response describing the tool's output, and the
+	// status code it was documented under (0 for the default response)
+	ResponseCode   int                    `json:"responseCode,omitempty"`
+	ResponseSchema map[string]interface{} `json:"responseSchema,omitempty"`
+
+	// Set by DescribeTool only: the tool's full input schema and example
+	// arguments assembled from the spec's examples and defaults
+	InputSchema map[string]interface{} `json:"inputSchema,omitempty"`
+	ExampleArgs map[string]interface{} `json:"exampleArgs,omitempty"`
+}
+
+// ErrToolNotFound is returned by DescribeTool for a name that is not an
+// available tool
+var ErrToolNotFound = errors.New("tool not found")
+
+// ParameterInfo describes a parameter of a tool's operation
+type ParameterInfo struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Required    bool        `json:"required"`
+	Description string      `json:"description"`
+	Type        string      `json:"type,omitempty"`
+	Format      string      `json:"format,omitempty"`
+	Example     interface{} `json:"example,omitempty"` // Example value, or example request body
+}
+
+// ListToolsDetailed returns the available tools (after filtering) with
+// their operation metadata, sorted by name
+func (s *Server) ListToolsDetailed() []ToolInfo {
+	swagger, filter := s.mcp.exposedSpec()
+	tools := []ToolInfo{}
+	if swagger == nil || swagger.Paths == nil {
+		return tools
+	}
+
+	for path, pathItem := range swagger.Paths.Paths {
+		operations := []struct {
+			method string
+			op     *spec.Operation
+		}{
+			{"GET", pathItem.Get},
+			{"POST", pathItem.Post},
+			{"PUT", pathItem.Put},
+			{"DELETE", pathItem.Delete},
+			{"PATCH", pathItem.Patch},
+			{"HEAD", pathItem.Head},
+			{"OPTIONS", pathItem.Options},
+		}
+
+		for _, operation := range operations {
+			if !exposesOperation(filter, operation.method, path, operation.op) {
+				continue
+			}
+			info := newToolInfo(operation.method, path, operation.op, s.config.PreferredResponseCode)
+			info.Description = s.config.toolDescription(operation.method, path, operation.op)
+			tools = append(tools, info)
+		}
+	}
+
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	return tools
+}
+
+// DescribeTool returns the full description of one available tool: its
+// operation metadata, input schema and example arguments. It returns an
+// error wrapping ErrToolNotFound for an unknown or filtered-out name.
+func (s *Server) DescribeTool(name string) (*ToolInfo, error) {
+	swagger, filter := s.mcp.exposedSpec()
+	method, path, op := FindOperationByToolName(name, swagger, filter)
+	if op == nil {
+		return nil, fmt.Errorf("%w: %q", ErrToolNotFound, name)
+	}
+
+	info := newToolInfo(method, path, op, s.config.PreferredResponseCode)
+	info.Description = s.config.toolDescription(method, path, op)
+	// A copy, so callers cannot change the registered schema
+	if data, err := json.Marshal(s.mcp.toolInputSchema(name)); err == nil {
+		_ = json.Unmarshal(data, &info.InputSchema)
+	}
+	if example := s.exampleCall(method, path, op); len(example.Args) > 0 {
+		info.ExampleArgs = example.Args
+	}
+	return &info, nil
+}
+
+// EffectiveSpec returns a copy of the spec reduced to the operations exposed
+// as tools: operations excluded by the API filter, and paths left without
+// operations, are removed. Specs loaded from OpenAPI 3 are returned in
+// their converted Swagger 2.0 form.
+func (s *Server) EffectiveSpec() (*spec.Swagger, error) {
+	swagger, filter := s.mcp.exposedSpec()
+	if swagger == nil {
+		return nil, fmt.Errorf("no spec loaded")
+	}
+	data, err := json.Marshal(swagger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy spec: %w", err)
+	}
+	effective := &spec.Swagger{}
+	if err := json.Unmarshal(data, effective); err != nil {
+		return nil, fmt.Errorf("failed to copy spec: %w", err)
+	}
+	if effective.Paths == nil {
+		return effective, nil
+	}
+
+	for path, pathItem := range effective.Paths.Paths {
+		for _, operation := range []struct {
+			method string
+			op     **spec.Operation
+		}{
+			{"GET", &pathItem.Get},
+			{"POST", &pathItem.Post},
+			{"PUT", &pathItem.Put},
+			{"DELETE", &pathItem.Delete},
+			{"PATCH", &pathItem.Patch},
+			{"HEAD", &pathItem.Head},
+			{"OPTIONS", &pathItem.Options},
+		} {
+			if *operation.op != nil && !exposesOperation(filter, operation.method, path, *operation.op) {
+				*operation.op = nil
+			}
+		}
+		if pathItem.Get == nil && pathItem.Post == nil && pathItem.Put == nil && pathItem.Delete == nil &&
+			pathItem.Patch == nil && pathItem.Head == nil && pathItem.Options == nil {
+			delete(effective.Paths.Paths, path)
+			continue
+		}
+		effective.Paths.Paths[path] = pathItem
+	}
+	return effective, nil
+}
+
+// newToolInfo creates tool information from a swagger operation, describing
+// its output with the preferredCode response when documented
+func newToolInfo(method, path string, op *spec.Operation, preferredCode int) ToolInfo {
+	parameters := []ParameterInfo{}
+	for _, param := range op.Parameters {
+		parameters = append(parameters, ParameterInfo{
+			Name:        param.Name,
+			In:          param.In,
+			Required:    param.Required,
+			Description: param.Description,
+			Type:        param.Type,
+			Format:      param.Format,
+			Example:     parameterExample(param),
+		})
+	}
+
+	sunset, _ := op.Extensions.GetString("x-sunset")
+	externalDocs := ""
+	if op.ExternalDocs != nil {
+		externalDocs = op.ExternalDocs.URL
+	}
+	responseCode, responseSchema := successResponse(op, preferredCode)
+	info := ToolInfo{
+		Name:         GenerateToolName(method, path, op),
+		Description:  GenerateToolDescription(method, path, op),
+		Summary:      op.Summary,
+		Method:       method,
+		Path:         path,
+		Parameters:   parameters,
+		OperationID:  op.ID,
+		Tags:         op.Tags,
+		Deprecated:   op.Deprecated,
+		Sunset:       sunset,
+		ExternalDocs: externalDocs,
+		ResponseCode: responseCode,
+	}
+	if responseSchema != nil {
+		info.ResponseSchema = schemaToMap(responseSchema)
+	}
+	return info
+}
+
+// CallTool invokes a tool by name from Go code, without an MCP client. It
+// makes the same upstream call as tools/call and returns the formatted
+// response content and HTTP status code; API error statuses are reported
+// through the status code rather than err.
+func (s *Server) CallTool(ctx context.Context, toolName string, args map[string]interface{}) (string, int, error) {
+	if s.mcp.isResourceTool(toolName) {
+		_, result, err := s.mcp.callResourceTool(ctx, toolName, copyArgs(args))
+		if err != nil {
+			if result != nil {
+				return "", result.StatusCode, err
+			}
+			return "", 0, err
+		}
+		return result.Content, result.StatusCode, nil
+	}
+
+	swagger, filter := s.mcp.exposedSpec()
+	method, path, op := FindOperationByToolName(toolName, swagger, filter)
+	if op == nil {
+		return "", 0, fmt.Errorf("unknown tool %q", toolName)
+	}
+
+	result, err := s.mcp.executeTool(ctx, toolName, method, path, op, copyArgs(args))
+	if err != nil {
+		if result != nil {
+			return "", result.StatusCode, err
+		}
+		return "", 0, err
+	}
+	return result.Content, result.StatusCode, nil
+}
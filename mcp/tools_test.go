@@ -0,0 +1,384 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestListToolsDetailed_MatchesListTools(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig("http://localhost:1", "").
+		WithExcludeMethods("DELETE"))
+
+	names := server.ListTools()
+	detailed := server.ListToolsDetailed()
+	if len(names) != len(detailed) {
+		t.Fatalf("ListTools returned %d names, ListToolsDetailed %d tools", len(names), len(detailed))
+	}
+	for i, tool := range detailed {
+		if tool.Name != names[i] {
+			t.Errorf("tool %d: detailed name %q, ListTools name %q", i, tool.Name, names[i])
+		}
+	}
+
+	// The names match what MCP clients see
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := connectTestClient(t, server).ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var registered []string
+	for _, tool := range result.Tools {
+		registered = append(registered, tool.Name)
+	}
+	sort.Strings(registered)
+	if len(registered) != len(names) {
+		t.Fatalf("registered tools %v, ListTools %v", registered, names)
+	}
+	for i := range registered {
+		if registered[i] != names[i] {
+			t.Errorf("registered tools %v, ListTools %v", registered, names)
+			break
+		}
+	}
+
+	want := map[string][2]string{
+		"listpets":  {"GET", "/pets"},
+		"createpet": {"POST", "/pets"},
+		"updatepet": {"PUT", "/pets/{petId}"},
+	}
+	for _, tool := range detailed {
+		w, ok := want[tool.Name]
+		if !ok {
+			t.Errorf("unexpected tool %q", tool.Name)
+			continue
+		}
+		if tool.Method != w[0] || tool.Path != w[1] {
+			t.Errorf("%s: %s %s, want %s %s", tool.Name, tool.Method, tool.Path, w[0], w[1])
+		}
+		if tool.OperationID == "" || tool.Description == "" {
+			t.Errorf("%s: missing operationId or description: %+v", tool.Name, tool)
+		}
+	}
+
+	for _, tool := range detailed {
+		if tool.Name == "listpets" {
+			if len(tool.Parameters) != 1 || tool.Parameters[0].Name != "limit" || tool.Parameters[0].In != "query" {
+				t.Errorf("listpets parameters = %+v", tool.Parameters)
+			}
+		}
+	}
+}
+
+func TestServer_CallTool(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "no such pet"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id": 1, "limit": "` + r.URL.Query().Get("limit") + `"}]`))
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+
+	args := map[string]interface{}{"limit": 3}
+	content, status, err := server.CallTool(context.Background(), "listpets", args)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if status != http.StatusOK || !strings.Contains(content, `"limit":"3"`) {
+		t.Errorf("CallTool = %d %q", status, content)
+	}
+	if _, ok := args["limit"]; !ok {
+		t.Error("CallTool modified the caller's arguments")
+	}
+
+	content, status, err = server.CallTool(context.Background(), "deletepet", map[string]interface{}{"petId": "9"})
+	if err != nil || status != http.StatusNotFound || !strings.Contains(content, "no such pet") {
+		t.Errorf("CallTool(deletepet) = %d %q %v", status, content, err)
+	}
+
+	if _, _, err := server.CallTool(context.Background(), "nosuchtool", nil); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+}
+
+func TestExamples_PropagateToSchemaAndToolInfo(t *testing.T) {
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "post": {
+        "operationId": "createPet",
+        "externalDocs": {"url": "https://docs.example.com/pets#create"},
+        "parameters": [
+          {"name": "dryRun", "in": "query", "type": "boolean", "x-example": true},
+          {"name": "body", "in": "body", "schema": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string", "example": "Rex"},
+              "example": {"type": "string"}
+            },
+            "example": {"name": "Rex", "tag": "dog"}
+          }}
+        ],
+        "responses": {"201": {"description": "Created"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)).WithAPIConfig("http://localhost:1", ""))
+
+	op := server.config.SwaggerSpec.Paths.Paths["/pets"].Post
+	properties := server.mcp.buildParametersSchema(op.Parameters)["properties"].(map[string]interface{})
+
+	dryRun := properties["dryRun"].(map[string]interface{})
+	if examples, _ := dryRun["examples"].([]interface{}); len(examples) != 1 || examples[0] != true {
+		t.Errorf("expected the parameter example in examples, got %v", dryRun)
+	}
+
+	body := properties["body"].(map[string]interface{})
+	examples, _ := body["examples"].([]interface{})
+	if len(examples) != 1 {
+		t.Fatalf("expected the body example in examples, got %v", body)
+	}
+	if example, _ := examples[0].(map[string]interface{}); example["tag"] != "dog" {
+		t.Errorf("unexpected body example %v", examples[0])
+	}
+	if _, ok := body["example"]; ok {
+		t.Errorf("expected example to be replaced by examples, got %v", body)
+	}
+	bodyProperties := body["properties"].(map[string]interface{})
+	name := bodyProperties["name"].(map[string]interface{})
+	if examples, _ := name["examples"].([]interface{}); len(examples) != 1 || examples[0] != "Rex" {
+		t.Errorf("expected the property example in examples, got %v", name)
+	}
+	if _, ok := bodyProperties["example"]; !ok {
+		t.Errorf("a property named example must be kept, got %v", bodyProperties)
+	}
+
+	tools := server.ListToolsDetailed()
+	if len(tools) != 1 {
+		t.Fatalf("expected one tool, got %d", len(tools))
+	}
+	if tools[0].ExternalDocs != "https://docs.example.com/pets#create" {
+		t.Errorf("expected externalDocs, got %q", tools[0].ExternalDocs)
+	}
+	for _, param := range tools[0].Parameters {
+		if param.Example == nil {
+			t.Errorf("expected an example for parameter %s", param.Name)
+		}
+	}
+}
+
+func TestResponseSchema_PreferredCode(t *testing.T) {
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets": {
+      "put": {
+        "operationId": "upsertPet",
+        "responses": {
+          "200": {"description": "Updated", "schema": {"type": "object", "properties": {"updated": {"type": "boolean"}}}},
+          "201": {"description": "Created", "schema": {"type": "object", "properties": {"id": {"type": "integer"}}}},
+          "default": {"description": "Error", "schema": {"type": "object", "properties": {"message": {"type": "string"}}}}
+        }
+      },
+      "delete": {
+        "operationId": "deletePets",
+        "responses": {
+          "204": {"description": "Deleted"},
+          "default": {"description": "Error", "schema": {"type": "object", "properties": {"message": {"type": "string"}}}}
+        }
+      }
+    }
+  }
+}`
+	responseProperty := func(tool ToolInfo) string {
+		properties, _ := tool.ResponseSchema["properties"].(map[string]interface{})
+		for name := range properties {
+			return name
+		}
+		return ""
+	}
+	tests := []struct {
+		name      string
+		preferred int
+		tool      string
+		wantCode  int
+		wantProp  string
+	}{
+		{"lowest 2xx by default", 0, "upsertpet", 200, "updated"},
+		{"preferred code", 201, "upsertpet", 201, "id"},
+		{"undocumented preferred code falls back", 202, "upsertpet", 200, "updated"},
+		{"default response without a 2xx schema", 201, "deletepets", 0, "message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, DefaultConfig().
+				WithSwaggerData([]byte(spec)).
+				WithPreferredResponseCode(tt.preferred))
+			for _, tool := range server.ListToolsDetailed() {
+				if tool.Name != tt.tool {
+					continue
+				}
+				if tool.ResponseCode != tt.wantCode || responseProperty(tool) != tt.wantProp {
+					t.Errorf("response = %d %v, want %d with %q", tool.ResponseCode, tool.ResponseSchema, tt.wantCode, tt.wantProp)
+				}
+				return
+			}
+			t.Fatalf("tool %s not found", tt.tool)
+		})
+	}
+}
+
+func TestGenerateExampleCalls(t *testing.T) {
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Pet API", "version": "1.0.0"},
+  "paths": {
+    "/pets/{petId}": {
+      "put": {
+        "operationId": "updatePet",
+        "parameters": [
+          {"name": "petId", "in": "path", "required": true, "type": "string", "x-example": "pet-42"},
+          {"name": "notify", "in": "query", "type": "boolean", "default": true},
+          {"name": "body", "in": "body", "required": true, "schema": {
+            "type": "object",
+            "properties": {
+              "name": {"type": "string", "example": "Rex"},
+              "age": {"type": "integer", "example": 3},
+              "tags": {"type": "array", "items": {"type": "string", "example": "friendly"}},
+              "notes": {"type": "string"}
+            }
+          }}
+        ],
+        "responses": {"200": {"description": "Updated"}}
+      }
+    },
+    "/pets": {
+      "get": {
+        "operationId": "listPets",
+        "parameters": [
+          {"name": "species", "in": "query", "required": true, "type": "string"},
+          {"name": "limit", "in": "query", "type": "integer", "x-example": 10}
+        ],
+        "responses": {"200": {"description": "OK"}}
+      }
+    }
+  }
+}`
+	server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(spec)))
+
+	calls := server.GenerateExampleCalls()
+	if len(calls) != 2 || calls[0].Tool != "listpets" || calls[1].Tool != "updatepet" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+
+	list := calls[0]
+	if list.Args["limit"] != float64(10) || len(list.Missing) != 1 || list.Missing[0] != "species" {
+		t.Errorf("listpets call = %+v", list)
+	}
+
+	update := calls[1]
+	if update.Method != "PUT" || update.Path != "/pets/{petId}" || len(update.Missing) != 0 {
+		t.Errorf("updatepet call = %+v", update)
+	}
+	if update.Args["petId"] != "pet-42" || update.Args["notify"] != true {
+		t.Errorf("path/query args = %v", update.Args)
+	}
+	body, _ := update.Args["body"].(map[string]interface{})
+	tags, _ := body["tags"].([]interface{})
+	if body["name"] != "Rex" || body["age"] != float64(3) || len(tags) != 1 || tags[0] != "friendly" {
+		t.Errorf("body = %v", body)
+	}
+	if _, ok := body["notes"]; ok {
+		t.Errorf("properties without an example must be left out, got %v", body)
+	}
+}
+
+func TestToolMeta_CarriesOperationTags(t *testing.T) {
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig("http://localhost:1", "").
+		WithSwaggerData([]byte(`{
+			"swagger": "2.0",
+			"info": {"title": "t", "version": "1"},
+			"paths": {
+				"/pets": {"get": {"operationId": "listPets", "tags": ["pet", "public"], "responses": {"200": {"description": "OK"}}}},
+				"/health": {"get": {"operationId": "health", "responses": {"200": {"description": "OK"}}}}
+			}
+		}`)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := connectTestClient(t, server).ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	tags := map[string]interface{}{}
+	for _, tool := range result.Tools {
+		tags[tool.Name] = tool.Meta["tags"]
+	}
+	if got, _ := tags["listpets"].([]interface{}); len(got) != 2 || got[0] != "pet" || got[1] != "public" {
+		t.Errorf("listpets _meta tags = %v, want [pet public]", tags["listpets"])
+	}
+	if tags["health"] != nil {
+		t.Errorf("untagged tool has _meta tags %v", tags["health"])
+	}
+
+	for _, tool := range server.ListToolsDetailed() {
+		if tool.Name == "listpets" && strings.Join(tool.Tags, ",") != "pet,public" {
+			t.Errorf("ListToolsDetailed tags = %v", tool.Tags)
+		}
+	}
+}
+
+func TestToolSets_AgreeWithAndWithoutFilter(t *testing.T) {
+	configs := map[string]func() *Config{
+		"no filter": func() *Config { return DefaultConfig() },
+		"filter": func() *Config {
+			return DefaultConfig().WithAPIFilter(&APIFilter{
+				ExcludeMethods: []string{"DELETE"},
+				ExcludePaths:   []string{"/pets/{petId}"},
+			})
+		},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			server := newTestServer(t, config())
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			result, err := connectTestClient(t, server).ListTools(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			var registered []string
+			for _, tool := range result.Tools {
+				registered = append(registered, tool.Name)
+			}
+			sort.Strings(registered)
+
+			listed := server.ListTools()
+			served := fetchToolsListing(t, server)
+			if strings.Join(listed, ",") != strings.Join(registered, ",") || len(served) != len(registered) {
+				t.Errorf("registered %v, ListTools %v, /tools %d tools", registered, listed, len(served))
+			}
+			for _, name := range registered {
+				if _, _, op := FindOperationByToolName(name, server.config.SwaggerSpec, server.config.Filter); op == nil {
+					t.Errorf("FindOperationByToolName(%q) found nothing", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Span attribute keys used for tool invocations and upstream HTTP calls
+const (
+	attrToolName       = attribute.Key("mcp.tool.name")
+	attrHTTPMethod     = attribute.Key("http.request.method")
+	attrHTTPRoute      = attribute.Key("url.template")
+	attrHTTPStatusCode = attribute.Key("http.response.status_code")
+)
+
+// noopTracer is used when no tracer is configured so instrumentation code
+// never has to check for nil
+var noopTracer = noop.NewTracerProvider().Tracer("")
+
+// tracer returns the configured tracer, or a no-op tracer if none is set
+func (e *APIExecutor) tracer() trace.Tracer {
+	if e.Tracer == nil {
+		return noopTracer
+	}
+	return e.Tracer
+}
+
+// injectTraceHeaders propagates the span context in ctx to the outgoing
+// request using W3C Trace Context headers
+func injectTraceHeaders(ctx context.Context, req *http.Request) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+}
+
+// endSpan records the outcome of a call on span and ends it
+func endSpan(span trace.Span, statusCode int, err error) {
+	if statusCode > 0 {
+		span.SetAttributes(attrHTTPStatusCode.Int(statusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if statusCode >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+	span.End()
+}
@@ -0,0 +1,169 @@
+package mcp
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingTracer is an in-memory tracer that keeps the spans it ends, so
+// the tests need nothing beyond the trace API
+type recordingTracer struct {
+	noop.Tracer
+
+	mu    sync.Mutex
+	ids   uint64
+	ended []*recordedSpan
+}
+
+// recordedSpan is a span started by a recordingTracer
+type recordedSpan struct {
+	noop.Span
+
+	tracer     *recordingTracer
+	kind       trace.SpanKind
+	context    trace.SpanContext
+	parent     trace.SpanContext
+	attributes []attribute.KeyValue
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	parent := trace.SpanContextFromContext(ctx)
+
+	t.mu.Lock()
+	t.ids++
+	id := t.ids
+	t.mu.Unlock()
+
+	var traceID trace.TraceID
+	var spanID trace.SpanID
+	if parent.IsValid() {
+		traceID = parent.TraceID()
+	} else {
+		binary.BigEndian.PutUint64(traceID[8:], id)
+	}
+	binary.BigEndian.PutUint64(spanID[:], id)
+
+	span := &recordedSpan{
+		tracer: t,
+		kind:   config.SpanKind(),
+		context: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     spanID,
+			TraceFlags: trace.FlagsSampled,
+		}),
+		parent:     parent,
+		attributes: config.Attributes(),
+	}
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext { return s.context }
+
+func (s *recordedSpan) IsRecording() bool { return true }
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attributes = append(s.attributes, kv...)
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tracer.ended = append(s.tracer.ended, s)
+}
+
+// spans returns the spans ended so far
+func (t *recordingTracer) spans() []*recordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*recordedSpan(nil), t.ended...)
+}
+
+func spanAttr(span *recordedSpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing_SpansPerToolCall(t *testing.T) {
+	var traceparent string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer backend.Close()
+
+	tracer := &recordingTracer{}
+	server := newTestServer(t, DefaultConfig().
+		WithAPIConfig(backend.URL, "").
+		WithTracer(tracer))
+
+	callTool(t, server, "deletepet", map[string]any{"petId": "1"})
+
+	spans := tracer.spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans (tool + HTTP), got %d", len(spans))
+	}
+
+	var toolSpan, httpSpan *recordedSpan
+	for _, s := range spans {
+		if s.kind == trace.SpanKindClient {
+			httpSpan = s
+		} else {
+			toolSpan = s
+		}
+	}
+	if toolSpan == nil || httpSpan == nil {
+		t.Fatalf("missing tool or HTTP span: %+v", spans)
+	}
+
+	if v, _ := spanAttr(toolSpan, attrToolName); v.AsString() != "deletepet" {
+		t.Errorf("tool span %s = %q, want deletepet", attrToolName, v.AsString())
+	}
+	if v, _ := spanAttr(httpSpan, attrHTTPMethod); v.AsString() != "DELETE" {
+		t.Errorf("HTTP span method = %q, want DELETE", v.AsString())
+	}
+	if v, _ := spanAttr(httpSpan, attrHTTPRoute); v.AsString() != "/pets/{petId}" {
+		t.Errorf("HTTP span route = %q, want /pets/{petId}", v.AsString())
+	}
+	if v, _ := spanAttr(httpSpan, attrHTTPStatusCode); v.AsInt64() != 200 {
+		t.Errorf("HTTP span status = %d, want 200", v.AsInt64())
+	}
+	if httpSpan.parent.SpanID() != toolSpan.SpanContext().SpanID() {
+		t.Error("HTTP span should be a child of the tool span")
+	}
+
+	if traceparent == "" {
+		t.Fatal("expected traceparent header on the upstream request")
+	}
+	if want := httpSpan.SpanContext().TraceID().String(); len(traceparent) < 35 || traceparent[3:35] != want {
+		t.Errorf("traceparent %q does not carry trace ID %s", traceparent, want)
+	}
+}
+
+func TestTracing_DisabledByDefault(t *testing.T) {
+	var traceparent string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, DefaultConfig().WithAPIConfig(backend.URL, ""))
+	callTool(t, server, "listpets", nil)
+
+	if traceparent != "" {
+		t.Errorf("no trace headers expected without a tracer, got %q", traceparent)
+	}
+}
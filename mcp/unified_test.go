@@ -1,6 +1,9 @@
 package mcp
 
 import (
+    "context"
+    "fmt"
+    "strings"
     "testing"
 
     "github.com/go-openapi/spec"
@@ -118,3 +121,73 @@ func TestAPIExecutor(t *testing.T) {
         t.Errorf("Expected API key to be test-key, got %s", executor.APIKey)
     }
 }
+
+// TestGenerateToolDescription_Deprecation verifies deprecated and sunsetting
+// operations are flagged in their description
+func TestGenerateToolDescription_Deprecation(t *testing.T) {
+    deprecated := spec.NewOperation("").WithSummary("Old search")
+    deprecated.Deprecated = true
+    if got := GenerateToolDescription("GET", "/search", deprecated); got != "[DEPRECATED] Old search" {
+        t.Errorf("GenerateToolDescription() = %q, want %q", got, "[DEPRECATED] Old search")
+    }
+
+    swagger, err := ParseSwaggerSpec([]byte(`{
+        "swagger": "2.0",
+        "info": {"title": "T", "version": "1"},
+        "paths": {"/legacy": {"get": {"summary": "Legacy list", "deprecated": true, "x-sunset": "2025-06-30"}}}
+    }`))
+    if err != nil {
+        t.Fatalf("failed to parse spec: %v", err)
+    }
+    op := swagger.Paths.Paths["/legacy"].Get
+    want := "[DEPRECATED: sunset 2025-06-30] Legacy list"
+    if got := GenerateToolDescription("GET", "/legacy", op); got != want {
+        t.Errorf("GenerateToolDescription() = %q, want %q", got, want)
+    }
+}
+
+// TestRichDescriptions verifies enriched descriptions list the method, path
+// and required parameters, and are served through tools/list
+func TestRichDescriptions(t *testing.T) {
+    data := `{
+        "swagger": "2.0",
+        "info": {"title": "T", "version": "1"},
+        "paths": {"/pets/{petId}": {"put": {
+            "operationId": "updatePet",
+            "summary": "Update a pet",
+            "parameters": [
+                {"name": "petId", "in": "path", "required": true, "type": "string"},
+                {"name": "dryRun", "in": "query", "type": "boolean"},
+                {"name": "body", "in": "body", "schema": {
+                    "type": "object",
+                    "required": ["name"],
+                    "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}
+                }}
+            ],
+            "responses": {"200": {"description": "OK"}}
+        }}}
+    }`
+    server := newTestServer(t, DefaultConfig().WithSwaggerData([]byte(data)).WithRichDescriptions(true))
+
+    tools, err := connectTestClient(t, server).ListTools(context.Background(), nil)
+    if err != nil || len(tools.Tools) != 1 {
+        t.Fatalf("tools/list = %v, %v", tools, err)
+    }
+    want := "Update a pet\n\nPUT /pets/{petId}\nRequired: petId (string, path), body.name (string)"
+    if got := tools.Tools[0].Description; got != want {
+        t.Errorf("description = %q, want %q", got, want)
+    }
+    if got := server.ListToolsDetailed()[0].Description; got != want {
+        t.Errorf("ListToolsDetailed description = %q, want %q", got, want)
+    }
+
+    // Long parameter lists are cut off at the length cap
+    op := spec.NewOperation("bulk").WithSummary("Bulk")
+    for i := 0; i < 200; i++ {
+        op.AddParam(spec.QueryParam(fmt.Sprintf("param%03d", i)).Typed("string", "").AsRequired())
+    }
+    got := GenerateRichToolDescription("GET", "/bulk", op)
+    if len(got) > maxRichDescriptionLength || !strings.Contains(got, "more)") {
+        t.Errorf("description of %d chars not capped: %q", len(got), got)
+    }
+}
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+    "bytes"
     "encoding/json"
     "fmt"
     "io"
@@ -16,24 +17,36 @@ import (
 // YAML. OpenAPI 3.x documents are converted to Swagger 2.0 internally, and
 // all $refs are expanded so downstream schema generation sees full schemas.
 func ParseSwaggerSpec(data []byte) (*spec.Swagger, error) {
-    jsonData := data
+    return ParseSwaggerSpecWithOptions(data, ParseOptions{})
+}
+
+// ParseOptions controls how external $refs in a spec are resolved
+type ParseOptions struct {
+    // Location is the file path or URL the spec was read from; relative
+    // $refs to other files are resolved against it
+    Location string
+
+    // AllowedRefHosts lists the hosts remote $refs may be fetched from,
+    // in addition to the host of a remote Location
+    AllowedRefHosts []string
+}
+
+// ParseSwaggerSpecWithOptions parses a spec like ParseSwaggerSpec, resolving
+// $refs to other files or URLs relative to opts.Location. Remote references
+// are only followed to allowed hosts, and local files are never read for a
+// spec fetched from a URL.
+func ParseSwaggerSpecWithOptions(data []byte, opts ParseOptions) (*spec.Swagger, error) {
+    refs := newRefLoader(opts)
 
     // Normalize YAML input to JSON first
-    if !json.Valid(data) {
-        var yamlData map[string]interface{}
-        if err := yaml.Unmarshal(data, &yamlData); err != nil {
-            return nil, fmt.Errorf("failed to parse spec as JSON or YAML")
-        }
-        converted, err := json.Marshal(yamlData)
-        if err != nil {
-            return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
-        }
-        jsonData = converted
+    jsonData, err := yamlToJSON(data)
+    if err != nil {
+        return nil, err
     }
 
     // Convert OpenAPI 3.x documents to Swagger 2.0
     if isOpenAPI3(jsonData) {
-        converted, err := convertOpenAPI3ToSwagger2(jsonData)
+        converted, err := convertOpenAPI3ToSwagger2(jsonData, refs)
         if err != nil {
             return nil, err
         }
@@ -45,15 +58,72 @@ func ParseSwaggerSpec(data []byte) (*spec.Swagger, error) {
         return nil, fmt.Errorf("failed to parse spec: %w", err)
     }
 
-    // Expand $refs (e.g. body schemas referencing #/definitions) so tool
-    // input schemas include the full field list instead of a bare object.
-    if err := spec.ExpandSpec(&swagger, nil); err != nil {
+    // Expand $refs (e.g. body schemas referencing #/definitions or other
+    // files) so tool input schemas include the full field list instead of
+    // a bare object.
+    if err := spec.ExpandSpec(&swagger, refs.expandOptions()); err != nil {
+        if ref, location := unresolvedLocalRef(jsonData); ref != "" {
+            return nil, fmt.Errorf("failed to expand spec refs: unresolved $ref %q at %s", ref, location)
+        }
         return nil, fmt.Errorf("failed to expand spec refs: %w", err)
     }
+    inheritMediaTypes(&swagger)
 
     return &swagger, nil
 }
 
+// inheritMediaTypes copies the spec-level consumes/produces to operations
+// that don't declare their own, as Swagger 2.0 specifies
+func inheritMediaTypes(swagger *spec.Swagger) {
+    if swagger.Paths == nil || (len(swagger.Consumes) == 0 && len(swagger.Produces) == 0) {
+        return
+    }
+    for _, pathItem := range swagger.Paths.Paths {
+        for _, op := range []*spec.Operation{pathItem.Get, pathItem.Put, pathItem.Post, pathItem.Delete, pathItem.Options, pathItem.Head, pathItem.Patch} {
+            if op == nil {
+                continue
+            }
+            if len(op.Consumes) == 0 {
+                op.Consumes = swagger.Consumes
+            }
+            if len(op.Produces) == 0 {
+                op.Produces = swagger.Produces
+            }
+        }
+    }
+}
+
+// unmarshalJSONNumbers decodes data like json.Unmarshal but keeps numbers
+// as json.Number, so integers beyond float64 precision, such as 64-bit IDs,
+// are re-encoded exactly
+func unmarshalJSONNumbers(data []byte, v interface{}) error {
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    decoder.UseNumber()
+    if err := decoder.Decode(v); err != nil {
+        return err
+    }
+    if _, err := decoder.Token(); err != io.EOF {
+        return fmt.Errorf("unexpected data after JSON value")
+    }
+    return nil
+}
+
+// yamlToJSON returns data as JSON, converting it from YAML if necessary
+func yamlToJSON(data []byte) ([]byte, error) {
+    if json.Valid(data) {
+        return data, nil
+    }
+    var yamlData map[string]interface{}
+    if err := yaml.Unmarshal(data, &yamlData); err != nil {
+        return nil, fmt.Errorf("failed to parse spec as JSON or YAML")
+    }
+    converted, err := json.Marshal(yamlData)
+    if err != nil {
+        return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+    }
+    return converted, nil
+}
+
 // FetchSwaggerFromURL downloads a Swagger/OpenAPI spec from a URL
 func FetchSwaggerFromURL(url string) ([]byte, error) {
     resp, err := http.Get(url)
@@ -104,5 +174,108 @@ func GenerateToolDescription(method, path string, op *spec.Operation) string {
     if description == "" {
         description = fmt.Sprintf("%s %s", method, path)
     }
+    return deprecationPrefix(op) + description
+}
+
+// maxRichDescriptionLength caps descriptions built by
+// GenerateRichToolDescription; parameters that don't fit are elided
+const maxRichDescriptionLength = 1024
+
+// GenerateRichToolDescription extends GenerateToolDescription with the
+// HTTP method and path and the required parameters with their types, e.g.
+//
+//	Get a pet
+//
+//	GET /pets/{petId}
+//	Required: petId (string, path)
+//
+// Required fields of a body schema are listed as body.<field>.
+func GenerateRichToolDescription(method, path string, op *spec.Operation) string {
+    description := GenerateToolDescription(method, path, op)
+    route := fmt.Sprintf("%s %s", method, path)
+    if !strings.HasSuffix(description, route) {
+        description += "\n\n" + route
+    }
+
+    var required []string
+    for _, param := range op.Parameters {
+        if param.In == "body" {
+            if param.Schema == nil {
+                continue
+            }
+            for _, name := range param.Schema.Required {
+                property := param.Schema.Properties[name]
+                required = append(required, fmt.Sprintf("body.%s (%s)", name, schemaTypeName(&property)))
+            }
+            if len(param.Schema.Required) == 0 && param.Required {
+                required = append(required, fmt.Sprintf("body (%s)", schemaTypeName(param.Schema)))
+            }
+            continue
+        }
+        if param.Required {
+            typ := param.Type
+            if typ == "" {
+                typ = "string"
+            }
+            required = append(required, fmt.Sprintf("%s (%s, %s)", param.Name, typ, param.In))
+        }
+    }
+    if len(required) == 0 {
+        return description
+    }
+
+    description += "\nRequired: "
+    for i, param := range required {
+        separator := ""
+        if i > 0 {
+            separator = ", "
+        }
+        // Leave room for the elision note
+        if len(description)+len(separator)+len(param) > maxRichDescriptionLength-len(", ... (999 more)") {
+            return description + separator + fmt.Sprintf("... (%d more)", len(required)-i)
+        }
+        description += separator + param
+    }
     return description
-}
\ No newline at end of file
+}
+
+// schemaTypeName names a schema's type for descriptions, defaulting to
+// object
+func schemaTypeName(schema *spec.Schema) string {
+    if len(schema.Type) > 0 {
+        return strings.Join(schema.Type, "|")
+    }
+    return "object"
+}
+
+// deprecationPrefix flags deprecated or sunsetting operations so the model
+// avoids them, e.g. "[DEPRECATED: sunset 2025-06-30] "
+func deprecationPrefix(op *spec.Operation) string {
+    sunset, _ := op.Extensions.GetString("x-sunset")
+    switch {
+    case sunset != "":
+        return fmt.Sprintf("[DEPRECATED: sunset %s] ", sunset)
+    case op.Deprecated:
+        return "[DEPRECATED] "
+    }
+    return ""
+}
+
+// joinURL appends path to base with exactly one slash between them. A query
+// string on base is kept after the joined path, so "http://x/api/?v=2" and
+// "/users" give "http://x/api/users?v=2".
+func joinURL(base, path string) string {
+    if base == "" {
+        return path
+    }
+
+    query := ""
+    if i := strings.IndexByte(base, '?'); i >= 0 {
+        base, query = base[:i], base[i:]
+    }
+    base = strings.TrimRight(base, "/")
+    if path == "" {
+        return base + query
+    }
+    return base + "/" + strings.TrimLeft(path, "/") + query
+}
@@ -0,0 +1,348 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ArgumentError reports tool arguments that do not satisfy the tool's input
+// schema. Each problem names the offending field by its path, e.g.
+// "body.address.zip is required", so the caller can correct the call.
+type ArgumentError struct {
+	Problems []string
+}
+
+func (e *ArgumentError) Error() string {
+	return "invalid arguments: " + strings.Join(e.Problems, "; ")
+}
+
+// argumentChecks selects the optional checks of argument validation
+type argumentChecks struct {
+	formats bool // String values must match their schema's format (see validFormat)
+	strict  bool // Arguments and object fields the schema does not declare are rejected
+}
+
+// validateArguments checks args against a generated input schema, returning
+// an *ArgumentError listing every problem found
+func validateArguments(schema map[string]interface{}, args map[string]interface{}, checks argumentChecks) error {
+	if schema == nil {
+		return nil
+	}
+	var problems []string
+	validateObject(schema, args, "", checks, &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ArgumentError{Problems: problems}
+}
+
+// validateValue checks value against schema, recording problems under path
+func validateValue(schema map[string]interface{}, value interface{}, path string, checks argumentChecks, problems *[]string) {
+	types := schemaTypes(schema["type"])
+	if len(types) > 0 {
+		matched := ""
+		for _, typ := range types {
+			if valueHasType(value, typ) {
+				matched = typ
+				break
+			}
+		}
+		if matched == "" {
+			*problems = append(*problems, fmt.Sprintf("%s must be %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value)))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 && !inEnum(enum, value) {
+		options := make([]string, len(enum))
+		for i, option := range enum {
+			options[i] = fmt.Sprintf("%v", option)
+		}
+		*problems = append(*problems, fmt.Sprintf("%s must be one of: %s", path, strings.Join(options, ", ")))
+	}
+
+	if format, ok := schema["format"].(string); ok && checks.formats {
+		if str, isString := value.(string); isString && !validFormat(format, str) {
+			*problems = append(*problems, fmt.Sprintf("%s must be a valid %s", path, format))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		validateObject(schema, v, path, checks, problems)
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			return
+		}
+		for i, item := range v {
+			validateValue(items, item, fmt.Sprintf("%s[%d]", path, i), checks, problems)
+		}
+	}
+}
+
+// validateObject checks an object's required and declared properties. In
+// strict mode the tool arguments themselves, and nested objects that
+// declare properties without allowing additional ones, may hold nothing
+// else.
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, checks argumentChecks, problems *[]string) {
+	properties, _ := schema["properties"].(map[string]interface{})
+	if _, declared := schema["properties"]; checks.strict && (path == "" || declared) && !allowsAdditionalProperties(schema) {
+		var undeclared []string
+		for name := range object {
+			if _, ok := properties[name]; !ok {
+				undeclared = append(undeclared, name)
+			}
+		}
+		sort.Strings(undeclared)
+		for _, name := range undeclared {
+			*problems = append(*problems, joinArgumentPath(path, name)+" is not declared in the spec")
+		}
+	}
+	for _, name := range schemaRequired(schema["required"]) {
+		if _, ok := object[name]; ok {
+			continue
+		}
+		// The SDK fills in defaults, so a defaulted field is never missing
+		if property, _ := properties[name].(map[string]interface{}); property != nil {
+			if _, ok := property["default"]; ok {
+				continue
+			}
+		}
+		*problems = append(*problems, joinArgumentPath(path, name)+" is required")
+	}
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := object[name]
+		property, _ := properties[name].(map[string]interface{})
+		if !ok || property == nil {
+			continue
+		}
+		validateValue(property, value, joinArgumentPath(path, name), checks, problems)
+	}
+}
+
+// allowsAdditionalProperties reports whether an object schema accepts
+// properties beyond those it declares through additionalProperties
+func allowsAdditionalProperties(schema map[string]interface{}) bool {
+	switch additional := schema["additionalProperties"].(type) {
+	case bool:
+		return additional
+	case map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+// joinArgumentPath appends a property name to a dotted argument path
+func joinArgumentPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// schemaTypes returns the type names a schema's type keyword allows
+func schemaTypes(typ interface{}) []string {
+	switch t := typ.(type) {
+	case string:
+		return []string{t}
+	case []string:
+		return t
+	case []interface{}:
+		var types []string
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// schemaRequired returns the names listed by a schema's required keyword
+func schemaRequired(required interface{}) []string {
+	switch r := required.(type) {
+	case []string:
+		return r
+	case []interface{}:
+		var names []string
+		for _, name := range r {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+// valueHasType reports whether a decoded JSON (or Go) value is of the JSON
+// Schema type typ
+func valueHasType(value interface{}, typ string) bool {
+	switch typ {
+	case "null":
+		return value == nil
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int32, int64, json.Number:
+			return true
+		}
+	case "integer":
+		switch v := value.(type) {
+		case int, int32, int64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		case float32:
+			return v == float32(int64(v))
+		case json.Number:
+			_, err := v.Int64()
+			return err == nil
+		}
+	default:
+		// Unknown types are not ours to reject
+		return true
+	}
+	return false
+}
+
+// jsonTypeName names the JSON type of a value for error messages
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if valueHasType(value, "integer") {
+		return "integer"
+	}
+	if valueHasType(value, "number") {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// uuidPattern matches the canonical 8-4-4-4-12 hex form of a UUID
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// validFormat reports whether s is valid for a JSON Schema string format.
+// It covers email, date, date-time, time, uuid, uri, ipv4 and ipv6; other
+// formats are not checked.
+func validFormat(format, s string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(s)
+		return err == nil && address.Address == s
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}
+	return true
+}
+
+// inEnum reports whether value equals one of the enum options, comparing
+// numbers by value
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, option := range enum {
+		if fmt.Sprintf("%v", option) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentChecks returns the optional argument checks the config enables
+func (s *SwaggerMCPServer) argumentChecks() argumentChecks {
+	if s.config == nil {
+		return argumentChecks{}
+	}
+	return argumentChecks{formats: s.config.FormatValidation, strict: s.config.StrictParams}
+}
+
+// toolInputSchema returns the input schema registered for a tool, or nil
+func (s *SwaggerMCPServer) toolInputSchema(name string) map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	registered, ok := s.tools[name]
+	if !ok || registered.tool == nil {
+		return nil
+	}
+	schema, _ := registered.tool.InputSchema.(map[string]interface{})
+	return schema
+}
+
+// validateToolCalls is receiving middleware that answers tools/call
+// requests whose arguments do not satisfy the tool's input schema with
+// path-qualified problems, ahead of the SDK's own validation
+func (s *SwaggerMCPServer) validateToolCalls(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		call, ok := req.(*mcp.CallToolRequest)
+		if !ok || call.Params == nil {
+			return next(ctx, method, req)
+		}
+		var args map[string]interface{}
+		if len(call.Params.Arguments) > 0 {
+			if err := json.Unmarshal(call.Params.Arguments, &args); err != nil {
+				return next(ctx, method, req)
+			}
+		}
+		if err := validateArguments(s.toolInputSchema(call.Params.Name), args, s.argumentChecks()); err != nil {
+			var result mcp.CallToolResult
+			result.SetError(err)
+			return &result, nil
+		}
+		return next(ctx, method, req)
+	}
+}
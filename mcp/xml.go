@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+// isXMLMediaType reports whether a media type carries XML, e.g.
+// application/xml, text/xml or application/atom+xml
+func isXMLMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// requestMediaType picks the Content-Type of a request body. It negotiates
+// like negotiateMediaType, except that an operation consuming XML but not
+// JSON gets its XML type, so the body is sent as XML.
+func requestMediaType(consumes []string, configured string) string {
+	for _, mediaType := range consumes {
+		if isJSONMediaType(mediaType) || (configured != "" && strings.EqualFold(mediaType, configured)) {
+			return negotiateMediaType(consumes, configured)
+		}
+	}
+	for _, mediaType := range consumes {
+		if isXMLMediaType(mediaType) {
+			return mediaType
+		}
+	}
+	return negotiateMediaType(consumes, configured)
+}
+
+// marshalXMLBody encodes a request body as an XML document. The root
+// element is named by the schema's xml.name, falling back to rootName;
+// properties marked xml.attribute become attributes, and arrays repeat
+// their items' element unless xml.wrapped asks for an enclosing element.
+func marshalXMLBody(schema *spec.Schema, rootName string, value interface{}) ([]byte, error) {
+	if schema != nil && schema.XML != nil && schema.XML.Name != "" {
+		rootName = schema.XML.Name
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	if err := encodeXMLElement(encoder, rootName, schema, value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeXMLElement writes value as the element name, described by schema
+// (which may be nil)
+func encodeXMLElement(encoder *xml.Encoder, name string, schema *spec.Schema, value interface{}) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var children []string
+		for _, key := range keys {
+			property := propertySchema(schema, key)
+			if property != nil && property.XML != nil && property.XML.Attribute {
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlName(key, property)}, Value: xmlText(v[key])})
+				continue
+			}
+			children = append(children, key)
+		}
+
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, key := range children {
+			property := propertySchema(schema, key)
+			if err := encodeXMLElement(encoder, xmlName(key, property), property, v[key]); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+
+	case []interface{}:
+		var items *spec.Schema
+		if schema != nil && schema.Items != nil {
+			items = schema.Items.Schema
+		}
+		itemName := xmlName(name, items)
+		wrapped := schema != nil && schema.XML != nil && schema.XML.Wrapped
+		if wrapped {
+			if err := encoder.EncodeToken(start); err != nil {
+				return err
+			}
+		}
+		for _, item := range v {
+			if err := encodeXMLElement(encoder, itemName, items, item); err != nil {
+				return err
+			}
+		}
+		if wrapped {
+			return encoder.EncodeToken(start.End())
+		}
+		return nil
+
+	case nil:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+
+	default:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if err := encoder.EncodeToken(xml.CharData(xmlText(v))); err != nil {
+			return err
+		}
+		return encoder.EncodeToken(start.End())
+	}
+}
+
+// propertySchema returns the schema of a property, or nil if schema
+// doesn't declare it
+func propertySchema(schema *spec.Schema, name string) *spec.Schema {
+	if schema == nil {
+		return nil
+	}
+	if property, ok := schema.Properties[name]; ok {
+		return &property
+	}
+	return nil
+}
+
+// xmlName returns the schema's xml.name, falling back to name
+func xmlName(name string, schema *spec.Schema) string {
+	if schema != nil && schema.XML != nil && schema.XML.Name != "" {
+		return schema.XML.Name
+	}
+	return name
+}
+
+// xmlText renders a scalar value as XML text, writing JSON numbers without
+// exponents
+func xmlText(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+	return fmt.Sprintf("%v", value)
+}